@@ -13,15 +13,22 @@ type client struct {
 	logRequests  bool
 	logResponses bool
 	serverName   string
+	contentType  types.ContentType
+	retryPolicy  *types.RetryPolicy
+	cancelTasks  bool
+	tasks        taskTracker
 }
 
-// New returns a new API client.
-func New(host string, transport *http.Transport) types.APIClient {
+// New returns a new API client. The transport is usually an *http.Transport,
+// but it may be any RoundTripper, such as one that fails over between hosts.
+func New(host string, transport http.RoundTripper) types.APIClient {
 	return &client{
 		Client: http.Client{
 			Transport: transport,
 		},
-		host: host,
+		host:        host,
+		contentType: types.ContentTypeJSON,
+		tasks:       newTaskTracker(),
 	}
 }
 
@@ -36,3 +43,15 @@ func (c *client) LogRequests(enabled bool) {
 func (c *client) LogResponses(enabled bool) {
 	c.logResponses = enabled
 }
+
+func (c *client) UseContentType(contentType types.ContentType) {
+	c.contentType = contentType
+}
+
+func (c *client) UseRetryPolicy(policy *types.RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+func (c *client) CancelTasks(enabled bool) {
+	c.cancelTasks = enabled
+}
@@ -3,10 +3,15 @@ package client
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/types"
 )
 
@@ -75,6 +80,8 @@ func (c *client) ServiceInspect(
 	url := fmt.Sprintf("/services/%s", name)
 	if ctx.Value(ctxInstanceForSvc) != nil {
 		url = fmt.Sprintf("/services/%s?instance", name)
+	} else if context.Zones(ctx) {
+		url = fmt.Sprintf("/services/%s?zones", name)
 	}
 
 	if _, err := c.httpGet(ctx, url, &reply); err != nil {
@@ -83,16 +90,47 @@ func (c *client) ServiceInspect(
 	return reply, nil
 }
 
+func (c *client) StorageClasses(
+	ctx types.Context, service string) (types.StorageClassMap, error) {
+
+	reply := types.StorageClassMap{}
+	url := fmt.Sprintf("/services/%s/classes", service)
+	if _, err := c.httpGet(ctx, url, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *client) Quotas(ctx types.Context) (*types.QuotaReport, error) {
+
+	reply := &types.QuotaReport{}
+	if _, err := c.httpGet(ctx, "/quotas", &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *client) SubjectQuota(
+	ctx types.Context, subject string) (*types.SubjectQuota, error) {
+
+	reply := &types.SubjectQuota{}
+	url := fmt.Sprintf("/quotas/%s", subject)
+	if _, err := c.httpGet(ctx, url, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
 func (c *client) Volumes(
 	ctx types.Context,
 	attachments types.VolumeAttachmentsTypes) (types.ServiceVolumeMap, error) {
 
-	reply := types.ServiceVolumeMap{}
-	url := fmt.Sprintf("/volumes?attachments=%v", attachments)
+	reply := types.PartialServiceVolumeMap{}
+	url := fmt.Sprintf("/volumes?attachments=%v&partial=true", attachments)
 	if _, err := c.httpGet(ctx, url, &reply); err != nil {
 		return nil, err
 	}
-	return reply, nil
+	return reply.Volumes, partialResultErr(reply.Errors)
 }
 
 func (c *client) VolumesByService(
@@ -108,6 +146,19 @@ func (c *client) VolumesByService(
 	return reply, nil
 }
 
+func (c *client) VolumesWatch(
+	ctx types.Context,
+	service string,
+	since int64) (*types.VolumeWatchResponse, error) {
+
+	reply := types.VolumeWatchResponse{}
+	url := fmt.Sprintf("/volumes/%s?watch=true&since=%d", service, since)
+	if _, err := c.httpGet(ctx, url, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
 func (c *client) VolumeInspect(
 	ctx types.Context,
 	service, volumeID string,
@@ -213,12 +264,12 @@ func (c *client) VolumeDetachAll(
 	ctx types.Context,
 	request *types.VolumeDetachRequest) (types.ServiceVolumeMap, error) {
 
-	reply := types.ServiceVolumeMap{}
+	reply := types.PartialServiceVolumeMap{}
 	if _, err := c.httpPost(ctx,
 		fmt.Sprintf("/volumes?detach"), request, &reply); err != nil {
 		return nil, err
 	}
-	return reply, nil
+	return reply.Volumes, partialResultErr(reply.Errors)
 }
 
 func (c *client) VolumeDetachAllForService(
@@ -309,6 +360,169 @@ func (c *client) SnapshotCopy(
 	return &reply, nil
 }
 
+func (c *client) VolumeCreateAsync(
+	ctx types.Context,
+	service string,
+	request *types.VolumeCreateRequest) (*types.Task, error) {
+
+	return c.httpAsync(ctx, "POST",
+		fmt.Sprintf("/volumes/%s", service), request, newVolumeResult)
+}
+
+func (c *client) VolumeCreateFromSnapshotAsync(
+	ctx types.Context,
+	service, snapshotID string,
+	request *types.VolumeCreateRequest) (*types.Task, error) {
+
+	return c.httpAsync(ctx, "POST",
+		fmt.Sprintf("/snapshots/%s/%s?create", service, snapshotID),
+		request, newVolumeResult)
+}
+
+func (c *client) VolumeCopyAsync(
+	ctx types.Context,
+	service, volumeID string,
+	request *types.VolumeCopyRequest) (*types.Task, error) {
+
+	return c.httpAsync(ctx, "POST",
+		fmt.Sprintf("/volumes/%s/%s?copy", service, volumeID),
+		request, newVolumeResult)
+}
+
+func (c *client) VolumeRemoveAsync(
+	ctx types.Context,
+	service, volumeID string,
+	force bool) (*types.Task, error) {
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "/volumes/%s/%s", service, volumeID)
+	if force {
+		fmt.Fprintf(buf, "?force")
+	}
+	return c.httpAsync(ctx, "DELETE", buf.String(), nil, nil)
+}
+
+func (c *client) VolumeAttachAsync(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeAttachRequest) (*types.Task, error) {
+
+	return c.httpAsync(ctx, "POST",
+		fmt.Sprintf("/volumes/%s/%s?attach", service, volumeID),
+		request, newVolumeAttachResult)
+}
+
+func (c *client) VolumeDetachAsync(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeDetachRequest) (*types.Task, error) {
+
+	return c.httpAsync(ctx, "POST",
+		fmt.Sprintf("/volumes/%s/%s?detach", service, volumeID),
+		request, newVolumeResult)
+}
+
+func (c *client) VolumeSnapshotAsync(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeSnapshotRequest) (*types.Task, error) {
+
+	return c.httpAsync(ctx, "POST",
+		fmt.Sprintf("/volumes/%s/%s?snapshot", service, volumeID),
+		request, newSnapshotResult)
+}
+
+func (c *client) SnapshotRemoveAsync(
+	ctx types.Context,
+	service, snapshotID string) (*types.Task, error) {
+
+	return c.httpAsync(ctx, "DELETE",
+		fmt.Sprintf("/snapshots/%s/%s", service, snapshotID), nil, nil)
+}
+
+func (c *client) SnapshotCopyAsync(
+	ctx types.Context,
+	service, snapshotID string,
+	request *types.SnapshotCopyRequest) (*types.Task, error) {
+
+	return c.httpAsync(ctx, "POST",
+		fmt.Sprintf("/snapshots/%s/%s?copy", service, snapshotID),
+		request, newSnapshotResult)
+}
+
+func (c *client) Tasks(ctx types.Context) (map[string]*types.Task, error) {
+
+	reply := map[string]json.RawMessage{}
+	if _, err := c.httpGet(ctx, "/tasks", &reply); err != nil {
+		return nil, err
+	}
+
+	tasks := map[string]*types.Task{}
+	for id, raw := range reply {
+		taskID, _ := strconv.Atoi(id)
+		_, newResult := c.tasks.tracked(ctx, taskID)
+		task, err := decodeTask(raw, newResult)
+		if err != nil {
+			return nil, err
+		}
+		tasks[id] = task
+	}
+	return tasks, nil
+}
+
+func (c *client) TaskInspect(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	ctx, newResult := c.tasks.tracked(ctx, taskID)
+
+	reply := json.RawMessage{}
+	if _, err := c.httpGet(ctx,
+		fmt.Sprintf("/tasks/%d", taskID), &reply); err != nil {
+		return nil, err
+	}
+	return decodeTask(reply, newResult)
+}
+
+func (c *client) TaskCancel(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	ctx, newResult := c.tasks.tracked(ctx, taskID)
+
+	reply := json.RawMessage{}
+	if _, err := c.httpDelete(ctx,
+		fmt.Sprintf("/tasks/%d", taskID), &reply); err != nil {
+		return nil, err
+	}
+	return decodeTask(reply, newResult)
+}
+
+func (c *client) TaskWait(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	for {
+		task, err := c.TaskInspect(ctx, taskID)
+		if err == nil {
+			if done, err := taskDone(task); done {
+				return task, err
+			}
+		} else if ctx.Err() == nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			if c.cancelTasks {
+				cancelTask(ctx, c, taskID)
+			}
+			return task, ctx.Err()
+		case <-time.After(taskWaitInterval):
+		}
+	}
+}
+
 func (c *client) Executors(
 	ctx types.Context) (map[string]*types.ExecutorInfo, error) {
 
@@ -328,21 +542,45 @@ func (c *client) ExecutorHead(
 		return nil, err
 	}
 
-	size, err := strconv.ParseInt(res.Header.Get("Content-Length"), 10, 64)
+	return executorInfoFromHeaders(name, res.Header)
+}
+
+// executorInfoFromHeaders returns information about an executor from the
+// headers of a HEAD request for the executor. The SHA-256 checksum and the
+// signature of the executor are only present if the server provides them.
+func executorInfoFromHeaders(
+	name string, header http.Header) (*types.ExecutorInfo, error) {
+
+	size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
 	if err != nil {
 		return nil, err
 	}
 
-	buf, err := base64.StdEncoding.DecodeString(res.Header.Get("Content-MD5"))
+	buf, err := base64.StdEncoding.DecodeString(header.Get("Content-MD5"))
 	if err != nil {
 		return nil, err
 	}
 
-	return &types.ExecutorInfo{
+	ei := &types.ExecutorInfo{
 		Name:        name,
 		Size:        size,
 		MD5Checksum: fmt.Sprintf("%x", buf),
-	}, nil
+		Signature:   header.Get(types.ExecutorSignatureHeader),
+	}
+
+	for _, v := range strings.Split(header.Get(types.DigestHeader), ",") {
+		v = strings.TrimSpace(v)
+		if !strings.HasPrefix(strings.ToUpper(v), "SHA-256=") {
+			continue
+		}
+		buf, err := base64.StdEncoding.DecodeString(v[len("SHA-256="):])
+		if err != nil {
+			return nil, err
+		}
+		ei.SHA256Checksum = fmt.Sprintf("%x", buf)
+	}
+
+	return ei, nil
 }
 
 func (c *client) ExecutorGet(
@@ -352,5 +590,14 @@ func (c *client) ExecutorGet(
 	if err != nil {
 		return nil, err
 	}
-	return res.Body, nil
+	return &ctxReadCloser{ctx: ctx, ReadCloser: res.Body}, nil
+}
+
+// partialResultErr returns an ErrPartialResult error if any services failed
+// to process a request for multiple services.
+func partialResultErr(errs map[string]*types.ServiceError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &types.ErrPartialResult{Errors: errs}
 }
@@ -0,0 +1,836 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/akutz/goof"
+	gocontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/rpc"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// grpcClient is the libStorage API client for gRPC endpoints.
+type grpcClient struct {
+	conn         *grpc.ClientConn
+	logRequests  bool
+	logResponses bool
+	serverName   string
+	cancelTasks  bool
+	tasks        taskTracker
+}
+
+// NewGRPC returns a new API client that communicates with a libStorage gRPC
+// endpoint by way of the provided connection.
+func NewGRPC(conn *grpc.ClientConn) types.APIClient {
+	return &grpcClient{conn: conn, tasks: newTaskTracker()}
+}
+
+func (c *grpcClient) ServerName() string {
+	return c.serverName
+}
+
+func (c *grpcClient) LogRequests(enabled bool) {
+	c.logRequests = enabled
+}
+
+func (c *grpcClient) LogResponses(enabled bool) {
+	c.logResponses = enabled
+}
+
+// UseContentType is a no-op as the payloads of gRPC calls are always JSON.
+func (c *grpcClient) UseContentType(contentType types.ContentType) {
+}
+
+// UseRetryPolicy is a no-op as gRPC calls are not retried. The connection
+// is reestablished by the gRPC runtime when it fails.
+func (c *grpcClient) UseRetryPolicy(policy *types.RetryPolicy) {
+}
+
+func (c *grpcClient) CancelTasks(enabled bool) {
+	c.cancelTasks = enabled
+}
+
+// invoke calls a unary method of the gRPC service. The payload, if not nil,
+// is encoded as the request's JSON document and the response's JSON
+// document, if any, is decoded into the reply.
+func (c *grpcClient) invoke(
+	ctx types.Context,
+	m *rpc.Method,
+	in *rpc.Request,
+	payload, reply interface{}) (*rpc.Response, error) {
+
+	if payload != nil {
+		buf, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		in.JSON = buf
+	}
+
+	// ask the server to cancel the call's task if the call is cancelled.
+	// only methods that modify resources are executed as tasks
+	if c.cancelTasks &&
+		m.HTTPMethod != http.MethodGet && m.HTTPMethod != http.MethodHead {
+		in.Cancel = true
+	}
+
+	ctx, md := grpcMetadata(ctx)
+	c.logGRPCRequest(m, md, in)
+
+	var (
+		header  metadata.MD
+		trailer metadata.MD
+		out     = &rpc.Response{}
+	)
+
+	err := grpc.Invoke(
+		metadata.NewContext(ctx, md), m.FullName(), in, out, c.conn,
+		grpc.Header(&header), grpc.Trailer(&trailer))
+	c.setServerName(header)
+	if err != nil {
+		return nil, grpcError(err, trailer)
+	}
+
+	c.logGRPCResponse(m, header, out.JSON)
+
+	if reply != nil && len(out.JSON) > 0 {
+		if err := json.Unmarshal(out.JSON, reply); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// stream calls a streaming method of the gRPC service. The returned function
+// cancels the call and must be invoked once the stream is no longer used.
+func (c *grpcClient) stream(
+	ctx types.Context,
+	m *rpc.Method,
+	in *rpc.Request) (grpc.ClientStream, func(), error) {
+
+	ctx, md := grpcMetadata(ctx)
+	c.logGRPCRequest(m, md, in)
+
+	callCtx, cancel := gocontext.WithCancel(metadata.NewContext(ctx, md))
+	stream, err := c.newStream(callCtx, m, in)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return stream, cancel, nil
+}
+
+func (c *grpcClient) newStream(
+	ctx gocontext.Context,
+	m *rpc.Method,
+	in *rpc.Request) (grpc.ClientStream, error) {
+
+	stream, err := grpc.NewClientStream(
+		ctx,
+		&grpc.StreamDesc{StreamName: m.Name, ServerStreams: true},
+		c.conn, m.FullName())
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(in); err != nil {
+		return nil, grpcError(err, stream.Trailer())
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	header, err := stream.Header()
+	if err != nil {
+		return nil, grpcError(err, stream.Trailer())
+	}
+	c.setServerName(header)
+
+	return stream, nil
+}
+
+func (c *grpcClient) setServerName(md metadata.MD) {
+	if v := md[rpc.ServerNameMetadataKey]; len(v) > 0 {
+		c.serverName = v[0]
+	}
+}
+
+// grpcMetadata returns the request metadata for a gRPC call, which includes
+// the same values as the headers of an HTTP request, along with the context
+// that includes the call's transaction.
+func grpcMetadata(ctx types.Context) (types.Context, metadata.MD) {
+	ctx, hdrs := requestHeaders(ctx)
+	md := metadata.MD{}
+	for k, v := range hdrs {
+		k = strings.ToLower(k)
+		md[k] = append(md[k], v...)
+	}
+	return ctx, md
+}
+
+// grpcError returns the API error in the trailer metadata of a failed call,
+// or the call's error if the trailer does not include an API error.
+func grpcError(err error, trailer metadata.MD) error {
+	if v := trailer[rpc.ErrorMetadataKey]; len(v) > 0 {
+		httpErr, derr := goof.DecodeHTTPError(strings.NewReader(v[0]))
+		if derr == nil {
+			return httpErr
+		}
+	}
+	return err
+}
+
+func (c *grpcClient) Root(ctx types.Context) ([]string, error) {
+
+	reply := []string{}
+	if _, err := c.invoke(
+		ctx, rpc.Root, &rpc.Request{}, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) Instances(
+	ctx types.Context) (map[string]*types.Instance, error) {
+
+	reply := map[string]*types.ServiceInfo{}
+	if _, err := c.invoke(ctx, rpc.Services, &rpc.Request{
+		Instance: true,
+	}, nil, &reply); err != nil {
+		return nil, err
+	}
+	instances := map[string]*types.Instance{}
+	for service, si := range reply {
+		instances[service] = si.Instance
+	}
+	return instances, nil
+}
+
+func (c *grpcClient) InstanceInspect(
+	ctx types.Context, service string) (*types.Instance, error) {
+
+	reply := &types.ServiceInfo{}
+	if _, err := c.invoke(ctx, rpc.ServiceInspect, &rpc.Request{
+		Service:  service,
+		Instance: true,
+	}, nil, reply); err != nil {
+		return nil, err
+	}
+	return reply.Instance, nil
+}
+
+func (c *grpcClient) Services(
+	ctx types.Context) (map[string]*types.ServiceInfo, error) {
+
+	reply := map[string]*types.ServiceInfo{}
+	if _, err := c.invoke(
+		ctx, rpc.Services, &rpc.Request{}, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) ServiceInspect(
+	ctx types.Context, name string) (*types.ServiceInfo, error) {
+
+	reply := &types.ServiceInfo{}
+	if _, err := c.invoke(ctx, rpc.ServiceInspect, &rpc.Request{
+		Service: name,
+		Zones:   context.Zones(ctx),
+	}, nil, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) StorageClasses(
+	ctx types.Context, service string) (types.StorageClassMap, error) {
+
+	reply := types.StorageClassMap{}
+	if _, err := c.invoke(ctx, rpc.StorageClasses, &rpc.Request{
+		Service: service,
+	}, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) Quotas(ctx types.Context) (*types.QuotaReport, error) {
+
+	reply := &types.QuotaReport{}
+	if _, err := c.invoke(
+		ctx, rpc.Quotas, &rpc.Request{}, nil, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) SubjectQuota(
+	ctx types.Context, subject string) (*types.SubjectQuota, error) {
+
+	reply := &types.SubjectQuota{}
+	if _, err := c.invoke(ctx, rpc.SubjectQuota, &rpc.Request{
+		ID: subject,
+	}, nil, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) Volumes(
+	ctx types.Context,
+	attachments types.VolumeAttachmentsTypes) (types.ServiceVolumeMap, error) {
+
+	reply := types.PartialServiceVolumeMap{}
+	if _, err := c.invoke(ctx, rpc.Volumes, &rpc.Request{
+		Attachments: int32(attachments),
+		Partial:     true,
+	}, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Volumes, partialResultErr(reply.Errors)
+}
+
+func (c *grpcClient) VolumesByService(
+	ctx types.Context,
+	service string,
+	attachments types.VolumeAttachmentsTypes) (types.VolumeMap, error) {
+
+	reply := types.VolumeMap{}
+	if _, err := c.invoke(ctx, rpc.VolumesByService, &rpc.Request{
+		Service:     service,
+		Attachments: int32(attachments),
+	}, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) VolumesWatch(
+	ctx types.Context,
+	service string,
+	since int64) (*types.VolumeWatchResponse, error) {
+
+	reply := &types.VolumeWatchResponse{}
+	if _, err := c.invoke(ctx, rpc.VolumesWatch, &rpc.Request{
+		Service: service,
+		Since:   since,
+	}, nil, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) VolumeInspect(
+	ctx types.Context,
+	service, volumeID string,
+	attachments types.VolumeAttachmentsTypes) (*types.Volume, error) {
+
+	reply := &types.Volume{}
+	if _, err := c.invoke(ctx, rpc.VolumeInspect, &rpc.Request{
+		Service:     service,
+		ID:          volumeID,
+		Attachments: int32(attachments),
+	}, nil, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) VolumeCreate(
+	ctx types.Context,
+	service string,
+	request *types.VolumeCreateRequest) (*types.Volume, error) {
+
+	reply := &types.Volume{}
+	if _, err := c.invoke(ctx, rpc.VolumeCreate, &rpc.Request{
+		Service: service,
+	}, request, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) VolumeCreateFromSnapshot(
+	ctx types.Context,
+	service, snapshotID string,
+	request *types.VolumeCreateRequest) (*types.Volume, error) {
+
+	reply := &types.Volume{}
+	if _, err := c.invoke(ctx, rpc.VolumeCreateFromSnapshot, &rpc.Request{
+		Service: service,
+		ID:      snapshotID,
+	}, request, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) VolumeCopy(
+	ctx types.Context,
+	service, volumeID string,
+	request *types.VolumeCopyRequest) (*types.Volume, error) {
+
+	reply := &types.Volume{}
+	if _, err := c.invoke(ctx, rpc.VolumeCopy, &rpc.Request{
+		Service: service,
+		ID:      volumeID,
+	}, request, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) VolumeRemove(
+	ctx types.Context,
+	service, volumeID string,
+	force bool) error {
+
+	_, err := c.invoke(ctx, rpc.VolumeRemove, &rpc.Request{
+		Service: service,
+		ID:      volumeID,
+		Force:   force,
+	}, nil, nil)
+	return err
+}
+
+func (c *grpcClient) VolumeAttach(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeAttachRequest) (*types.Volume, string, error) {
+
+	reply := types.VolumeAttachResponse{}
+	if _, err := c.invoke(ctx, rpc.VolumeAttach, &rpc.Request{
+		Service: service,
+		ID:      volumeID,
+	}, request, &reply); err != nil {
+		return nil, "", err
+	}
+	return reply.Volume, reply.AttachToken, nil
+}
+
+func (c *grpcClient) VolumeDetach(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeDetachRequest) (*types.Volume, error) {
+
+	reply := &types.Volume{}
+	if _, err := c.invoke(ctx, rpc.VolumeDetach, &rpc.Request{
+		Service: service,
+		ID:      volumeID,
+	}, request, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) VolumeDetachAll(
+	ctx types.Context,
+	request *types.VolumeDetachRequest) (types.ServiceVolumeMap, error) {
+
+	reply := types.PartialServiceVolumeMap{}
+	if _, err := c.invoke(ctx, rpc.VolumeDetachAll,
+		&rpc.Request{}, request, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Volumes, partialResultErr(reply.Errors)
+}
+
+func (c *grpcClient) VolumeDetachAllForService(
+	ctx types.Context,
+	service string,
+	request *types.VolumeDetachRequest) (types.VolumeMap, error) {
+
+	reply := types.VolumeMap{}
+	if _, err := c.invoke(ctx, rpc.VolumeDetachAllForService, &rpc.Request{
+		Service: service,
+	}, request, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) VolumeSnapshot(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeSnapshotRequest) (*types.Snapshot, error) {
+
+	reply := &types.Snapshot{}
+	if _, err := c.invoke(ctx, rpc.VolumeSnapshot, &rpc.Request{
+		Service: service,
+		ID:      volumeID,
+	}, request, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) Snapshots(
+	ctx types.Context) (types.ServiceSnapshotMap, error) {
+
+	reply := types.ServiceSnapshotMap{}
+	if _, err := c.invoke(
+		ctx, rpc.Snapshots, &rpc.Request{}, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) SnapshotsByService(
+	ctx types.Context, service string) (types.SnapshotMap, error) {
+
+	reply := types.SnapshotMap{}
+	if _, err := c.invoke(ctx, rpc.SnapshotsByService, &rpc.Request{
+		Service: service,
+	}, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) SnapshotInspect(
+	ctx types.Context,
+	service, snapshotID string) (*types.Snapshot, error) {
+
+	reply := &types.Snapshot{}
+	if _, err := c.invoke(ctx, rpc.SnapshotInspect, &rpc.Request{
+		Service: service,
+		ID:      snapshotID,
+	}, nil, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) SnapshotRemove(
+	ctx types.Context,
+	service, snapshotID string) error {
+
+	_, err := c.invoke(ctx, rpc.SnapshotRemove, &rpc.Request{
+		Service: service,
+		ID:      snapshotID,
+	}, nil, nil)
+	return err
+}
+
+func (c *grpcClient) SnapshotCopy(
+	ctx types.Context,
+	service, snapshotID string,
+	request *types.SnapshotCopyRequest) (*types.Snapshot, error) {
+
+	reply := &types.Snapshot{}
+	if _, err := c.invoke(ctx, rpc.SnapshotCopy, &rpc.Request{
+		Service: service,
+		ID:      snapshotID,
+	}, request, reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// invokeAsync calls a method that enqueues a task rather than waiting for
+// the task to complete, and returns the task.
+func (c *grpcClient) invokeAsync(
+	ctx types.Context,
+	m *rpc.Method,
+	in *rpc.Request,
+	payload interface{},
+	newResult func() interface{}) (*types.Task, error) {
+
+	in.Async = true
+
+	// the task is tracked with the call's transaction
+	ctx = context.RequireTX(ctx)
+
+	reply := json.RawMessage{}
+	if _, err := c.invoke(ctx, m, in, payload, &reply); err != nil {
+		return nil, err
+	}
+	return c.tasks.track(ctx, reply, newResult)
+}
+
+func (c *grpcClient) VolumeCreateAsync(
+	ctx types.Context,
+	service string,
+	request *types.VolumeCreateRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, rpc.VolumeCreate, &rpc.Request{
+		Service: service,
+	}, request, newVolumeResult)
+}
+
+func (c *grpcClient) VolumeCreateFromSnapshotAsync(
+	ctx types.Context,
+	service, snapshotID string,
+	request *types.VolumeCreateRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, rpc.VolumeCreateFromSnapshot, &rpc.Request{
+		Service: service,
+		ID:      snapshotID,
+	}, request, newVolumeResult)
+}
+
+func (c *grpcClient) VolumeCopyAsync(
+	ctx types.Context,
+	service, volumeID string,
+	request *types.VolumeCopyRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, rpc.VolumeCopy, &rpc.Request{
+		Service: service,
+		ID:      volumeID,
+	}, request, newVolumeResult)
+}
+
+func (c *grpcClient) VolumeRemoveAsync(
+	ctx types.Context,
+	service, volumeID string,
+	force bool) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, rpc.VolumeRemove, &rpc.Request{
+		Service: service,
+		ID:      volumeID,
+		Force:   force,
+	}, nil, nil)
+}
+
+func (c *grpcClient) VolumeAttachAsync(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeAttachRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, rpc.VolumeAttach, &rpc.Request{
+		Service: service,
+		ID:      volumeID,
+	}, request, newVolumeAttachResult)
+}
+
+func (c *grpcClient) VolumeDetachAsync(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeDetachRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, rpc.VolumeDetach, &rpc.Request{
+		Service: service,
+		ID:      volumeID,
+	}, request, newVolumeResult)
+}
+
+func (c *grpcClient) VolumeSnapshotAsync(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeSnapshotRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, rpc.VolumeSnapshot, &rpc.Request{
+		Service: service,
+		ID:      volumeID,
+	}, request, newSnapshotResult)
+}
+
+func (c *grpcClient) SnapshotRemoveAsync(
+	ctx types.Context,
+	service, snapshotID string) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, rpc.SnapshotRemove, &rpc.Request{
+		Service: service,
+		ID:      snapshotID,
+	}, nil, nil)
+}
+
+func (c *grpcClient) SnapshotCopyAsync(
+	ctx types.Context,
+	service, snapshotID string,
+	request *types.SnapshotCopyRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, rpc.SnapshotCopy, &rpc.Request{
+		Service: service,
+		ID:      snapshotID,
+	}, request, newSnapshotResult)
+}
+
+func (c *grpcClient) Tasks(
+	ctx types.Context) (map[string]*types.Task, error) {
+
+	reply := map[string]json.RawMessage{}
+	if _, err := c.invoke(
+		ctx, rpc.Tasks, &rpc.Request{}, nil, &reply); err != nil {
+		return nil, err
+	}
+
+	tasks := map[string]*types.Task{}
+	for id, raw := range reply {
+		taskID, _ := strconv.Atoi(id)
+		_, newResult := c.tasks.tracked(ctx, taskID)
+		task, err := decodeTask(raw, newResult)
+		if err != nil {
+			return nil, err
+		}
+		tasks[id] = task
+	}
+	return tasks, nil
+}
+
+func (c *grpcClient) TaskInspect(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	ctx, newResult := c.tasks.tracked(ctx, taskID)
+
+	reply := json.RawMessage{}
+	if _, err := c.invoke(ctx, rpc.TaskInspect, &rpc.Request{
+		ID: strconv.Itoa(taskID),
+	}, nil, &reply); err != nil {
+		return nil, err
+	}
+	return decodeTask(reply, newResult)
+}
+
+func (c *grpcClient) TaskCancel(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	ctx, newResult := c.tasks.tracked(ctx, taskID)
+
+	reply := json.RawMessage{}
+	if _, err := c.invoke(ctx, rpc.TaskCancel, &rpc.Request{
+		ID: strconv.Itoa(taskID),
+	}, nil, &reply); err != nil {
+		return nil, err
+	}
+	return decodeTask(reply, newResult)
+}
+
+// TaskWait watches the task with the TaskWatch stream, which sends the task
+// and then sends it again once the task completes.
+func (c *grpcClient) TaskWait(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	ctx, newResult := c.tasks.tracked(ctx, taskID)
+
+	stream, cancel, err := c.stream(
+		ctx, rpc.TaskWatch, &rpc.Request{ID: strconv.Itoa(taskID)})
+	if err != nil {
+		return nil, c.taskWaitError(ctx, taskID, err)
+	}
+	defer cancel()
+
+	var task *types.Task
+	for {
+		out := &rpc.Response{}
+		if err := stream.RecvMsg(out); err != nil {
+			if err == io.EOF {
+				return task, goof.WithField(
+					"taskID", taskID, "task watch ended before completion")
+			}
+			return task, c.taskWaitError(
+				ctx, taskID, grpcError(err, stream.Trailer()))
+		}
+
+		if task, err = decodeTask(out.JSON, newResult); err != nil {
+			return nil, err
+		}
+		if done, err := taskDone(task); done {
+			return task, err
+		}
+	}
+}
+
+// taskWaitError returns the error with which a task watch failed, or the
+// context's error if the watch failed because the context was cancelled, in
+// which case the task is cancelled as well if task cancellation is enabled.
+func (c *grpcClient) taskWaitError(
+	ctx types.Context, taskID int, err error) error {
+
+	if ctx.Err() == nil {
+		return err
+	}
+	if c.cancelTasks {
+		cancelTask(ctx, c, taskID)
+	}
+	return ctx.Err()
+}
+
+func (c *grpcClient) Executors(
+	ctx types.Context) (map[string]*types.ExecutorInfo, error) {
+
+	reply := map[string]*types.ExecutorInfo{}
+	if _, err := c.invoke(
+		ctx, rpc.Executors, &rpc.Request{}, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *grpcClient) ExecutorHead(
+	ctx types.Context,
+	name string) (*types.ExecutorInfo, error) {
+
+	res, err := c.invoke(
+		ctx, rpc.ExecutorHead, &rpc.Request{ID: name}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	for k, v := range res.Headers {
+		header.Set(k, v)
+	}
+	return executorInfoFromHeaders(name, header)
+}
+
+func (c *grpcClient) ExecutorGet(
+	ctx types.Context, name string) (io.ReadCloser, error) {
+
+	stream, cancel, err := c.stream(
+		ctx, rpc.ExecutorGet, &rpc.Request{ID: name})
+	if err != nil {
+		return nil, err
+	}
+	return &grpcChunkReader{
+		ctx: ctx, stream: stream, cancel: cancel}, nil
+}
+
+// grpcChunkReader reads the chunks of a file streamed by ExecutorGet.
+// Reading fails with the context's error once the context is cancelled.
+type grpcChunkReader struct {
+	ctx    types.Context
+	stream grpc.ClientStream
+	cancel func()
+	buf    bytes.Buffer
+}
+
+func (r *grpcChunkReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	for r.buf.Len() == 0 {
+		chunk := &rpc.Chunk{}
+		if err := r.stream.RecvMsg(chunk); err != nil {
+			if err == io.EOF {
+				return 0, io.EOF
+			}
+			if err := r.ctx.Err(); err != nil {
+				return 0, err
+			}
+			return 0, grpcError(err, r.stream.Trailer())
+		}
+		r.buf.Write(chunk.Data)
+	}
+	return r.buf.Read(p)
+}
+
+func (r *grpcChunkReader) Close() error {
+	r.cancel()
+	return nil
+}
@@ -7,13 +7,17 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"sync"
+	"time"
 
+	log "github.com/Sirupsen/logrus"
 	"github.com/akutz/goof"
 	"golang.org/x/net/context/ctxhttp"
 
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 )
 
 type headerKey int
@@ -23,6 +27,7 @@ const (
 	instanceIDHeaderKey
 	localDevicesHeaderKey
 	authTokenHeaderKey
+	debugHeaderKey
 )
 
 var (
@@ -39,6 +44,8 @@ func (k headerKey) String() string {
 		return types.LocalDevicesHeader
 	case authTokenHeaderKey:
 		return types.AuthorizationHeader
+	case debugHeaderKey:
+		return types.DebugHeader
 	}
 	panic("invalid header key")
 }
@@ -48,6 +55,125 @@ func (c *client) httpDo(
 	method, path string,
 	payload, reply interface{}) (*http.Response, error) {
 
+	reqBody, err := encPayload(payload, c.contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	// a request that modifies resources may only be retried after a response
+	// if the caller declared that the request may be repeated
+	idempotent := context.Idempotent(ctx)
+
+	// ask the server to cancel the request's task if the request is
+	// cancelled. only requests that modify resources are executed as tasks
+	if c.cancelTasks && method != http.MethodGet && method != http.MethodHead {
+		path = withQuery(path, "cancel=true")
+	}
+
+	url := fmt.Sprintf("http://%s%s", c.host, path)
+	ctx, hdrs := requestHeaders(ctx)
+
+	var res *http.Response
+	for attempt := 1; ; attempt++ {
+
+		res, err = c.httpSend(ctx, method, url, reqBody, hdrs)
+
+		delay, retry := retryDelay(
+			c.retryPolicy, attempt, isRetryable(method, idempotent, res, err), res)
+		if !retry {
+			break
+		}
+
+		lf := log.Fields{
+			"method":  method,
+			"path":    path,
+			"attempt": attempt,
+			"delay":   delay,
+		}
+		if res != nil {
+			lf["status"] = res.StatusCode
+			res.Body.Close()
+		}
+		ctx.WithFields(lf).WithError(err).Warn("retrying request")
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// transcode YAML response bodies to JSON so they may be decoded into
+	// the reply and error types
+	if err := yamlResToJSON(res); err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode > 299 {
+		httpErr, err := goof.DecodeHTTPError(res.Body)
+		if err != nil {
+			return res, goof.WithField("status", res.StatusCode, "http error")
+		}
+		return res, httpErr
+	}
+
+	if method != http.MethodHead && reply != nil {
+		if err := decRes(res.Body, reply); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// httpSend sends a single attempt of a request.
+func (c *client) httpSend(
+	ctx types.Context,
+	method, url string,
+	body []byte,
+	hdrs http.Header) (*http.Response, error) {
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", c.contentType.String())
+	if body != nil {
+		req.Header.Set("Content-Type", c.contentType.String())
+	}
+
+	for k, v := range hdrs {
+		req.Header[k] = append(req.Header[k], v...)
+	}
+
+	c.logRequest(req)
+
+	res, err := ctxhttp.Do(ctx, &c.Client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setServerName(res)
+	c.logResponse(res)
+
+	return res, nil
+}
+
+// requestHeaders returns the headers sent with a request to a libStorage
+// server, such as the transaction, instance ID and auth token headers, along
+// with the context that includes the request's transaction.
+func requestHeaders(ctx types.Context) (types.Context, http.Header) {
+
 	registerCustomKeyOnce.Do(func() {
 		context.RegisterCustomKeyWithContext(
 			ctx, transactionHeaderKey, context.CustomHeaderKey)
@@ -57,18 +183,11 @@ func (c *client) httpDo(
 			ctx, localDevicesHeaderKey, context.CustomHeaderKey)
 		context.RegisterCustomKeyWithContext(
 			ctx, authTokenHeaderKey, context.CustomHeaderKey)
+		context.RegisterCustomKeyWithContext(
+			ctx, debugHeaderKey, context.CustomHeaderKey)
 	})
 
-	reqBody, err := encPayload(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	url := fmt.Sprintf("http://%s%s", c.host, path)
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, err
-	}
+	hdrs := http.Header{}
 
 	ctx = context.RequireTX(ctx)
 	tx := context.MustTransaction(ctx)
@@ -119,6 +238,12 @@ func (c *client) httpDo(
 			fmt.Sprintf("Bearer %s", tok))
 	}
 
+	// request server-side debug logging for the scope of the request if
+	// the client is logging at the debug level
+	if lvl, ok := context.GetLogLevel(ctx); ok && lvl >= log.DebugLevel {
+		ctx = ctx.WithValue(debugHeaderKey, "true")
+	}
+
 	for key := range context.CustomHeaderKeys() {
 
 		var headerName string
@@ -139,49 +264,25 @@ func (c *client) httpDo(
 		val := ctx.Value(key)
 		switch tv := val.(type) {
 		case string:
-			req.Header.Add(headerName, tv)
+			hdrs.Add(headerName, tv)
 		case fmt.Stringer:
-			req.Header.Add(headerName, tv.String())
+			hdrs.Add(headerName, tv.String())
 		case []string:
 			for _, sv := range tv {
-				req.Header.Add(headerName, sv)
+				hdrs.Add(headerName, sv)
 			}
 		case []fmt.Stringer:
 			for _, sv := range tv {
-				req.Header.Add(headerName, sv.String())
+				hdrs.Add(headerName, sv.String())
 			}
 		default:
 			if val != nil {
-				req.Header.Add(headerName, fmt.Sprintf("%v", val))
+				hdrs.Add(headerName, fmt.Sprintf("%v", val))
 			}
 		}
 	}
 
-	c.logRequest(req)
-
-	res, err := ctxhttp.Do(ctx, &c.Client, req)
-	if err != nil {
-		return nil, err
-	}
-	defer c.setServerName(res)
-
-	c.logResponse(res)
-
-	if res.StatusCode > 299 {
-		httpErr, err := goof.DecodeHTTPError(res.Body)
-		if err != nil {
-			return res, goof.WithField("status", res.StatusCode, "http error")
-		}
-		return res, httpErr
-	}
-
-	if req.Method != http.MethodHead && reply != nil {
-		if err := decRes(res.Body, reply); err != nil {
-			return nil, err
-		}
-	}
-
-	return res, nil
+	return ctx, hdrs
 }
 
 func (c *client) setServerName(res *http.Response) {
@@ -220,7 +321,57 @@ func (c *client) httpDelete(
 	return c.httpDo(ctx, "DELETE", path, nil, reply)
 }
 
-func encPayload(payload interface{}) (io.Reader, error) {
+// httpAsync sends a request that enqueues a task rather than waiting for
+// the task to complete, and returns the task.
+func (c *client) httpAsync(
+	ctx types.Context,
+	method, path string,
+	payload interface{},
+	newResult func() interface{}) (*types.Task, error) {
+
+	// the task is tracked with the request's transaction
+	ctx = context.RequireTX(ctx)
+
+	reply := json.RawMessage{}
+	if _, err := c.httpDo(ctx, method,
+		withQuery(path, "async=true"), payload, &reply); err != nil {
+		return nil, err
+	}
+	return c.tasks.track(ctx, reply, newResult)
+}
+
+// withQuery appends a query parameter to a path.
+func withQuery(path, param string) string {
+	if strings.Contains(path, "?") {
+		return path + "&" + param
+	}
+	return path + "?" + param
+}
+
+// ctxReadCloser is the body of a response that is read after the request
+// returns, such as a downloaded executor. Reading the body fails with the
+// context's error once the request's context is cancelled.
+type ctxReadCloser struct {
+	ctx types.Context
+	io.ReadCloser
+}
+
+func (r *ctxReadCloser) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		if ctxErr := r.ctx.Err(); ctxErr != nil {
+			return n, ctxErr
+		}
+	}
+	return n, err
+}
+
+func encPayload(
+	payload interface{}, contentType types.ContentType) ([]byte, error) {
+
 	if payload == nil {
 		return nil, nil
 	}
@@ -230,7 +381,13 @@ func encPayload(payload interface{}) (io.Reader, error) {
 		return nil, err
 	}
 
-	return bytes.NewReader(buf), nil
+	if contentType.IsYAML() {
+		if buf, err = utils.JSONToYAML(buf); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
 }
 
 func decRes(body io.Reader, reply interface{}) error {
@@ -243,3 +400,20 @@ func decRes(body io.Reader, reply interface{}) error {
 	}
 	return nil
 }
+
+func yamlResToJSON(res *http.Response) error {
+	if !types.ParseContentType(res.Header.Get("Content-Type")).IsYAML() {
+		return nil
+	}
+	defer res.Body.Close()
+	buf, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if buf, err = utils.YAMLToJSON(buf); err != nil {
+		return err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(buf))
+	res.ContentLength = int64(len(buf))
+	return nil
+}
@@ -4,11 +4,16 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httputil"
+	"sort"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/akutz/gotil"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/codedellemc/libstorage/api/rpc"
 )
 
 func (c *client) logRequest(req *http.Request) {
@@ -64,3 +69,66 @@ func (c *client) logResponse(res *http.Response) {
 		fmt.Fprintln(w, scanner.Text())
 	}
 }
+
+func (c *grpcClient) logGRPCRequest(
+	m *rpc.Method, md metadata.MD, in *rpc.Request) {
+
+	if !c.logRequests {
+		return
+	}
+
+	w := log.StandardLogger().Writer()
+
+	fmt.Fprintln(w, "")
+	fmt.Fprint(w, "    -------------------------- ")
+	fmt.Fprint(w, "GRPC REQUEST (CLIENT)")
+	fmt.Fprintln(w, " -------------------------")
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s %s\n", m.FullName(), m.URL(in))
+	writeGRPCMetadata(buf, md)
+	if len(in.JSON) > 0 {
+		fmt.Fprintf(buf, "\n%s\n", in.JSON)
+	}
+
+	gotil.WriteIndented(w, buf.Bytes())
+	fmt.Fprintln(w)
+}
+
+func (c *grpcClient) logGRPCResponse(
+	m *rpc.Method, md metadata.MD, body []byte) {
+
+	if !c.logResponses {
+		return
+	}
+
+	w := log.StandardLogger().Writer()
+
+	fmt.Fprintln(w)
+	fmt.Fprint(w, "    -------------------------- ")
+	fmt.Fprint(w, "GRPC RESPONSE (CLIENT)")
+	fmt.Fprintln(w, " -------------------------")
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s\n", m.FullName())
+	writeGRPCMetadata(buf, md)
+	if len(body) > 0 {
+		fmt.Fprintf(buf, "\n%s\n", body)
+	}
+
+	gotil.WriteIndented(w, buf.Bytes())
+	fmt.Fprintln(w)
+}
+
+func writeGRPCMetadata(w io.Writer, md metadata.MD) {
+	keys := []string{}
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range md[k] {
+			fmt.Fprintf(w, "%s: %s\n", k, v)
+		}
+	}
+}
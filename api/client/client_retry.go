@@ -0,0 +1,119 @@
+package client
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// isRetryable returns a flag indicating whether or not a request may be
+// retried after the provided response or error.
+//
+// Requests that read resources may always be retried. Requests that modify
+// resources may be retried after a failure to dial the server, since the
+// request was never sent. They may be retried after a response that
+// indicates the server did not process the request only if the caller
+// declared the request idempotent. A proxy may return a 502 or 504 response
+// after the server received the request, so sending it again may run the
+// operation twice. A 408 response means the server's task for the request is
+// still running, so such a request is never sent again.
+func isRetryable(
+	method string, idempotent bool, res *http.Response, err error) bool {
+
+	read := method == http.MethodGet || method == http.MethodHead
+
+	if err != nil {
+		if err == context.Canceled || err == context.DeadlineExceeded {
+			return false
+		}
+		urlErr, ok := err.(*url.Error)
+		if !ok {
+			return read
+		}
+		switch tErr := urlErr.Err.(type) {
+		case *types.ErrKnownHost, *types.ErrKnownHostConflict:
+			return false
+		case *net.OpError:
+			return read || tErr.Op == "dial"
+		}
+		return read
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return read || idempotent
+	case http.StatusRequestTimeout:
+		return read
+	}
+	return read && res.StatusCode >= 500
+}
+
+// retryDelay returns the delay before the next attempt of a request and a
+// flag indicating whether or not the request should be retried. The delay
+// grows exponentially with each attempt, and half of the delay is random so
+// that clients do not retry in lockstep. A Retry-After header takes
+// precedence over the computed delay, but it cannot exceed the policy's
+// maximum backoff.
+func retryDelay(
+	policy *types.RetryPolicy,
+	attempt int,
+	retryable bool,
+	res *http.Response) (time.Duration, bool) {
+
+	if policy == nil || !retryable || attempt > policy.Attempts {
+		return 0, false
+	}
+
+	if res != nil {
+		if d, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			if d > policy.MaxBackoff {
+				d = policy.MaxBackoff
+			}
+			return d, true
+		}
+	}
+
+	d := policy.MinBackoff << uint(attempt-1)
+	if d <= 0 || d > policy.MaxBackoff {
+		d = policy.MaxBackoff
+	}
+	if d <= 0 {
+		return 0, true
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1)), true
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either
+// a number of seconds or an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	d := t.Sub(time.Now())
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
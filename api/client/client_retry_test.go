@@ -0,0 +1,116 @@
+package client
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestIsRetryable(t *testing.T) {
+
+	res := func(status int) *http.Response {
+		return &http.Response{StatusCode: status}
+	}
+
+	assert.True(t, isRetryable("GET", false, res(500), nil))
+	assert.True(t, isRetryable("HEAD", false, res(408), nil))
+	assert.False(t, isRetryable("GET", false, res(404), nil))
+	assert.False(t, isRetryable("POST", false, res(503), nil))
+	assert.True(t, isRetryable("POST", true, res(503), nil))
+	assert.False(t, isRetryable("POST", true, res(500), nil))
+	assert.False(t, isRetryable("POST", true, res(408), nil))
+
+	dialErr := &url.Error{
+		Op:  "Post",
+		URL: "http://libstorage-server/volumes",
+		Err: &net.OpError{Op: "dial", Err: errors.New("refused")},
+	}
+	assert.True(t, isRetryable("POST", true, nil, dialErr))
+	assert.True(t, isRetryable("POST", false, nil, dialErr))
+
+	resetErr := &url.Error{
+		Op:  "Post",
+		URL: "http://libstorage-server/volumes",
+		Err: &net.OpError{Op: "read", Err: errors.New("reset")},
+	}
+	assert.False(t, isRetryable("POST", true, nil, resetErr))
+	assert.True(t, isRetryable("GET", false, nil, resetErr))
+	assert.True(t, isRetryable("GET", false, nil, dialErr))
+
+	knownHostErr := &url.Error{
+		Op:  "Get",
+		URL: "http://libstorage-server/services",
+		Err: &types.ErrKnownHost{HostName: "127.0.0.1"},
+	}
+	assert.False(t, isRetryable("GET", false, nil, knownHostErr))
+}
+
+func TestRetryDelay(t *testing.T) {
+
+	policy := &types.RetryPolicy{
+		Attempts:   3,
+		MinBackoff: time.Duration(100) * time.Millisecond,
+		MaxBackoff: time.Duration(300) * time.Millisecond,
+	}
+
+	_, ok := retryDelay(nil, 1, true, nil)
+	assert.False(t, ok)
+
+	_, ok = retryDelay(policy, 1, false, nil)
+	assert.False(t, ok)
+
+	_, ok = retryDelay(policy, 4, true, nil)
+	assert.False(t, ok)
+
+	for attempt, max := range map[int]time.Duration{
+		1: policy.MinBackoff,
+		2: policy.MinBackoff * 2,
+		3: policy.MaxBackoff,
+	} {
+		d, ok := retryDelay(policy, attempt, true, nil)
+		assert.True(t, ok)
+		assert.True(t, d >= max/2, "attempt=%d delay=%v", attempt, d)
+		assert.True(t, d <= max, "attempt=%d delay=%v", attempt, d)
+	}
+
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set("Retry-After", "0")
+	d, ok := retryDelay(policy, 1, true, res)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), d)
+
+	// the delay requested by the server is capped by the maximum backoff
+	res.Header.Set("Retry-After", "3600")
+	d, ok = retryDelay(policy, 1, true, res)
+	assert.True(t, ok)
+	assert.Equal(t, policy.MaxBackoff, d)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+
+	_, ok := parseRetryAfter("")
+	assert.False(t, ok)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+
+	d, ok := parseRetryAfter("5")
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(5)*time.Second, d)
+
+	d, ok = parseRetryAfter(
+		time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.True(t, d > time.Duration(59)*time.Minute)
+
+	d, ok = parseRetryAfter("Mon, 02 Jan 2006 15:04:05 GMT")
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), d)
+}
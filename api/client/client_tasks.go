@@ -0,0 +1,191 @@
+package client
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+var (
+	// taskWaitInterval is the interval at which TaskWait polls the state of
+	// a task.
+	taskWaitInterval = time.Duration(500) * time.Millisecond
+
+	// TrackedTaskTimeout is how long a client remembers a task created by
+	// one of its async methods after the task was last inspected.
+	TrackedTaskTimeout = time.Duration(1) * time.Hour
+)
+
+// Functions that return the values into which the results of the tasks
+// created by the async methods are decoded.
+var (
+	newVolumeResult = func() interface{} {
+		return &types.Volume{}
+	}
+	newVolumeAttachResult = func() interface{} {
+		return &types.VolumeAttachResponse{}
+	}
+	newSnapshotResult = func() interface{} {
+		return &types.Snapshot{}
+	}
+)
+
+// trackedTask is a task created by one of an API client's async methods.
+type trackedTask struct {
+
+	// tx is the transaction of the request that created the task. The
+	// task is inspected as part of the same transaction so a client of
+	// several servers sends the requests to the server that has the task.
+	tx *types.Transaction
+
+	// newResult returns the value into which the task's result is decoded.
+	newResult func() interface{}
+}
+
+// taskTracker remembers the tasks created by an API client's async methods.
+type taskTracker struct {
+	store types.Store
+}
+
+func newTaskTracker() taskTracker {
+	return taskTracker{store: utils.NewTTLStore(TrackedTaskTimeout, false)}
+}
+
+// track decodes a task created by an async method and remembers the
+// transaction that created it and the type of the task's result.
+func (t *taskTracker) track(
+	ctx types.Context,
+	raw []byte,
+	newResult func() interface{}) (*types.Task, error) {
+
+	task, err := decodeTask(raw, newResult)
+	if err != nil {
+		return nil, err
+	}
+
+	tt := &trackedTask{newResult: newResult}
+	tt.tx, _ = context.Transaction(ctx)
+	t.store.Set(strconv.Itoa(task.ID), tt)
+
+	return task, nil
+}
+
+// tracked returns the context with which a task is inspected and the
+// function that returns the value into which the task's result is decoded,
+// which is nil if the task was not created by the client.
+func (t *taskTracker) tracked(
+	ctx types.Context,
+	taskID int) (types.Context, func() interface{}) {
+
+	tt, ok := t.store.Get(strconv.Itoa(taskID)).(*trackedTask)
+	if !ok {
+		return ctx, nil
+	}
+	if tt.tx != nil {
+		ctx = ctx.WithValue(context.TransactionKey, tt.tx)
+	}
+	return ctx, tt.newResult
+}
+
+// taskJSON is the JSON representation of a task. The result and error are
+// decoded separately since their types are not known.
+type taskJSON struct {
+	types.Task
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+// decodeTask decodes a task. The task's result is decoded into the value
+// returned by newResult, or into generic maps and slices if newResult is
+// nil.
+func decodeTask(
+	raw []byte, newResult func() interface{}) (*types.Task, error) {
+
+	tj := &taskJSON{}
+	if err := json.Unmarshal(raw, tj); err != nil {
+		return nil, err
+	}
+
+	task := &tj.Task
+	task.Error = decodeTaskError(tj.Error)
+
+	if len(tj.Result) == 0 || string(tj.Result) == "null" {
+		return task, nil
+	}
+
+	if newResult != nil {
+		task.Result = newResult()
+		if err := json.Unmarshal(tj.Result, task.Result); err != nil {
+			return nil, err
+		}
+		return task, nil
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(tj.Result, &result); err != nil {
+		return nil, err
+	}
+	task.Result = result
+	return task, nil
+}
+
+// decodeTaskError decodes the error of a task. The server encodes the
+// error with the error's own JSON representation, which is a string or an
+// object with the error's message and fields for most errors.
+func decodeTaskError(raw json.RawMessage) error {
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var msg string
+	if err := json.Unmarshal(raw, &msg); err == nil {
+		return goof.New(msg)
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return goof.New(string(raw))
+	}
+
+	for _, k := range []string{"message", "msg", "error"} {
+		if v, ok := fields[k].(string); ok && v != "" {
+			delete(fields, k)
+			return goof.WithFields(fields, v)
+		}
+	}
+
+	return goof.WithFields(fields, "task failed")
+}
+
+// taskDone returns a flag indicating whether or not a task is complete and,
+// if the task failed, the task's error.
+func taskDone(task *types.Task) (bool, error) {
+	switch task.State {
+	case types.TaskStateSuccess:
+		return true, nil
+	case types.TaskStateError:
+		if task.Error == nil {
+			return true, goof.WithField("taskID", task.ID, "task failed")
+		}
+		return true, task.Error
+	}
+	return false, nil
+}
+
+// cancelTask cancels a task after the context with which the task was waited
+// on is cancelled. The task is cancelled with a context that is not
+// cancelled, and a failure to cancel the task is only logged.
+func cancelTask(ctx types.Context, api types.APIClient, taskID int) {
+	_, err := api.TaskCancel(context.WithoutCancel(ctx), taskID)
+	if err != nil {
+		ctx.WithError(err).WithField(
+			"taskID", taskID).Warn("error cancelling task")
+	}
+}
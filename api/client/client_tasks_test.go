@@ -0,0 +1,89 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestDecodeTask(t *testing.T) {
+
+	task, err := decodeTask([]byte(`{
+		"id": 3,
+		"queueTime": 1490000000,
+		"state": "success",
+		"result": {"id": "vfs-000", "name": "v1", "size": 10}
+	}`), newVolumeResult)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, task.ID)
+	assert.EqualValues(t, types.TaskStateSuccess, task.State)
+	assert.NoError(t, task.Error)
+	if assert.IsType(t, &types.Volume{}, task.Result) {
+		vol := task.Result.(*types.Volume)
+		assert.Equal(t, "vfs-000", vol.ID)
+		assert.Equal(t, "v1", vol.Name)
+		assert.EqualValues(t, 10, vol.Size)
+	}
+
+	task, err = decodeTask([]byte(`{
+		"id": 4,
+		"state": "success",
+		"result": {"volume": {"id": "vfs-001"}, "attachToken": "1234"}
+	}`), newVolumeAttachResult)
+	assert.NoError(t, err)
+	if assert.IsType(t, &types.VolumeAttachResponse{}, task.Result) {
+		res := task.Result.(*types.VolumeAttachResponse)
+		assert.Equal(t, "vfs-001", res.Volume.ID)
+		assert.Equal(t, "1234", res.AttachToken)
+	}
+
+	task, err = decodeTask(
+		[]byte(`{"id": 5, "state": "running"}`), newSnapshotResult)
+	assert.NoError(t, err)
+	assert.Nil(t, task.Result)
+	done, err := taskDone(task)
+	assert.False(t, done)
+	assert.NoError(t, err)
+
+	task, err = decodeTask([]byte(`{
+		"id": 6,
+		"state": "success",
+		"result": {"id": "vfs-002"}
+	}`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": "vfs-002"}, task.Result)
+
+	_, err = decodeTask([]byte(`{"id": 7`), nil)
+	assert.Error(t, err)
+}
+
+func TestDecodeTaskError(t *testing.T) {
+
+	assert.NoError(t, decodeTaskError(nil))
+	assert.NoError(t, decodeTaskError([]byte("null")))
+
+	err := decodeTaskError([]byte(`"volume not found"`))
+	assert.EqualError(t, err, "volume not found")
+
+	err = decodeTaskError([]byte(`{"msg":"volume busy","id":"vfs-000"}`))
+	assert.Contains(t, err.Error(), "volume busy")
+
+	err = decodeTaskError([]byte(`{}`))
+	assert.Contains(t, err.Error(), "task failed")
+
+	task, err := decodeTask([]byte(`{
+		"id": 8,
+		"state": "error",
+		"error": {"message": "volume busy"}
+	}`), newVolumeResult)
+	assert.NoError(t, err)
+	done, err := taskDone(task)
+	assert.True(t, done)
+	assert.Contains(t, err.Error(), "volume busy")
+
+	done, err = taskDone(&types.Task{ID: 9, State: types.TaskStateError})
+	assert.True(t, done)
+	assert.Error(t, err)
+}
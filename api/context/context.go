@@ -19,7 +19,7 @@ type lsc struct {
 	val             interface{}
 	req             *http.Request
 	right           context.Context
-	logger          *log.Logger
+	logger          *loggerRef
 	pathConfig      *types.PathConfig
 	loggerInherited bool
 }
@@ -43,7 +43,7 @@ func newContext(
 
 	// figure out who the parent logger instance is. if there is none,
 	// reference the log.StandardLogger as the parent.
-	var logger *log.Logger
+	var logger *loggerRef
 	if ctx, ok := parent.(*lsc); ok {
 		logger = ctx.logger
 	}
@@ -58,12 +58,12 @@ func newContext(
 			}
 			lvl = ll
 		}
-		logger = &log.Logger{
+		logger = newLoggerRef(&log.Logger{
 			Formatter: log.StandardLogger().Formatter,
 			Hooks:     log.StandardLogger().Hooks,
 			Level:     lvl,
 			Out:       types.Stderr,
-		}
+		})
 	}
 
 	// forward the pathConfig reference
@@ -142,7 +142,24 @@ func WithStorageService(
 		}
 	}
 
-	return newContext(parent, ServiceKey, service, nil, nil)
+	ctx := newContext(parent, ServiceKey, service, nil, nil)
+
+	// apply the service's log level override if present, unless debug
+	// logging was requested for the scope of the request
+	if debug, _ := ctx.Value(DebugKey).(bool); debug {
+		return ctx
+	}
+	if llsvc, ok := service.(hasLogLevel); ok {
+		if lvl, ok := llsvc.LogLevel(); ok {
+			SetLogLevel(ctx, lvl)
+		}
+	}
+
+	return ctx
+}
+
+type hasLogLevel interface {
+	LogLevel() (log.Level, bool)
 }
 
 // WithStorageSession returns a context that is logged into the storage
@@ -170,6 +187,31 @@ func WithStorageSession(parent context.Context) (types.Context, error) {
 	return newContext(pctx, SessionKey, sess, nil, nil), nil
 }
 
+// WithCancel returns a copy of parent that is cancelled when the returned
+// function is invoked or when the parent is cancelled.
+func WithCancel(parent types.Context) (types.Context, context.CancelFunc) {
+	cctx, cancel := context.WithCancel(parent)
+	return inherit(newContext(cctx, nil, nil, nil, nil), parent), cancel
+}
+
+// WithoutCancel returns a copy of parent that is never cancelled and has no
+// deadline, such as the context of a task that outlives the request that
+// created it. The parent's values are still available.
+func WithoutCancel(parent types.Context) types.Context {
+	return inherit(
+		newContext(context.Background(), nil, nil, nil, parent), parent)
+}
+
+// inherit assigns a context the logger and path config of a context from
+// which it is derived by way of a context that is not a libStorage context.
+func inherit(ctx *lsc, parent context.Context) types.Context {
+	if p, ok := parent.(*lsc); ok {
+		ctx.logger = p.logger
+		ctx.pathConfig = p.pathConfig
+	}
+	return ctx
+}
+
 // WithValue returns a copy of parent in which the value associated with
 // key is val.
 func WithValue(ctx context.Context, key, val interface{}) types.Context {
@@ -193,7 +235,7 @@ func Value(ctx context.Context, key interface{}) interface{} {
 func (ctx *lsc) Value(key interface{}) interface{} {
 
 	if key == LoggerKey && ctx.logger != nil {
-		return ctx.logger
+		return ctx.getLogger()
 	}
 
 	if key == PathConfigKey && ctx.pathConfig != nil {
@@ -279,29 +321,36 @@ func (ctx *lsc) Join(right context.Context) types.Context {
 	return Join(ctx, right)
 }
 
-// SetLogLevel sets the context's log level.
+// SetLogLevel sets the context's log level. A context that inherited its
+// logger is given its own logger, so the level of the context from which it
+// is derived is unchanged. Setting the level of a context that has its own
+// logger also sets the level of the contexts derived from it.
 func SetLogLevel(ctx context.Context, lvl log.Level) {
 	if logCtx, ok := ctx.(*lsc); ok {
-		if lvl == logCtx.logger.Level {
-			return
-		}
 		if logCtx.loggerInherited {
-			parentLogger := logCtx.logger
-			logCtx.logger = &log.Logger{
-				Formatter: parentLogger.Formatter,
-				Out:       parentLogger.Out,
-				Hooks:     parentLogger.Hooks,
-				Level:     lvl,
-			}
+			logCtx.logger = newLoggerRef(logCtx.getLogger())
 			logCtx.loggerInherited = false
+		} else if lvl == logCtx.getLogger().Level {
+			return
 		}
+		logCtx.logger.setLevel(lvl)
+	}
+}
+
+// SetSharedLogLevel sets the level of the logger the context shares with the
+// context that owns the logger and with all of the contexts derived from it,
+// ex. the level of a server's logger. The logger is replaced rather than
+// modified, so it is safe to call while other goroutines log.
+func SetSharedLogLevel(ctx context.Context, lvl log.Level) {
+	if logCtx, ok := ctx.(*lsc); ok {
+		logCtx.logger.setLevel(lvl)
 	}
 }
 
 // GetLogLevel gets the context's log level.
 func GetLogLevel(ctx context.Context) (log.Level, bool) {
 	if logCtx, ok := ctx.(*lsc); ok {
-		return logCtx.logger.Level, true
+		return logCtx.getLogger().Level, true
 	}
 	return 0, false
 }
@@ -353,6 +402,28 @@ func MustAuthToken(ctx context.Context) *types.AuthToken {
 	return ctx.Value(AuthTokenKey).(*types.AuthToken)
 }
 
+// Namespace returns the namespace to which a request is scoped. The flag is
+// false if the request is not scoped to a namespace, in which case it may
+// access the objects of all namespaces. This value is valid on the server.
+func Namespace(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(NamespaceKey).(string)
+	return v, ok
+}
+
+// Endpoint returns the configuration of the server endpoint that received a
+// request. This value is valid on the server.
+func Endpoint(ctx context.Context) (*types.Endpoint, bool) {
+	v, ok := ctx.Value(EndpointKey).(*types.Endpoint)
+	return v, ok
+}
+
+// APIClient returns the context's API client. This value is valid only for
+// contexts created on the client of an embedded server.
+func APIClient(ctx context.Context) (types.APIClient, bool) {
+	v, ok := ctx.Value(APIClientKey).(types.APIClient)
+	return v, ok
+}
+
 // InstanceID returns the context's InstanceID. This value is valid on both
 // the client and the server.
 func InstanceID(ctx context.Context) (*types.InstanceID, bool) {
@@ -386,6 +457,53 @@ func MustTransaction(ctx context.Context) *types.Transaction {
 	return ctx.Value(TransactionKey).(*types.Transaction)
 }
 
+// TransactionID returns the ID of the context's Transaction. This value is
+// valid on both the client and the server.
+func TransactionID(ctx context.Context) (string, bool) {
+	if tx, ok := Transaction(ctx); ok && tx.ID != nil {
+		return tx.ID.String(), true
+	}
+	return "", false
+}
+
+// WithIdempotent returns a context that declares the requests which modify
+// resources sent with the context may be repeated without side effects. The
+// client only retries such requests after a response that indicates the
+// server did not process them if the context is idempotent.
+func WithIdempotent(parent context.Context) types.Context {
+	return newContext(parent, IdempotentKey, true, nil, nil)
+}
+
+// Idempotent returns a flag indicating whether or not the caller declared
+// that the requests sent with the context may be repeated. This value is
+// valid on the client.
+func Idempotent(ctx context.Context) bool {
+	v, _ := ctx.Value(IdempotentKey).(bool)
+	return v
+}
+
+// WithZones returns a context that requests the zones in which a service can
+// provision volumes when the service is inspected with the context. Listing
+// the zones may require the server to query the storage platform.
+func WithZones(parent context.Context) types.Context {
+	return newContext(parent, ZonesKey, true, nil, nil)
+}
+
+// Zones returns a flag indicating whether or not the caller requested the
+// zones in which a service can provision volumes. This value is valid on the
+// client.
+func Zones(ctx context.Context) bool {
+	v, _ := ctx.Value(ZonesKey).(bool)
+	return v
+}
+
+// EmbeddedRequest returns the request of an embedded server's API client.
+// This value is valid on the server.
+func EmbeddedRequest(ctx context.Context) (*types.EmbeddedRequest, bool) {
+	v, ok := ctx.Value(EmbeddedRequestKey).(*types.EmbeddedRequest)
+	return v, ok
+}
+
 // RequireTX ensures a context has a transaction, and if it doesn't creates a
 // new one.
 func RequireTX(ctx context.Context) types.Context {
@@ -37,12 +37,43 @@ const (
 	// AdminTokenKey is the key for the server's admin token.
 	AdminTokenKey
 
+	// AdminAuthConfigKey is the key for the server's admin auth config.
+	AdminAuthConfigKey
+
+	// DebugAuthConfigKey is the key for the server's debug auth config.
+	DebugAuthConfigKey
+
+	// DebugKey is the key for a flag indicating debug logging was requested
+	// for the scope of a single request.
+	DebugKey
+
 	// SessionKey is the key for the storage driver's session.
 	SessionKey
 
 	// EncodedAuthTokenKey is the key for an encoded authentication token.
 	EncodedAuthTokenKey
 
+	// EndpointKey is the key for the configuration of the server endpoint
+	// that received a request.
+	EndpointKey
+
+	// APIClientKey is the key for the API client used by the libStorage
+	// storage driver instead of connecting to a server, ex. the in-process
+	// client of an embedded server.
+	APIClientKey
+
+	// IdempotentKey is the key for a flag indicating the caller has declared
+	// that a request which modifies resources may be sent more than once.
+	IdempotentKey
+
+	// EmbeddedRequestKey is the key for the *types.EmbeddedRequest of a
+	// request handled in process by an embedded server.
+	EmbeddedRequestKey
+
+	// ZonesKey is the key for a flag indicating the caller has requested the
+	// zones in which a service can provision volumes.
+	ZonesKey
+
 	// keyLoggable is the minimum value from which the succeeding keys should
 	// be checked when logging.
 	keyLoggable
@@ -89,6 +120,10 @@ const (
 	// TLSKey is a context key.
 	TLSKey
 
+	// NamespaceKey is the key for the namespace to which a request is
+	// scoped.
+	NamespaceKey
+
 	// keyEOF should always be the final key
 	keyEOF
 )
@@ -116,6 +151,7 @@ var (
 		UserKey:           "user",
 		HostKey:           "host",
 		TLSKey:            "tls",
+		NamespaceKey:      "namespace",
 	}
 )
 
@@ -188,7 +224,7 @@ func RegisterCustomKeyWithContext(
 	customKeys[newCustomKey.externalID] = newCustomKey
 
 	if ctx, ok := ctx.(*lsc); ok && ctx.logger != nil {
-		ctx.logger.WithFields(map[string]interface{}{
+		ctx.getLogger().WithFields(map[string]interface{}{
 			"internalID": newCustomKey.internalID,
 			"externalID": newCustomKey.externalID,
 			"keyBitmask": newCustomKey.keyBitmask,
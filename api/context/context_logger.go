@@ -3,6 +3,7 @@ package context
 import (
 	"fmt"
 	"os"
+	"sync/atomic"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -14,6 +15,39 @@ const (
 	keyFieldOffset = 1000
 )
 
+// loggerRef holds the logger shared by a context and the contexts derived
+// from it. A logger is never modified once it is stored, since other
+// goroutines may be logging with it, so changing the level stores a copy of
+// the logger with the new level.
+type loggerRef struct {
+	v atomic.Value
+}
+
+func newLoggerRef(logger *log.Logger) *loggerRef {
+	r := &loggerRef{}
+	r.v.Store(logger)
+	return r
+}
+
+func (r *loggerRef) load() *log.Logger {
+	return r.v.Load().(*log.Logger)
+}
+
+// setLevel stores a copy of the logger with the provided level.
+func (r *loggerRef) setLevel(lvl log.Level) {
+	logger := r.load()
+	r.v.Store(&log.Logger{
+		Formatter: logger.Formatter,
+		Out:       logger.Out,
+		Hooks:     logger.Hooks,
+		Level:     lvl,
+	})
+}
+
+func (ctx *lsc) getLogger() *log.Logger {
+	return ctx.logger.load()
+}
+
 func (ctx *lsc) ctxFields() map[string]interface{} {
 
 	fields := map[string]interface{}{
@@ -83,13 +117,13 @@ func (ctx *lsc) addkeyFieldOffsetFieldsToEntry(entry *log.Entry) {
 }
 
 func (ctx *lsc) WithField(key string, value interface{}) types.LogEntry {
-	return &entry{Entry: ctx.logger.WithField(key, value), ctx: ctx}
+	return &entry{Entry: ctx.getLogger().WithField(key, value), ctx: ctx}
 }
 func (ctx *lsc) WithFields(fields log.Fields) types.LogEntry {
-	return &entry{Entry: ctx.logger.WithFields(fields), ctx: ctx}
+	return &entry{Entry: ctx.getLogger().WithFields(fields), ctx: ctx}
 }
 func (ctx *lsc) WithError(err error) types.LogEntry {
-	return &entry{Entry: ctx.logger.WithError(err), ctx: ctx}
+	return &entry{Entry: ctx.getLogger().WithError(err), ctx: ctx}
 }
 
 func (ctx *lsc) Debugf(format string, args ...interface{}) {
@@ -125,67 +159,67 @@ func (ctx *lsc) Panicf(format string, args ...interface{}) {
 }
 
 func (ctx *lsc) Debug(args ...interface{}) {
-	ctx.logger.WithFields(ctx.ctxFields()).Debug(args...)
+	ctx.getLogger().WithFields(ctx.ctxFields()).Debug(args...)
 }
 
 func (ctx *lsc) Info(args ...interface{}) {
-	ctx.logger.WithFields(ctx.ctxFields()).Info(args...)
+	ctx.getLogger().WithFields(ctx.ctxFields()).Info(args...)
 }
 
 func (ctx *lsc) Print(args ...interface{}) {
-	ctx.logger.WithFields(ctx.ctxFields()).Print(args...)
+	ctx.getLogger().WithFields(ctx.ctxFields()).Print(args...)
 }
 
 func (ctx *lsc) Warn(args ...interface{}) {
-	ctx.logger.WithFields(ctx.ctxFields()).Warn(args...)
+	ctx.getLogger().WithFields(ctx.ctxFields()).Warn(args...)
 }
 
 func (ctx *lsc) Warning(args ...interface{}) {
-	ctx.logger.WithFields(ctx.ctxFields()).Warning(args...)
+	ctx.getLogger().WithFields(ctx.ctxFields()).Warning(args...)
 }
 
 func (ctx *lsc) Error(args ...interface{}) {
-	ctx.logger.WithFields(ctx.ctxFields()).Error(args...)
+	ctx.getLogger().WithFields(ctx.ctxFields()).Error(args...)
 }
 
 func (ctx *lsc) Fatal(args ...interface{}) {
-	ctx.logger.WithFields(ctx.ctxFields()).Fatal(args...)
+	ctx.getLogger().WithFields(ctx.ctxFields()).Fatal(args...)
 }
 
 func (ctx *lsc) Panic(args ...interface{}) {
-	ctx.logger.WithFields(ctx.ctxFields()).Panic(args...)
+	ctx.getLogger().WithFields(ctx.ctxFields()).Panic(args...)
 }
 
 func (ctx *lsc) Debugln(args ...interface{}) {
-	ctx.logger.Debug(args...)
+	ctx.getLogger().Debug(args...)
 }
 
 func (ctx *lsc) Infoln(args ...interface{}) {
-	ctx.logger.Info(args...)
+	ctx.getLogger().Info(args...)
 }
 
 func (ctx *lsc) Println(args ...interface{}) {
-	ctx.logger.Print(args...)
+	ctx.getLogger().Print(args...)
 }
 
 func (ctx *lsc) Warnln(args ...interface{}) {
-	ctx.logger.Warn(args...)
+	ctx.getLogger().Warn(args...)
 }
 
 func (ctx *lsc) Warningln(args ...interface{}) {
-	ctx.logger.Warning(args...)
+	ctx.getLogger().Warning(args...)
 }
 
 func (ctx *lsc) Errorln(args ...interface{}) {
-	ctx.logger.Error(args...)
+	ctx.getLogger().Error(args...)
 }
 
 func (ctx *lsc) Fatalln(args ...interface{}) {
-	ctx.logger.Fatal(args...)
+	ctx.getLogger().Fatal(args...)
 }
 
 func (ctx *lsc) Panicln(args ...interface{}) {
-	ctx.logger.Panic(args...)
+	ctx.getLogger().Panic(args...)
 }
 
 type entry struct {
@@ -6,6 +6,7 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
 )
 
 func TestMain(m *testing.M) {
@@ -110,3 +111,81 @@ func TestContextIDLogCustomLoggerKey(t *testing.T) {
 	ctx = ctx.WithValue(testLogKeyHello, "world")
 	ctx.Info("testing custom log keys")
 }
+
+func TestSetLogLevel(t *testing.T) {
+	ctx1 := Background()
+	SetLogLevel(ctx1, log.InfoLevel)
+	ctx2 := ctx1.WithValue(ServerKey, serverName)
+
+	lvl, ok := GetLogLevel(ctx2)
+	assert.True(t, ok)
+	assert.Equal(t, log.InfoLevel, lvl)
+
+	// changing the level of a context that owns its logger affects the
+	// contexts derived from it
+	SetLogLevel(ctx1, log.DebugLevel)
+	lvl, _ = GetLogLevel(ctx2)
+	assert.Equal(t, log.DebugLevel, lvl)
+
+	// changing the level of a derived context does not affect its parent
+	SetLogLevel(ctx2, log.ErrorLevel)
+	lvl, _ = GetLogLevel(ctx2)
+	assert.Equal(t, log.ErrorLevel, lvl)
+	lvl, _ = GetLogLevel(ctx1)
+	assert.Equal(t, log.DebugLevel, lvl)
+
+	// setting the shared level of a derived context sets the level of the
+	// logger it shares with the context from which it is derived
+	ctx3 := ctx1.WithValue(ServerKey, serverName)
+	SetSharedLogLevel(ctx3, log.WarnLevel)
+	lvl, _ = GetLogLevel(ctx1)
+	assert.Equal(t, log.WarnLevel, lvl)
+}
+
+func TestSetLogLevelWhileLogging(t *testing.T) {
+	ctx1 := Background()
+	SetLogLevel(ctx1, log.ErrorLevel)
+	ctx2 := ctx1.WithValue(ServerKey, serverName)
+
+	done := make(chan int)
+	go func() {
+		defer close(done)
+		for x := 0; x < 100; x++ {
+			ctx2.Debug("logging while the level changes")
+		}
+	}()
+	for x := 0; x < 100; x++ {
+		if x%2 == 0 {
+			SetLogLevel(ctx1, log.ErrorLevel)
+		} else {
+			SetSharedLogLevel(ctx2, log.PanicLevel)
+		}
+	}
+	<-done
+}
+
+func TestWithCancel(t *testing.T) {
+	ctx1 := Background().WithValue(ServerKey, serverName)
+	SetLogLevel(ctx1, log.InfoLevel)
+
+	ctx2, cancel := WithCancel(ctx1)
+	ctx3 := WithoutCancel(ctx2)
+	assert.NoError(t, ctx2.Err())
+
+	cancel()
+	<-ctx2.Done()
+	assert.Equal(t, context.Canceled, ctx2.Err())
+	assert.NoError(t, ctx3.Err())
+	assert.NoError(t, ctx1.Err())
+
+	v, ok := Server(ctx2)
+	assert.True(t, ok)
+	assert.Equal(t, serverName, v)
+	v, ok = Server(ctx3)
+	assert.True(t, ok)
+	assert.Equal(t, serverName, v)
+
+	lvl, ok := GetLogLevel(ctx3)
+	assert.True(t, ok)
+	assert.Equal(t, log.InfoLevel, lvl)
+}
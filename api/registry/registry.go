@@ -33,6 +33,12 @@ var (
 
 	routers    = []types.Router{}
 	routersRWL = &sync.RWMutex{}
+
+	secretProviderCtors    = map[string]types.NewSecretProvider{}
+	secretProviderCtorsRWL = &sync.RWMutex{}
+
+	caches    = []types.Cache{}
+	cachesRWL = &sync.RWMutex{}
 )
 
 type cregW struct {
@@ -54,6 +60,13 @@ func RegisterRouter(router types.Router) {
 	routers = append(routers, router)
 }
 
+// RegisterCache registers a Cache that may be flushed at runtime.
+func RegisterCache(cache types.Cache) {
+	cachesRWL.Lock()
+	defer cachesRWL.Unlock()
+	caches = append(caches, cache)
+}
+
 // RegisterStorageExecutor registers a StorageExecutor.
 func RegisterStorageExecutor(name string, ctor types.NewStorageExecutor) {
 	storExecsCtorsRWL.Lock()
@@ -91,6 +104,13 @@ func RegisterIntegrationDriver(name string, ctor types.NewIntegrationDriver) {
 	intDriverCtors[strings.ToLower(name)] = ctor
 }
 
+// RegisterSecretProvider registers a SecretProvider.
+func RegisterSecretProvider(name string, ctor types.NewSecretProvider) {
+	secretProviderCtorsRWL.Lock()
+	defer secretProviderCtorsRWL.Unlock()
+	secretProviderCtors[strings.ToLower(name)] = ctor
+}
+
 // NewStorageExecutor returns a new instance of the executor specified by the
 // executor name.
 func NewStorageExecutor(name string) (types.StorageExecutor, error) {
@@ -196,6 +216,27 @@ func NewIntegrationDriver(name string) (types.IntegrationDriver, error) {
 	return NewIntegrationDriverManager(ctor()), nil
 }
 
+// NewSecretProvider returns a new instance of the secret provider specified
+// by the provider name.
+func NewSecretProvider(name string) (types.SecretProvider, error) {
+
+	var ok bool
+	var ctor types.NewSecretProvider
+
+	func() {
+		secretProviderCtorsRWL.RLock()
+		defer secretProviderCtorsRWL.RUnlock()
+		ctor, ok = secretProviderCtors[strings.ToLower(name)]
+	}()
+
+	if !ok {
+		return nil, goof.WithField(
+			"provider", name, "invalid secret provider name")
+	}
+
+	return ctor(), nil
+}
+
 // ConfigRegs returns a channel on which all registered configuration
 // registrations are returned.
 func ConfigRegs(ctx types.Context) <-chan gofig.ConfigRegistration {
@@ -302,3 +343,17 @@ func Routers() <-chan types.Router {
 	}()
 	return c
 }
+
+// Caches returns a channel on which all registered caches can be received.
+func Caches() <-chan types.Cache {
+	c := make(chan types.Cache)
+	go func() {
+		cachesRWL.RLock()
+		defer cachesRWL.RUnlock()
+		for _, cache := range caches {
+			c <- cache
+		}
+		close(c)
+	}()
+	return c
+}
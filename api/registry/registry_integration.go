@@ -20,6 +20,10 @@ type idm struct {
 	used       map[string]int
 	retryCount int
 	retryWait  time.Duration
+
+	// registerPathCache ensures the path cache is registered once, even if
+	// the driver is initialized more than once.
+	registerPathCache sync.Once
 }
 
 // NewIntegrationDriverManager returns a new integration driver manager.
@@ -50,6 +54,11 @@ func (d *idm) Init(ctx types.Context, config gofig.Config) error {
 	}
 
 	d.initPathCache(ctx)
+	if d.pathCacheEnabled() {
+		d.registerPathCache.Do(func() {
+			RegisterCache(&idmPathCache{d})
+		})
+	}
 
 	ctx.WithFields(log.Fields{
 		types.ConfigIgVolOpsPathCacheEnabled:  d.pathCacheEnabled(),
@@ -94,6 +103,27 @@ func (d *idm) initPathCache(ctx types.Context) {
 	}
 }
 
+// idmPathCache enables the integration driver's path cache to be flushed
+// at runtime.
+type idmPathCache struct {
+	d *idm
+}
+
+func (c *idmPathCache) Name() string {
+	return "path"
+}
+
+// Flush resets the mount counts tracked by the path cache and then
+// reinitializes the cache.
+func (c *idmPathCache) Flush(ctx types.Context) int {
+	c.d.Lock()
+	n := len(c.d.used)
+	c.d.used = map[string]int{}
+	c.d.Unlock()
+	c.d.initPathCache(c.d.ctx)
+	return n
+}
+
 func (d *idm) List(
 	ctx types.Context,
 	opts types.Store) ([]types.VolumeMapping, error) {
@@ -38,6 +38,35 @@ func (d *sdm) XCLI() types.StorageExecutorCLI {
 	return nil
 }
 
+func (d *sdm) Validate(
+	ctx types.Context,
+	opts *types.ValidateOpts) error {
+
+	if sd, ok := d.StorageDriver.(types.StorageDriverWithValidate); ok {
+		return sd.Validate(ctx.Join(d.Context), opts)
+	}
+	return nil
+}
+
+func (d *sdm) AvailabilityZones(
+	ctx types.Context) ([]string, error) {
+
+	sd, ok := d.StorageDriver.(types.StorageDriverWithAvailabilityZones)
+	if ok {
+		return sd.AvailabilityZones(ctx.Join(d.Context))
+	}
+	return nil, nil
+}
+
+func (d *sdm) PersistsLabels(
+	ctx types.Context) bool {
+
+	if sd, ok := d.StorageDriver.(types.StorageDriverWithLabels); ok {
+		return sd.PersistsLabels(ctx.Join(d.Context))
+	}
+	return false
+}
+
 func (d *sdm) NextDeviceInfo(
 	ctx types.Context) (*types.NextDeviceInfo, error) {
 
@@ -177,3 +206,34 @@ func (d *sdmWithLogin) Login(
 
 	return d.StorageDriverWithLogin.Login(ctx.Join(d.Context))
 }
+
+func (d *sdmWithLogin) Validate(
+	ctx types.Context,
+	opts *types.ValidateOpts) error {
+
+	sd, ok := d.StorageDriverWithLogin.(types.StorageDriverWithValidate)
+	if ok {
+		return sd.Validate(ctx.Join(d.Context), opts)
+	}
+	return nil
+}
+
+func (d *sdmWithLogin) AvailabilityZones(
+	ctx types.Context) ([]string, error) {
+
+	sdl := d.StorageDriverWithLogin
+	if sd, ok := sdl.(types.StorageDriverWithAvailabilityZones); ok {
+		return sd.AvailabilityZones(ctx.Join(d.Context))
+	}
+	return nil, nil
+}
+
+func (d *sdmWithLogin) PersistsLabels(
+	ctx types.Context) bool {
+
+	sdl := d.StorageDriverWithLogin
+	if sd, ok := sdl.(types.StorageDriverWithLabels); ok {
+		return sd.PersistsLabels(ctx.Join(d.Context))
+	}
+	return false
+}
@@ -0,0 +1,335 @@
+// Package rpc defines the libStorage gRPC service described by
+// libstorage.proto. Each of the service's methods maps to a route of the
+// libStorage HTTP API, which allows the server to handle gRPC calls with the
+// same routers and middleware as HTTP requests. The request and reply
+// documents are the JSON documents of the HTTP API.
+package rpc
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// ServiceName is the full name of the libStorage gRPC service.
+	ServiceName = "libstorage.LibStorage"
+
+	// ServerNameMetadataKey is the key of the response header metadata that
+	// contains the server's name.
+	ServerNameMetadataKey = "libstorage-servername"
+
+	// ErrorMetadataKey is the key of the response trailer metadata that
+	// contains the JSON encoded API error of a failed call.
+	ErrorMetadataKey = "libstorage-error-bin"
+
+	// ChunkSize is the size of the chunks in which ExecutorGet streams an
+	// executor.
+	ChunkSize = 64 * 1024
+)
+
+// Method is a method of the libStorage gRPC service and the HTTP route to
+// which the method maps.
+type Method struct {
+
+	// Name is the method's name.
+	Name string
+
+	// HTTPMethod is the HTTP method of the route.
+	HTTPMethod string
+
+	// Path is the path of the route. The {service} and {id} placeholders are
+	// replaced with the Service and ID fields of a request.
+	Path string
+
+	// Query is the query that selects the route, if any.
+	Query string
+}
+
+// FullName returns the method's full name, ex. /libstorage.LibStorage/Root.
+func (m *Method) FullName() string {
+	return "/" + ServiceName + "/" + m.Name
+}
+
+// URL returns the URL of the HTTP request for a call to the method.
+func (m *Method) URL(in *Request) *url.URL {
+
+	p := strings.Replace(m.Path, "{service}", in.Service, 1)
+	p = strings.Replace(p, "{id}", in.ID, 1)
+
+	query := []string{}
+	if m.Query != "" {
+		query = append(query, m.Query)
+	}
+	if in.Attachments != 0 {
+		query = append(query,
+			"attachments="+strconv.Itoa(int(in.Attachments)))
+	}
+	if in.Since != 0 {
+		query = append(query, "since="+strconv.FormatInt(in.Since, 10))
+	}
+
+	// flags are encoded as a lone key, ex. ?force, to match the routes'
+	// query parameters the same way the HTTP client does
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"instance", in.Instance},
+		{"zones", in.Zones},
+		{"partial", in.Partial},
+		{"force", in.Force},
+		{"async", in.Async},
+		{"cancel", in.Cancel},
+	} {
+		if f.set {
+			query = append(query, f.name)
+		}
+	}
+
+	return &url.URL{Path: p, RawQuery: strings.Join(query, "&")}
+}
+
+var (
+	// Root returns a list of root resources.
+	Root = &Method{"Root", http.MethodGet, "/", ""}
+
+	// Services returns a map of the configured services.
+	Services = &Method{"Services", http.MethodGet, "/services", ""}
+
+	// ServiceInspect returns information about a service.
+	ServiceInspect = &Method{
+		"ServiceInspect", http.MethodGet, "/services/{service}", ""}
+
+	// StorageClasses returns the storage classes of a service.
+	StorageClasses = &Method{
+		"StorageClasses", http.MethodGet, "/services/{service}/classes", ""}
+
+	// Quotas returns the quota usage and limits of all subjects and services.
+	Quotas = &Method{"Quotas", http.MethodGet, "/quotas", ""}
+
+	// SubjectQuota returns the quota usage and limits of an auth subject.
+	SubjectQuota = &Method{
+		"SubjectQuota", http.MethodGet, "/quotas/{id}", ""}
+
+	// Volumes returns the volumes of all services.
+	Volumes = &Method{"Volumes", http.MethodGet, "/volumes", ""}
+
+	// VolumesByService returns the volumes of a service.
+	VolumesByService = &Method{
+		"VolumesByService", http.MethodGet, "/volumes/{service}", ""}
+
+	// VolumesWatch waits for the volumes of a service to change.
+	VolumesWatch = &Method{
+		"VolumesWatch",
+		http.MethodGet, "/volumes/{service}", "watch=true"}
+
+	// VolumeInspect inspects a volume.
+	VolumeInspect = &Method{
+		"VolumeInspect", http.MethodGet, "/volumes/{service}/{id}", ""}
+
+	// VolumeCreate creates a volume.
+	VolumeCreate = &Method{
+		"VolumeCreate", http.MethodPost, "/volumes/{service}", ""}
+
+	// VolumeCreateFromSnapshot creates a volume from a snapshot.
+	VolumeCreateFromSnapshot = &Method{
+		"VolumeCreateFromSnapshot",
+		http.MethodPost, "/snapshots/{service}/{id}", "create"}
+
+	// VolumeCopy copies a volume.
+	VolumeCopy = &Method{
+		"VolumeCopy", http.MethodPost, "/volumes/{service}/{id}", "copy"}
+
+	// VolumeRemove removes a volume.
+	VolumeRemove = &Method{
+		"VolumeRemove", http.MethodDelete, "/volumes/{service}/{id}", ""}
+
+	// VolumeAttach attaches a volume.
+	VolumeAttach = &Method{
+		"VolumeAttach", http.MethodPost, "/volumes/{service}/{id}", "attach"}
+
+	// VolumeDetach detaches a volume.
+	VolumeDetach = &Method{
+		"VolumeDetach", http.MethodPost, "/volumes/{service}/{id}", "detach"}
+
+	// VolumeDetachAll detaches all volumes of all services.
+	VolumeDetachAll = &Method{
+		"VolumeDetachAll", http.MethodPost, "/volumes", "detach"}
+
+	// VolumeDetachAllForService detaches all volumes of a service.
+	VolumeDetachAllForService = &Method{
+		"VolumeDetachAllForService",
+		http.MethodPost, "/volumes/{service}", "detach"}
+
+	// VolumeSnapshot creates a snapshot of a volume.
+	VolumeSnapshot = &Method{
+		"VolumeSnapshot",
+		http.MethodPost, "/volumes/{service}/{id}", "snapshot"}
+
+	// Snapshots returns the snapshots of all services.
+	Snapshots = &Method{"Snapshots", http.MethodGet, "/snapshots", ""}
+
+	// SnapshotsByService returns the snapshots of a service.
+	SnapshotsByService = &Method{
+		"SnapshotsByService", http.MethodGet, "/snapshots/{service}", ""}
+
+	// SnapshotInspect inspects a snapshot.
+	SnapshotInspect = &Method{
+		"SnapshotInspect", http.MethodGet, "/snapshots/{service}/{id}", ""}
+
+	// SnapshotRemove removes a snapshot.
+	SnapshotRemove = &Method{
+		"SnapshotRemove",
+		http.MethodDelete, "/snapshots/{service}/{id}", ""}
+
+	// SnapshotCopy copies a snapshot.
+	SnapshotCopy = &Method{
+		"SnapshotCopy", http.MethodPost, "/snapshots/{service}/{id}", "copy"}
+
+	// Tasks returns the server's tasks.
+	Tasks = &Method{"Tasks", http.MethodGet, "/tasks", ""}
+
+	// TaskInspect inspects a task.
+	TaskInspect = &Method{"TaskInspect", http.MethodGet, "/tasks/{id}", ""}
+
+	// TaskCancel cancels a task.
+	TaskCancel = &Method{
+		"TaskCancel", http.MethodDelete, "/tasks/{id}", ""}
+
+	// TaskWatch streams a task and then streams it again once the task
+	// completes.
+	TaskWatch = &Method{"TaskWatch", http.MethodGet, "/tasks/{id}", ""}
+
+	// Executors returns information about the executors.
+	Executors = &Method{"Executors", http.MethodGet, "/executors", ""}
+
+	// ExecutorHead returns information about an executor as the headers
+	// of the response.
+	ExecutorHead = &Method{
+		"ExecutorHead", http.MethodHead, "/executors/{id}", ""}
+
+	// ExecutorGet streams an executor in chunks.
+	ExecutorGet = &Method{
+		"ExecutorGet", http.MethodGet, "/executors/{id}", ""}
+
+	// UnaryMethods are the service's unary methods.
+	UnaryMethods = []*Method{
+		Root,
+		Services,
+		ServiceInspect,
+		StorageClasses,
+		Quotas,
+		SubjectQuota,
+		Volumes,
+		VolumesByService,
+		VolumesWatch,
+		VolumeInspect,
+		VolumeCreate,
+		VolumeCreateFromSnapshot,
+		VolumeCopy,
+		VolumeRemove,
+		VolumeAttach,
+		VolumeDetach,
+		VolumeDetachAll,
+		VolumeDetachAllForService,
+		VolumeSnapshot,
+		Snapshots,
+		SnapshotsByService,
+		SnapshotInspect,
+		SnapshotRemove,
+		SnapshotCopy,
+		Tasks,
+		TaskInspect,
+		TaskCancel,
+		Executors,
+		ExecutorHead,
+	}
+)
+
+// Request is the request for all of the service's methods. The fields that
+// do not apply to a method are ignored.
+type Request struct {
+	Service     string `protobuf:"bytes,1,opt,name=service" json:"service,omitempty"`
+	ID          string `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
+	JSON        []byte `protobuf:"bytes,3,opt,name=json,proto3" json:"json,omitempty"`
+	Attachments int32  `protobuf:"varint,4,opt,name=attachments" json:"attachments,omitempty"`
+	Since       int64  `protobuf:"varint,5,opt,name=since" json:"since,omitempty"`
+	Instance    bool   `protobuf:"varint,6,opt,name=instance" json:"instance,omitempty"`
+	Zones       bool   `protobuf:"varint,7,opt,name=zones" json:"zones,omitempty"`
+	Partial     bool   `protobuf:"varint,8,opt,name=partial" json:"partial,omitempty"`
+	Force       bool   `protobuf:"varint,9,opt,name=force" json:"force,omitempty"`
+	Async       bool   `protobuf:"varint,10,opt,name=async" json:"async,omitempty"`
+	Cancel      bool   `protobuf:"varint,11,opt,name=cancel" json:"cancel,omitempty"`
+}
+
+// Reset resets the message.
+func (m *Request) Reset() { *m = Request{} }
+
+// String returns the message's text representation.
+func (m *Request) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage marks the type as a protobuf message.
+func (*Request) ProtoMessage() {}
+
+// Response is the response of the service's unary methods and the messages
+// streamed by TaskWatch.
+type Response struct {
+	JSON    []byte            `protobuf:"bytes,1,opt,name=json,proto3" json:"json,omitempty"`
+	Headers map[string]string `protobuf:"bytes,2,rep,name=headers" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+// Reset resets the message.
+func (m *Response) Reset() { *m = Response{} }
+
+// String returns the message's text representation.
+func (m *Response) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage marks the type as a protobuf message.
+func (*Response) ProtoMessage() {}
+
+// Chunk is a part of a file streamed by ExecutorGet.
+type Chunk struct {
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+// Reset resets the message.
+func (m *Chunk) Reset() { *m = Chunk{} }
+
+// String returns the message's text representation.
+func (m *Chunk) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage marks the type as a protobuf message.
+func (*Chunk) ProtoMessage() {}
+
+// Code returns the gRPC status code for an HTTP status code.
+func Code(status int) codes.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	if status >= 500 {
+		return codes.Internal
+	}
+	return codes.Unknown
+}
@@ -0,0 +1,43 @@
+package rpc
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestMethodURL(t *testing.T) {
+
+	u := VolumeAttach.URL(&Request{Service: "vfs", ID: "vfs-000"})
+	assert.Equal(t, "/volumes/vfs/vfs-000?attach", u.String())
+
+	u = VolumesWatch.URL(&Request{Service: "vfs", Since: 2})
+	assert.Equal(t, "/volumes/vfs?watch=true&since=2", u.String())
+
+	u = VolumeRemove.URL(&Request{
+		Service: "vfs",
+		ID:      "vfs-001",
+		Force:   true,
+		Async:   true,
+	})
+	assert.Equal(t, "/volumes/vfs/vfs-001?force&async", u.String())
+
+	u = Volumes.URL(&Request{Attachments: 3, Partial: true})
+	assert.Equal(t, "/volumes?attachments=3&partial", u.String())
+
+	u = Services.URL(&Request{})
+	assert.Equal(t, "/services", u.String())
+
+	assert.Equal(t,
+		"/libstorage.LibStorage/VolumeAttach", VolumeAttach.FullName())
+}
+
+func TestCode(t *testing.T) {
+	assert.Equal(t, codes.NotFound, Code(http.StatusNotFound))
+	assert.Equal(t, codes.PermissionDenied, Code(http.StatusForbidden))
+	assert.Equal(t, codes.Unauthenticated, Code(http.StatusUnauthorized))
+	assert.Equal(t, codes.Internal, Code(http.StatusInternalServerError))
+	assert.Equal(t, codes.Unknown, Code(http.StatusTeapot))
+}
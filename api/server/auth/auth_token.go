@@ -15,8 +15,13 @@ import (
 
 var rxBearer = regexp.MustCompile(`Bearer (.+)`)
 
-// GetBearerTokenFromReq retrieves the bearer token from the HTTP request.
+// GetBearerTokenFromReq retrieves the bearer token from the HTTP request. The
+// token of a request handled in process by an embedded server is provided by
+// the embedded request instead.
 func GetBearerTokenFromReq(ctx types.Context, req *http.Request) string {
+	if er, ok := context.EmbeddedRequest(ctx); ok {
+		return er.AuthToken
+	}
 	m := rxBearer.FindStringSubmatch(
 		req.Header.Get(types.AuthorizationHeader))
 	if len(m) == 0 {
@@ -78,6 +83,22 @@ func getSubject(s string) string {
 	return s
 }
 
+// IsSubjectAllowed returns a flag indicating whether or not the provided
+// subject is allowed, and not denied, by the auth config.
+func IsSubjectAllowed(config *types.AuthConfig, subject string) bool {
+	for _, v := range config.Deny {
+		if strings.EqualFold(getSubject(v), subject) {
+			return false
+		}
+	}
+	for _, v := range config.Allow {
+		if strings.EqualFold(getSubject(v), subject) {
+			return true
+		}
+	}
+	return false
+}
+
 func validateAuthTokenAllowed(
 	ctx types.Context,
 	config *types.AuthConfig,
@@ -174,12 +195,22 @@ func ValidateAuthTokenWithJWT(
 		IssuedAt:  iat.UTC().Unix(),
 		Expires:   exp.UTC().Unix(),
 		NotBefore: nbf.UTC().Unix(),
+		Roles:     parseRoles(jwt.Claims().Get("roles")),
+	}
+	if v, ok := jwt.Claims().Get("namespace").(string); ok {
+		tok.Namespace = v
 	}
 
 	lf["sub"] = tok.Subject
 	lf["iat"] = tok.IssuedAt
 	lf["exp"] = tok.Expires
 	lf["nbf"] = tok.NotBefore
+	if len(tok.Roles) > 0 {
+		lf["roles"] = tok.Roles
+	}
+	if tok.Namespace != "" {
+		lf["namespace"] = tok.Namespace
+	}
 
 	if err := validateAuthTokenAllowed(ctx, config, lf, tok); err != nil {
 		return nil, err
@@ -189,6 +220,29 @@ func ValidateAuthTokenWithJWT(
 	return tok, nil
 }
 
+// parseRoles parses the value of a token's roles claim, which may be a
+// single role or a list of roles.
+func parseRoles(v interface{}) []string {
+	switch tv := v.(type) {
+	case string:
+		if tv == "" {
+			return nil
+		}
+		return []string{tv}
+	case []string:
+		return tv
+	case []interface{}:
+		roles := []string{}
+		for _, r := range tv {
+			if s, ok := r.(string); ok && s != "" {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	}
+	return nil
+}
+
 var signingMethods = []jcrypto.SigningMethod{
 	jcrypto.SigningMethodES256,
 	jcrypto.SigningMethodES384,
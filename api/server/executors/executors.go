@@ -1,8 +1,18 @@
 package executors
 
 import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
 	"encoding/json"
-	"regexp"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	// depend upon this tool with a nil import in order to preserve it
 	// in the dependency list
@@ -13,35 +23,92 @@ import (
 )
 
 var (
-	executors = map[string]*ExecutorInfoEx{}
-	pathRX    = regexp.MustCompile(`^lsx-(.+?)(?:.exe)?$`)
+	executors       = map[string]*ExecutorInfoEx{}
+	defaultRegistry = NewRegistry("")
 )
 
 func init() {
 	for path, bdFunc := range _bindata {
+
+		// an executor's detached signature is embedded alongside the
+		// executor when the executors are signed at build time
+		if strings.HasSuffix(path, utils.SignatureFileExt) {
+			continue
+		}
+
 		bd, err := bdFunc()
 
 		if err != nil {
 			panic(err)
 		}
 
+		goos, goarch, _ := utils.ParseExecutorName(path)
+
 		executors[path] = &ExecutorInfoEx{
 			ExecutorInfo: types.ExecutorInfo{
-				Name:         path,
-				MD5Checksum:  bd.info.MD5Checksum(),
-				Size:         bd.info.Size(),
-				LastModified: bd.info.ModTime().Unix(),
+				Name:           path,
+				OS:             goos,
+				Arch:           goarch,
+				MD5Checksum:    bd.info.MD5Checksum(),
+				SHA256Checksum: fmt.Sprintf("%x", sha256.Sum256(bd.bytes)),
+				Signature:      signature(path),
+				Size:           bd.info.Size(),
+				LastModified:   bd.info.ModTime().Unix(),
 			},
 		}
 	}
 }
 
+// signature returns the detached signature of the executor with the
+// provided name, or an empty string if the executor is not signed.
+func signature(name string) string {
+	bdFunc, ok := _bindata[name+utils.SignatureFileExt]
+	if !ok {
+		return ""
+	}
+	bd, err := bdFunc()
+	if err != nil {
+		panic(err)
+	}
+	return string(bytes.TrimSpace(bd.bytes))
+}
+
 // ExecutorInfos returns a channel on which all executor information can be
 // received.
 func ExecutorInfos() <-chan *ExecutorInfoEx {
+	return defaultRegistry.ExecutorInfos()
+}
+
+// ExecutorInfoInspect returns the executor info for the provided name.
+func ExecutorInfoInspect(name string, data bool) (*ExecutorInfoEx, error) {
+	return defaultRegistry.ExecutorInfoInspect(name, data)
+}
+
+// Registry provides the executors embedded in the server as well as the
+// executors in a directory. The directory is read every time the registry is
+// accessed, so executors may be added to or replaced in the directory while
+// the server is running. An executor in the directory takes precedence over
+// an embedded executor with the same name.
+type Registry struct {
+	dir       string
+	dirLock   sync.Mutex
+	executors map[string]*ExecutorInfoEx
+}
+
+// NewRegistry returns a new executor registry that provides the executors in
+// the provided directory in addition to the embedded executors. Only the
+// embedded executors are provided if the directory is empty.
+func NewRegistry(dir string) *Registry {
+	return &Registry{dir: dir, executors: map[string]*ExecutorInfoEx{}}
+}
+
+// ExecutorInfos returns a channel on which all executor information can be
+// received.
+func (r *Registry) ExecutorInfos() <-chan *ExecutorInfoEx {
+	eis := r.list()
 	c := make(chan *ExecutorInfoEx)
 	go func() {
-		for _, v := range executors {
+		for _, v := range eis {
 			c <- v
 		}
 		close(c)
@@ -49,14 +116,41 @@ func ExecutorInfos() <-chan *ExecutorInfoEx {
 	return c
 }
 
-// ExecutorInfoInspect returns the executor info for the provided name.
-func ExecutorInfoInspect(name string, data bool) (*ExecutorInfoEx, error) {
-	ei, ok := executors[name]
+// ExecutorInfoInspect returns the executor info for the provided name. If
+// there is no executor with the name, the info of the executor built for
+// the same operating system and architecture is returned, so clients may
+// request an executor by a name that does not include the architecture.
+func (r *Registry) ExecutorInfoInspect(
+	name string, data bool) (*ExecutorInfoEx, error) {
+
+	eis := r.list()
+
+	ei, ok := eis[name]
 	if !ok {
-		return nil, utils.NewNotFoundError(name)
+		goos, goarch, isLSX := utils.ParseExecutorName(name)
+		if !isLSX {
+			return nil, utils.NewNotFoundError(name)
+		}
+		// the names are sorted so the same executor is always returned
+		for _, k := range sortedNames(eis) {
+			if eis[k].OS == goos && eis[k].Arch == goarch {
+				ei = eis[k]
+				break
+			}
+		}
+		if ei == nil {
+			return nil, utils.NewNotFoundError(name)
+		}
 	}
+
+	// a copy is returned so requests do not share the executor's data
+	eiCopy := &ExecutorInfoEx{ExecutorInfo: ei.ExecutorInfo}
 	if !data {
-		return ei, nil
+		return eiCopy, nil
+	}
+
+	if ei.filePath != "" {
+		return readExecutorFile(ei.filePath)
 	}
 
 	bd, ok := _bindata[ei.Name]
@@ -67,14 +161,130 @@ func ExecutorInfoInspect(name string, data bool) (*ExecutorInfoEx, error) {
 	if err != nil {
 		return nil, err
 	}
-	ei.Data = a.bytes
-	return ei, nil
+	eiCopy.Data = a.bytes
+	return eiCopy, nil
+}
+
+// list returns the embedded executors and the executors in the registry's
+// directory. A directory that cannot be read is ignored.
+func (r *Registry) list() map[string]*ExecutorInfoEx {
+
+	eis := map[string]*ExecutorInfoEx{}
+	for k, v := range executors {
+		eis[k] = v
+	}
+	if r.dir == "" {
+		return eis
+	}
+
+	fis, err := ioutil.ReadDir(r.dir)
+	if err != nil {
+		return eis
+	}
+
+	r.dirLock.Lock()
+	defer r.dirLock.Unlock()
+
+	dirExecutors := map[string]*ExecutorInfoEx{}
+	for _, fi := range fis {
+		name := fi.Name()
+		if fi.IsDir() {
+			continue
+		}
+		if _, _, ok := utils.ParseExecutorName(name); !ok {
+			continue
+		}
+
+		// an executor is only read again if it was modified
+		ei, ok := r.executors[name]
+		if !ok || !ei.modTime.Equal(fi.ModTime()) || ei.Size != fi.Size() {
+			if ei, err = readExecutorFile(path.Join(r.dir, name)); err != nil {
+				continue
+			}
+			ei.Data = nil
+		} else {
+			ei = &ExecutorInfoEx{
+				ExecutorInfo: ei.ExecutorInfo,
+				filePath:     ei.filePath,
+				modTime:      ei.modTime,
+			}
+			ei.Signature = readSignatureFile(ei.filePath)
+		}
+
+		dirExecutors[name] = ei
+		eis[name] = ei
+	}
+	r.executors = dirExecutors
+
+	return eis
+}
+
+// readExecutorFile returns the info and the data of the executor file at
+// the provided path. The executor's signature is read from the file with the
+// same name and the signature file name extension, if it exists.
+func readExecutorFile(filePath string) (*ExecutorInfoEx, error) {
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	buf, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	name := path.Base(filePath)
+	goos, goarch, _ := utils.ParseExecutorName(name)
+
+	return &ExecutorInfoEx{
+		ExecutorInfo: types.ExecutorInfo{
+			Name:           name,
+			OS:             goos,
+			Arch:           goarch,
+			MD5Checksum:    fmt.Sprintf("%x", md5.Sum(buf)),
+			SHA256Checksum: fmt.Sprintf("%x", sha256.Sum256(buf)),
+			Signature:      readSignatureFile(filePath),
+			Size:           int64(len(buf)),
+			LastModified:   fi.ModTime().Unix(),
+		},
+		Data:     buf,
+		filePath: filePath,
+		modTime:  fi.ModTime(),
+	}, nil
+}
+
+// readSignatureFile returns the detached signature of the executor file at
+// the provided path, or an empty string if the executor is not signed.
+func readSignatureFile(filePath string) string {
+	buf, err := ioutil.ReadFile(filePath + utils.SignatureFileExt)
+	if err != nil {
+		return ""
+	}
+	return string(bytes.TrimSpace(buf))
+}
+
+func sortedNames(eis map[string]*ExecutorInfoEx) []string {
+	names := make([]string, 0, len(eis))
+	for k := range eis {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // ExecutorInfoEx is an extension of ExecutorInfo
 type ExecutorInfoEx struct {
 	types.ExecutorInfo
 	Data []byte `json:"-"`
+
+	filePath string
+	modTime  time.Time
 }
 
 // MarshalJSON marshals the ExecutorInfoEx to JSON.
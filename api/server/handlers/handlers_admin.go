@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/auth"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// adminHandler is an HTTP filter for granting access to the admin API.
+type adminHandler struct {
+	handler types.APIFunc
+}
+
+// NewAdminHandler returns a new adminHandler. Access is granted if the
+// request provides the server's admin token, either with the admin token
+// header or the "admin" query parameter, or if the request's bearer token is
+// valid and its subject is in the admin allow list.
+func NewAdminHandler() types.Middleware {
+	return &adminHandler{}
+}
+
+func (h *adminHandler) Name() string {
+	return "admin-handler"
+}
+
+func (h *adminHandler) Handler(m types.APIFunc) types.APIFunc {
+	return (&adminHandler{m}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *adminHandler) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	if actualToken := getAdminToken(req, store); actualToken != "" {
+		if !isAdminToken(ctx, actualToken) {
+			return utils.NewBadAdminTokenError(actualToken)
+		}
+		ctx.Debug("validated admin token")
+		return h.handler(ctx, w, req, store)
+	}
+
+	config, ok := ctx.Value(context.AdminAuthConfigKey).(*types.AuthConfig)
+	if !ok || auth.GetBearerTokenFromReq(ctx, req) == "" {
+		return utils.NewBadAdminTokenError("missing")
+	}
+
+	tok, err := auth.ValidateAuthTokenWithReq(ctx, config, req)
+	if err != nil {
+		return err
+	}
+
+	ctx.WithField("sub", tok.Subject).Debug("validated admin security token")
+	return h.handler(ctx, w, req, store)
+}
+
+// IsAdmin returns a flag indicating whether or not a request provides the
+// server's admin token or a valid security token whose subject is in the
+// admin allow list.
+func IsAdmin(ctx types.Context, req *http.Request, store types.Store) bool {
+	if tok := getAdminToken(req, store); tok != "" {
+		return isAdminToken(ctx, tok)
+	}
+	config, ok := ctx.Value(context.AdminAuthConfigKey).(*types.AuthConfig)
+	if !ok || auth.GetBearerTokenFromReq(ctx, req) == "" {
+		return false
+	}
+	_, err := auth.ValidateAuthTokenWithReq(ctx, config, req)
+	return err == nil
+}
+
+// getAdminToken returns the admin token provided with the request, if any.
+func getAdminToken(req *http.Request, store types.Store) string {
+	if v := req.Header.Get(types.AdminTokenHeader); v != "" {
+		return v
+	}
+	return store.GetString("admin")
+}
+
+// isAdminToken returns a flag indicating whether or not the provided token
+// matches the server's admin token.
+func isAdminToken(ctx types.Context, actualToken string) bool {
+	expectedToken, ok := ctx.Value(context.AdminTokenKey).(string)
+	return ok && subtle.ConstantTimeCompare(
+		[]byte(expectedToken), []byte(actualToken)) == 1
+}
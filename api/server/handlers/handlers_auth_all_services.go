@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/server/auth"
 	"github.com/codedellemc/libstorage/api/server/services"
 	"github.com/codedellemc/libstorage/api/types"
@@ -33,6 +34,12 @@ func (h *authAllSvcsHandler) Handle(
 	req *http.Request,
 	store types.Store) error {
 
+	if endpointAuthDisabled(ctx) {
+		ctx.Debug("skipping all services auth handler; endpoint auth disabled")
+		return h.handler(ctx, w, req, store)
+	}
+
+	var tok *types.AuthToken
 	for svc := range services.StorageServices(ctx) {
 		if svc.AuthConfig() == nil {
 			ctx.WithField("service", svc.Name()).Debug(
@@ -43,14 +50,21 @@ func (h *authAllSvcsHandler) Handle(
 			ctx.Debug("skipping svc auth handler; empty allow & deny lists")
 			continue
 		}
-		_, err := auth.ValidateAuthTokenWithCtxOrReq(
+		svcTok, err := auth.ValidateAuthTokenWithCtxOrReq(
 			ctx, svc.AuthConfig(), req)
 		if err != nil {
 			return err
 		}
+		tok = svcTok
 	}
 
 	ctx.Debug("validated all services access")
 
+	// the token is the same for all services, so any validated copy of it
+	// is made available to the rest of the request
+	if _, ok := context.AuthToken(ctx); !ok && tok != nil {
+		ctx = ctx.WithValue(context.AuthTokenKey, tok)
+	}
+
 	return h.handler(ctx, w, req, store)
 }
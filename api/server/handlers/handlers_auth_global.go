@@ -40,6 +40,11 @@ func (h *authGlobalHandler) Handle(
 		return h.handler(ctx, w, req, store)
 	}
 
+	if h.config.Disabled {
+		ctx.Debug("skipping global auth handler; auth disabled")
+		return h.handler(ctx, w, req, store)
+	}
+
 	if len(h.config.Allow) == 0 && len(h.config.Deny) == 0 {
 		ctx.Debug("skipping global auth handler; empty allow & deny lists")
 		return h.handler(ctx, w, req, store)
@@ -59,3 +64,11 @@ func (h *authGlobalHandler) Handle(
 	return h.handler(
 		ctx.WithValue(context.AuthTokenKey, tok), w, req, store)
 }
+
+// endpointAuthDisabled returns a flag indicating whether or not the endpoint
+// that received the request disables auth, including the auth of the storage
+// services.
+func endpointAuthDisabled(ctx types.Context) bool {
+	ep, ok := context.Endpoint(ctx)
+	return ok && ep.AuthDisabled()
+}
@@ -38,6 +38,11 @@ func (h *authSvcHandler) Handle(
 		return types.ErrMissingStorageService
 	}
 
+	if endpointAuthDisabled(ctx) {
+		ctx.Debug("skipping service auth handler; endpoint auth disabled")
+		return h.handler(ctx, w, req, store)
+	}
+
 	if svc.AuthConfig() == nil {
 		ctx.Debug("skipping service auth handler; empty auth config")
 		return h.handler(ctx, w, req, store)
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/akutz/goof"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// contentTypeHandler is a global HTTP filter for negotiating the media type
+// of request and response bodies
+type contentTypeHandler struct {
+	handler types.APIFunc
+}
+
+// NewContentTypeHandler returns a new global HTTP filter for negotiating the
+// media type of request and response bodies. YAML request bodies are
+// transcoded to JSON before they reach the route handlers, and the objects
+// of responses are marshaled as YAML when the client's Accept header prefers
+// it.
+func NewContentTypeHandler() types.Middleware {
+	return &contentTypeHandler{}
+}
+
+func (h *contentTypeHandler) Name() string {
+	return "content-type-handler"
+}
+
+func (h *contentTypeHandler) Handler(m types.APIFunc) types.APIFunc {
+	return (&contentTypeHandler{m}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *contentTypeHandler) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	if types.ParseContentType(req.Header.Get("Content-Type")).IsYAML() {
+		buf, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return err
+		}
+		req.Body.Close()
+		if buf, err = utils.YAMLToJSON(buf); err != nil {
+			return goof.WithError("error transcoding yaml req body", err)
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(buf))
+		req.ContentLength = int64(len(buf))
+		req.Header.Set("Content-Type", types.ContentTypeJSON.String())
+		ctx.Debug("transcoded yaml request body to json")
+	}
+
+	if !acceptsYAML(req) {
+		return h.handler(ctx, w, req, store)
+	}
+	return h.handler(ctx, &yamlResponseWriter{w}, req, store)
+}
+
+// acceptsYAML returns a flag indicating whether or not the first media type
+// in the request's Accept header that is either JSON or YAML is YAML.
+func acceptsYAML(req *http.Request) bool {
+	for _, a := range strings.Split(req.Header.Get("Accept"), ",") {
+		ct := types.ParseContentType(a)
+		if ct.IsYAML() {
+			return true
+		}
+		if ct == types.ContentTypeJSON {
+			return false
+		}
+	}
+	return false
+}
+
+// yamlResponseWriter marshals the objects of a response as YAML. Binary
+// data, such as executors, is written as it is.
+type yamlResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *yamlResponseWriter) WriteObject(code int, v interface{}) error {
+	w.Header().Set("Content-Type", types.ContentTypeYAML.String())
+	w.WriteHeader(code)
+	buf, err := marshalYAML(v)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *yamlResponseWriter) EncodesObjects() bool {
+	return true
+}
+
+// marshalYAML marshals an object as YAML. Objects that define only their
+// JSON representation, such as errors, are transcoded from it so that their
+// keys are the same in both representations.
+func marshalYAML(v interface{}) ([]byte, error) {
+	if _, ok := v.(yaml.Marshaler); !ok {
+		if jm, ok := v.(json.Marshaler); ok {
+			buf, err := jm.MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			return utils.JSONToYAML(buf)
+		}
+	}
+	return yaml.Marshal(v)
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akutz/goof"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/httputils"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// serveContentType sends a request that accepts YAML through the content
+// type handler to a route that replies with the object v. Binary data is
+// written as it is.
+func serveContentType(
+	t *testing.T, code int, v interface{}) *httptest.ResponseRecorder {
+
+	req, err := http.NewRequest(http.MethodGet, "/volumes", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	req.Header.Set("Accept", types.ContentTypeYAML.String())
+
+	h := NewContentTypeHandler().Handler(func(
+		ctx types.Context,
+		w http.ResponseWriter,
+		req *http.Request,
+		store types.Store) error {
+
+		if buf, ok := v.([]byte); ok {
+			return httputils.WriteData(w, code, buf)
+		}
+		return httputils.WriteJSON(w, code, v)
+	})
+
+	rec := httptest.NewRecorder()
+	if !assert.NoError(t, h(context.Background(), rec, req, utils.NewStore())) {
+		t.FailNow()
+	}
+	return rec
+}
+
+// assertYAMLResponse asserts the response is the YAML representation of v
+// with the same keys as the JSON representation of v.
+func assertYAMLResponse(
+	t *testing.T, rec *httptest.ResponseRecorder, code int, v interface{}) {
+
+	assert.Equal(t, code, rec.Code)
+	assert.Equal(t,
+		types.ContentTypeYAML.String(), rec.Header().Get("Content-Type"))
+
+	actual, err := utils.YAMLToJSON(rec.Body.Bytes())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	expected, err := json.Marshal(v)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.JSONEq(t, string(expected), string(actual))
+}
+
+func TestContentTypeHandlerYAML(t *testing.T) {
+	vol := &types.Volume{
+		ID:               "vfs-000",
+		Name:             "Volume 000",
+		AvailabilityZone: "US",
+		AttachmentState:  types.VolumeAvailable,
+		Size:             10240,
+		Fields:           map[string]string{"owner": "root@example.com"},
+	}
+	rec := serveContentType(t, http.StatusOK, vol)
+	assertYAMLResponse(t, rec, http.StatusOK, vol)
+	assert.Contains(t, rec.Body.String(), "availabilityZone: US")
+}
+
+func TestContentTypeHandlerYAMLTask(t *testing.T) {
+	task := &types.Task{
+		ID:     1,
+		State:  types.TaskStateError,
+		Result: map[string]interface{}{"size": int64(10240)},
+		Error:  goof.WithField("volumeID", "vfs-000", "volume attached"),
+	}
+	rec := serveContentType(t, http.StatusOK, task)
+	assertYAMLResponse(t, rec, http.StatusOK, task)
+}
+
+func TestContentTypeHandlerYAMLError(t *testing.T) {
+	httpErr := goof.NewHTTPError(
+		utils.NewNotFoundError("vfs-999"), http.StatusNotFound)
+	rec := serveContentType(t, http.StatusNotFound, httpErr)
+	assertYAMLResponse(t, rec, http.StatusNotFound, httpErr)
+}
+
+func TestContentTypeHandlerBinary(t *testing.T) {
+	data := []byte{0x7f, 'E', 'L', 'F', 0x00, 0xff}
+	rec := serveContentType(t, http.StatusOK, data)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t,
+		"application/octet-stream", rec.Header().Get("Content-Type"))
+	assert.Equal(t, data, rec.Body.Bytes())
+}
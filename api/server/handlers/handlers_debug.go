@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/codedellemc/gournal"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/auth"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// debugHandler is a global HTTP filter for enabling debug logging for the
+// scope of a single request.
+type debugHandler struct {
+	handler types.APIFunc
+}
+
+// NewDebugHandler returns a new global HTTP filter for enabling debug logging
+// for the scope of a single request. Debug logging is enabled only if the
+// request's debug header is true and the request provides either the
+// server's admin token or a security token whose subject is allowed to
+// enable debug logging.
+func NewDebugHandler() types.Middleware {
+	return &debugHandler{}
+}
+
+func (h *debugHandler) Name() string {
+	return "debug-handler"
+}
+
+func (h *debugHandler) Handler(m types.APIFunc) types.APIFunc {
+	return (&debugHandler{m}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *debugHandler) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	debugHeader := req.Header.Get(types.DebugHeader)
+	if er, ok := context.EmbeddedRequest(ctx); ok {
+		debugHeader = strconv.FormatBool(er.Debug)
+	}
+	if debug, _ := strconv.ParseBool(debugHeader); !debug {
+		return h.handler(ctx, w, req, store)
+	}
+
+	if !isDebugAuthorized(ctx, req, store) {
+		ctx.WithField(types.DebugHeader, debugHeader).Warn(
+			"ignoring debug header; request not authorized")
+		return h.handler(ctx, w, req, store)
+	}
+
+	ctx = ctx.WithValue(context.DebugKey, true)
+	context.SetLogLevel(ctx, log.DebugLevel)
+	ctx = context.WithValue(ctx, gournal.LevelKey(), gournal.DebugLevel)
+	ctx.Debug("enabled debug logging for request")
+
+	return h.handler(ctx, w, req, store)
+}
+
+func isDebugAuthorized(
+	ctx types.Context, req *http.Request, store types.Store) bool {
+
+	if tok := getAdminToken(req, store); tok != "" {
+		return isAdminToken(ctx, tok)
+	}
+
+	if auth.GetBearerTokenFromReq(ctx, req) == "" {
+		return false
+	}
+
+	for _, key := range []context.Key{
+		context.DebugAuthConfigKey,
+		context.AdminAuthConfigKey,
+	} {
+		config, ok := ctx.Value(key).(*types.AuthConfig)
+		if !ok {
+			continue
+		}
+		if _, err := auth.ValidateAuthTokenWithReq(
+			ctx, config, req); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -42,6 +42,15 @@ func (h *errorHandler) Handle(
 		return nil
 	}
 
+	httpErr := HTTPError(ctx, err)
+	httputils.WriteJSON(w, httpErr.Status(), httpErr)
+	return nil
+}
+
+// HTTPError logs the error returned by an API call and returns the HTTP error
+// with which the server responds to the call.
+func HTTPError(ctx types.Context, err error) goof.HTTPError {
+
 	gerr := goof.Newe(err)
 	ctx.WithError(gerr).Error("error: api call failed")
 
@@ -56,8 +65,7 @@ func (h *errorHandler) Handle(
 		}
 	}
 
-	httputils.WriteJSON(w, httpErr.Status(), httpErr)
-	return nil
+	return httpErr
 }
 
 func getStatus(err error) int {
@@ -68,11 +76,19 @@ func getStatus(err error) int {
 	case *types.ErrBadAdminToken,
 		*types.ErrSecTokInvalid:
 		return http.StatusUnauthorized
+	case *types.ErrQuotaExceeded,
+		*types.ErrNamespaceDenied,
+		*types.ErrPolicyDenied:
+		return http.StatusForbidden
 	case *types.ErrNotFound:
 		return http.StatusNotFound
 	case *types.ErrMissingInstanceID,
-		*types.ErrMissingLocalDevices:
+		*types.ErrMissingLocalDevices,
+		*types.ErrBadStorageClass,
+		*types.ErrQuotaSizeRequired:
 		return http.StatusBadRequest
+	case *types.ErrAvailabilityZoneMismatch:
+		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
 	}
@@ -42,9 +42,6 @@ func (h *instanceIDHandler) Handle(
 	req *http.Request,
 	store types.Store) error {
 
-	headers := req.Header[types.InstanceIDHeader]
-	ctx.WithField(types.InstanceIDHeader, headers).Debug("http header")
-
 	// this function has been updated to account for
 	// https://github.com/codedellemc/libstorage/pull/420 and
 	// https://github.com/codedellemc/rexray/issues/685.
@@ -64,11 +61,24 @@ func (h *instanceIDHandler) Handle(
 	d2i := map[string]*types.InstanceID{}
 	s2i := map[string]*types.InstanceID{}
 
-	for _, h := range headers {
-		val := &types.InstanceID{}
-		if err := val.UnmarshalText([]byte(h)); err != nil {
-			return err
+	// the instance IDs of a request handled in process by an embedded
+	// server are provided by the embedded request rather than the headers
+	var vals []*types.InstanceID
+	if er, ok := context.EmbeddedRequest(ctx); ok {
+		vals = er.InstanceIDs
+	} else {
+		headers := req.Header[types.InstanceIDHeader]
+		ctx.WithField(types.InstanceIDHeader, headers).Debug("http header")
+		for _, h := range headers {
+			val := &types.InstanceID{}
+			if err := val.UnmarshalText([]byte(h)); err != nil {
+				return err
+			}
+			vals = append(vals, val)
 		}
+	}
+
+	for _, val := range vals {
 		if len(val.Service) > 0 {
 			s2i[strings.ToLower(val.Service)] = val
 		} else {
@@ -35,10 +35,19 @@ func (h *localDevicesHandler) Handle(
 	req *http.Request,
 	store types.Store) error {
 
+	valMap := types.LocalDevicesMap{}
+
+	if er, ok := context.EmbeddedRequest(ctx); ok {
+		for _, val := range er.LocalDevices {
+			valMap[strings.ToLower(val.Driver)] = val
+		}
+		ctx = ctx.WithValue(context.AllLocalDevicesKey, valMap)
+		return h.handler(ctx, w, req, store)
+	}
+
 	headers := req.Header[types.LocalDevicesHeader]
 	ctx.WithField(types.LocalDevicesHeader, headers).Debug("http header")
 
-	valMap := types.LocalDevicesMap{}
 	for _, h := range headers {
 		val := &types.LocalDevices{}
 		if err := val.UnmarshalText([]byte(h)); err != nil {
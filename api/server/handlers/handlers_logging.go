@@ -72,20 +72,27 @@ func (h *loggingHandler) Handle(
 		}
 	}
 
+	// the response is only recorded when it is logged so that large
+	// responses, ex. executors, are streamed rather than buffered
+	if !h.logResponses {
+		sw := &statusWriter{ResponseWriter: w}
+		reqErr := h.handler(ctx, sw, req, store)
+		logRequest(h.logRequests, bw, sw.status(), sw.size, req, reqDump)
+		return reqErr
+	}
+
 	rec := httptest.NewRecorder()
 	reqErr := h.handler(ctx, rec, req, store)
 
-	logRequest(h.logRequests, bw, rec, req, reqDump)
+	logRequest(h.logRequests, bw, rec.Code, rec.Body.Len(), req, reqDump)
 
 	if reqErr != nil {
 		return reqErr
 	}
 
-	if h.logResponses {
-		fmt.Fprintln(bw, "")
-		logResponse(bw, rec, req)
-		fmt.Fprintln(bw, "")
-	}
+	fmt.Fprintln(bw, "")
+	logResponse(bw, rec, req)
+	fmt.Fprintln(bw, "")
 
 	for k, v := range rec.HeaderMap {
 		w.Header()[k] = v
@@ -98,15 +105,47 @@ func (h *loggingHandler) Handle(
 	return nil
 }
 
+// statusWriter records the status and size of a response that is written
+// to the underlying writer.
+type statusWriter struct {
+	http.ResponseWriter
+	code int
+	size int
+}
+
+// status returns the response's status, which is 200 if the response has
+// not been written.
+func (w *statusWriter) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.size += n
+	return n, err
+}
+
 func logRequest(
 	l bool,
 	w io.Writer,
-	rec *httptest.ResponseRecorder,
+	status, size int,
 	req *http.Request,
 	reqDump []byte) {
 
-	cll := buildCommonLogLine(
-		req, *req.URL, time.Now(), rec.Code, rec.Body.Len())
+	cll := buildCommonLogLine(req, *req.URL, time.Now(), status, size)
 	fmt.Fprintln(w, string(cll))
 
 	if !l || len(reqDump) == 0 {
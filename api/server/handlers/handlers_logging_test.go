@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// serveLogging sends a request through the logging handler to a route that
+// writes data and returns the response, the log, and whether the data was
+// written to the response before the route returned.
+func serveLogging(
+	t *testing.T,
+	logResponses bool,
+	data []byte) (*httptest.ResponseRecorder, string, bool) {
+
+	req, err := http.NewRequest(http.MethodGet, "/executors/lsx-linux", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	var (
+		log      = &bytes.Buffer{}
+		rec      = httptest.NewRecorder()
+		streamed bool
+	)
+
+	h := NewLoggingHandler(log, false, logResponses).Handler(func(
+		ctx types.Context,
+		w http.ResponseWriter,
+		req *http.Request,
+		store types.Store) error {
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		streamed = rec.Body.Len() == len(data)
+		return nil
+	})
+
+	if !assert.NoError(t, h(context.Background(), rec, req, utils.NewStore())) {
+		t.FailNow()
+	}
+	return rec, log.String(), streamed
+}
+
+func TestLoggingHandlerStreamsResponse(t *testing.T) {
+	data := bytes.Repeat([]byte{0x7f}, 1024)
+	rec, log, streamed := serveLogging(t, false, data)
+	assert.True(t, streamed)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, data, rec.Body.Bytes())
+	assert.Contains(t, log, `"GET /executors/lsx-linux HTTP/1.1" 200 1024`)
+}
+
+func TestLoggingHandlerRecordsLoggedResponse(t *testing.T) {
+	data := bytes.Repeat([]byte{0x7f}, 1024)
+	rec, log, streamed := serveLogging(t, true, data)
+	assert.False(t, streamed)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, data, rec.Body.Bytes())
+	assert.Contains(t, log, `"GET /executors/lsx-linux HTTP/1.1" 200 1024`)
+	assert.Contains(t, log, "HTTP RESPONSE (SERVER)")
+}
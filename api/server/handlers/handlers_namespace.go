@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/auth"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// namespaceHandler is an HTTP filter for scoping a request to the caller's
+// namespace.
+type namespaceHandler struct {
+	handler  types.APIFunc
+	enabled  bool
+	subjects map[string]string
+}
+
+// NewNamespaceHandler returns a new namespaceHandler. A caller's namespace
+// is the namespace to which the subject of its auth token is mapped, or the
+// value of the token's namespace claim. A request from a caller without a
+// namespace is scoped to the objects that have no namespace. Admins are not
+// scoped to a namespace unless they request one with the "namespace" query
+// parameter.
+func NewNamespaceHandler(config gofig.Config) types.Middleware {
+	h := &namespaceHandler{
+		enabled:  config.GetBool(types.ConfigServerNamespacesEnabled),
+		subjects: map[string]string{},
+	}
+	// the configuration's maps may be keyed by interface{} values
+	switch m := config.Get(types.ConfigServerNamespacesSubjects).(type) {
+	case map[string]interface{}:
+		for k, v := range m {
+			h.addSubject(k, v)
+		}
+	case map[interface{}]interface{}:
+		for k, v := range m {
+			if ks, ok := k.(string); ok {
+				h.addSubject(ks, v)
+			}
+		}
+	}
+	return h
+}
+
+func (h *namespaceHandler) addSubject(subject string, namespace interface{}) {
+	if v, ok := namespace.(string); ok {
+		h.subjects[strings.ToLower(subject)] = v
+	}
+}
+
+func (h *namespaceHandler) Name() string {
+	return "namespace-handler"
+}
+
+func (h *namespaceHandler) Handler(m types.APIFunc) types.APIFunc {
+	return (&namespaceHandler{m, h.enabled, h.subjects}).Handle
+}
+
+// Handle is the type's Handler function.
+func (h *namespaceHandler) Handle(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	if !h.enabled {
+		return h.handler(ctx, w, req, store)
+	}
+
+	var namespace string
+	tok, tokOK := context.AuthToken(ctx)
+	if tokOK {
+		namespace = tok.Namespace
+		if v, ok := h.subjects[strings.ToLower(tok.Subject)]; ok {
+			namespace = v
+		}
+	}
+
+	requested := store.IsSet("namespace")
+	if isNamespaceAdmin(ctx, req, store, tok) {
+		if !requested {
+			ctx.Debug("admin request not scoped to a namespace")
+			return h.handler(ctx, w, req, store)
+		}
+		namespace = store.GetString("namespace")
+	} else if requested &&
+		!strings.EqualFold(namespace, store.GetString("namespace")) {
+		return utils.NewNamespaceDeniedErr(store.GetString("namespace"))
+	}
+
+	ctx = ctx.WithValue(context.NamespaceKey, namespace)
+	ctx.Debug("scoped request to namespace")
+	return h.handler(ctx, w, req, store)
+}
+
+// isNamespaceAdmin returns a flag indicating whether or not the request is
+// from an admin, either because it provides the server's admin token or
+// because the subject of its auth token is in the admin allow list.
+func isNamespaceAdmin(
+	ctx types.Context,
+	req *http.Request,
+	store types.Store,
+	tok *types.AuthToken) bool {
+
+	if v := getAdminToken(req, store); v != "" && isAdminToken(ctx, v) {
+		return true
+	}
+	if tok == nil {
+		return false
+	}
+	config, ok := ctx.Value(context.AdminAuthConfigKey).(*types.AuthConfig)
+	return ok && auth.IsSubjectAllowed(config, tok.Subject)
+}
@@ -6,9 +6,12 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 
 	//log "github.com/Sirupsen/logrus"
 
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/httputils"
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils/schema"
 )
@@ -56,6 +59,11 @@ func (h *schemaValidator) Handle(
 	req *http.Request,
 	store types.Store) error {
 
+	er, embedded := context.EmbeddedRequest(ctx)
+	if embedded {
+		return h.handleEmbedded(ctx, w, req, store, er)
+	}
+
 	reqBody, err := ioutil.ReadAll(req.Body)
 	if err != nil {
 		return fmt.Errorf("validate req schema: read req error: %v", err)
@@ -82,18 +90,25 @@ func (h *schemaValidator) Handle(
 	}
 
 	// if there's not response schema then just return the result of the next
-	// handler
-	if DisableResponseValidation || h.resSchema == nil {
+	// handler. dry-run requests do not respond with the route's usual type.
+	if DisableResponseValidation || h.resSchema == nil ||
+		store.GetBool("dryRun") {
 		return h.handler(ctx, w, req, store)
 	}
 
 	// at this point we know there's going to be response validation, so
 	// we need to record the result of the next handler in order to intercept
 	// the response payload to validate it
-	rec := httptest.NewRecorder()
-
-	// invoke the next handler with a recorder
-	err = h.handler(ctx, rec, req, store)
+	rec := &objectRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+	// invoke the next handler with a recorder. the recorder only records
+	// objects if the response writer encodes them itself
+	var next http.ResponseWriter = rec.ResponseRecorder
+	ow, ok := w.(httputils.ObjectWriter)
+	if ok && ow.EncodesObjects() {
+		next = rec
+	}
+	err = h.handler(ctx, next, req, store)
 	if err != nil {
 		return err
 	}
@@ -106,13 +121,108 @@ func (h *schemaValidator) Handle(
 	}
 
 	// write the recorded result of the next handler to the resposne writer
-	w.WriteHeader(rec.Code)
 	for k, v := range rec.HeaderMap {
 		w.Header()[k] = v
 	}
+	if rec.written {
+		return ow.WriteObject(rec.Code, rec.obj)
+	}
+	w.WriteHeader(rec.Code)
 	if _, err = w.Write(resBody); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// objectRecorder records the object of a response as JSON so that it may be
+// validated before the object is written to the ObjectWriter of the request.
+type objectRecorder struct {
+	*httptest.ResponseRecorder
+	obj     interface{}
+	written bool
+}
+
+func (r *objectRecorder) WriteObject(code int, v interface{}) error {
+	r.obj = v
+	r.written = true
+	return httputils.WriteJSON(r.ResponseRecorder, code, v)
+}
+
+func (r *objectRecorder) EncodesObjects() bool {
+	return true
+}
+
+// handleEmbedded handles a request of an embedded server's API client. The
+// request's payload is validated against the request schema, and a copy of
+// the payload is the request object. The response is not validated since it
+// is not encoded.
+func (h *schemaValidator) handleEmbedded(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store,
+	er *types.EmbeddedRequest) error {
+
+	if h.reqSchema != nil {
+		reqBody := []byte{}
+		if er.Body != nil {
+			buf, err := json.Marshal(er.Body)
+			if err != nil {
+				return fmt.Errorf(
+					"validate req schema: marshal error: %v", err)
+			}
+			reqBody = buf
+		}
+		if err := schema.Validate(ctx, h.reqSchema, reqBody); err != nil {
+			return fmt.Errorf("validate req schema: validation error: %v", err)
+		}
+	}
+
+	if h.newReqObjFunc != nil {
+		reqObj := h.newReqObjFunc()
+		if er.Body != nil {
+			if err := copyReqObj(reqObj, er.Body); err != nil {
+				return err
+			}
+		}
+		ctx = ctx.WithValue("reqObj", reqObj)
+	}
+
+	return h.handler(ctx, w, req, store)
+}
+
+// copyReqObj copies the payload of an embedded request into a request object.
+// The payload's maps are copied as well since the server adds to the maps it
+// receives, such as a request's options.
+func copyReqObj(reqObj, body interface{}) error {
+	dst := reflect.ValueOf(reqObj).Elem()
+	src := reflect.ValueOf(body)
+	if src.Kind() == reflect.Ptr {
+		if src.IsNil() {
+			return nil
+		}
+		src = src.Elem()
+	}
+	if src.Type() != dst.Type() {
+		return fmt.Errorf(
+			"validate req schema: invalid payload type: %s", src.Type())
+	}
+	dst.Set(src)
+	for i := 0; i < dst.NumField(); i++ {
+		f := dst.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		m, ok := f.Interface().(map[string]interface{})
+		if !ok || m == nil {
+			continue
+		}
+		c := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			c[k] = v
+		}
+		f.Set(reflect.ValueOf(c))
+	}
+	return nil
+}
@@ -34,6 +34,15 @@ func (h *transactionHandler) Handle(
 	req *http.Request,
 	store types.Store) error {
 
+	if er, ok := context.EmbeddedRequest(ctx); ok {
+		if er.Transaction == nil {
+			ctx = context.RequireTX(ctx)
+		} else {
+			ctx = ctx.WithValue(context.TransactionKey, er.Transaction)
+		}
+		return h.handler(ctx, w, req, store)
+	}
+
 	txHeader := req.Header.Get(types.TransactionHeader)
 	ctx.WithField(types.TransactionHeader, txHeader).Debug("http header")
 
@@ -47,5 +56,11 @@ func (h *transactionHandler) Handle(
 		ctx = ctx.WithValue(context.TransactionKey, tx)
 	}
 
+	// return the transaction to the client so the response can be
+	// correlated with the server's logs
+	if tx, ok := context.Transaction(ctx); ok {
+		w.Header().Set(types.TransactionHeader, tx.String())
+	}
+
 	return h.handler(ctx, w, req, store)
 }
@@ -4,17 +4,41 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"time"
 
 	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
 
+	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/server/services"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 )
 
+// ObjectWriter is implemented by a ResponseWriter that writes the objects of
+// responses itself instead of receiving them encoded as JSON. The
+// ResponseWriter of a request that is handled in process, such as a request
+// of an embedded server's API client, records the objects as they are, while
+// the ResponseWriter of a response negotiated as YAML encodes them as YAML.
+type ObjectWriter interface {
+
+	// WriteObject writes the status code and object of the response.
+	WriteObject(code int, v interface{}) error
+
+	// EncodesObjects returns a flag indicating whether or not the objects
+	// written to the ObjectWriter are encoded. Routes that reply with binary
+	// data write the data as an object only if the objects are not encoded.
+	EncodesObjects() bool
+}
+
 // WriteJSON writes the value v to the http response stream as json with
-// standard json encoding.
+// standard json encoding. The value is written by the ResponseWriter if it
+// is an ObjectWriter.
 func WriteJSON(w http.ResponseWriter, code int, v interface{}) error {
+	if ow, ok := w.(ObjectWriter); ok {
+		return ow.WriteObject(code, v)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	buf, err := json.MarshalIndent(v, "", "  ")
@@ -67,6 +91,14 @@ func WriteTask(
 		exeTimeoutDur = time.Duration(time.Second * 60)
 	}
 	exeTimeout := time.NewTimer(exeTimeoutDur)
+	defer exeTimeout.Stop()
+
+	// the task is cancelled along with the request only if the client
+	// asked for it, otherwise the task completes regardless
+	var cancelled <-chan struct{}
+	if store.GetBool("cancel") {
+		cancelled = ctx.Done()
+	}
 
 	select {
 	case <-services.TaskWaitC(ctx, task.ID):
@@ -76,7 +108,190 @@ func WriteTask(
 		WriteJSON(w, okStatus, task.Result)
 	case <-exeTimeout.C:
 		WriteJSON(w, http.StatusRequestTimeout, task)
+	case <-cancelled:
+		services.TaskCancel(ctx, task.ID)
+		return ctx.Err()
 	}
 
 	return nil
 }
+
+// IsDryRun returns a flag indicating whether or not the request should
+// describe the operation it would perform instead of performing it.
+func IsDryRun(store types.Store) bool {
+	return store.GetBool("dryRun")
+}
+
+// WriteDryRun resolves the volume and snapshot an operation targets and
+// validates the operation without performing it. The server's policy and
+// quotas are evaluated as well, but no usage is reserved. The description of
+// the operation, including any validation errors, is written to the
+// ResponseWriter.
+func WriteDryRun(
+	ctx types.Context,
+	config gofig.Config,
+	w http.ResponseWriter,
+	store types.Store,
+	service types.StorageService,
+	volumeID, snapshotID string,
+	opts *types.ValidateOpts) error {
+
+	run := func(
+		ctx types.Context,
+		svc types.StorageService) (interface{}, error) {
+
+		return dryRun(ctx, svc, store, volumeID, snapshotID, opts), nil
+	}
+
+	return WriteTask(
+		ctx,
+		config,
+		w,
+		store,
+		service.TaskEnqueue(ctx, run, nil),
+		http.StatusOK)
+}
+
+func dryRun(
+	ctx types.Context,
+	svc types.StorageService,
+	store types.Store,
+	volumeID, snapshotID string,
+	opts *types.ValidateOpts) *types.DryRunResponse {
+
+	var (
+		driver = svc.Driver()
+		res    = &types.DryRunResponse{
+			Operation: opts.Operation,
+			Service:   svc.Name(),
+			Request:   map[string]interface{}{},
+		}
+	)
+
+	for k, v := range store.Map() {
+		// encryption keys are never disclosed in the task's result
+		if strings.EqualFold(k, "encryptionKey") {
+			continue
+		}
+		res.Request[k] = v
+	}
+
+	addErr := func(err error) {
+		res.Errors = append(res.Errors, err.Error())
+	}
+
+	if volumeID != "" {
+		attachments := types.VolAttReq
+		if _, ok := context.InstanceID(ctx); ok {
+			attachments = types.VolAttReqForInstance
+		}
+		v, err := driver.VolumeInspect(
+			ctx,
+			volumeID,
+			&types.VolumeInspectOpts{Attachments: attachments, Opts: store})
+		if err != nil {
+			addErr(err)
+		} else if v == nil || !utils.InNamespace(ctx, v.Fields) {
+			addErr(utils.NewNotFoundError(volumeID))
+		} else {
+			opts.Volume = v
+		}
+	}
+
+	if snapshotID != "" {
+		s, err := driver.SnapshotInspect(ctx, snapshotID, store)
+		if err != nil {
+			addErr(err)
+		} else if s == nil || !utils.InNamespace(ctx, s.Fields) {
+			addErr(utils.NewNotFoundError(snapshotID))
+		} else {
+			opts.Snapshot = s
+		}
+	}
+
+	if opts.CreateOpts != nil {
+		if err := utils.ApplyStorageClass(svc, opts.CreateOpts); err != nil {
+			addErr(err)
+		}
+		DefaultAvailabilityZone(ctx, svc, store, opts.CreateOpts)
+	}
+
+	// the policy and quotas are only evaluated once the operation's targets
+	// are resolved, as they are when the operation is performed
+	if len(res.Errors) == 0 {
+		if err := services.PolicyCheck(
+			ctx, svc, opts.Operation, store, opts.CreateOpts); err != nil {
+			addErr(err)
+		} else if requested := dryRunQuotaUsage(opts); requested != nil {
+			if err := services.QuotaCheck(ctx, svc, requested); err != nil {
+				addErr(err)
+			}
+		}
+	}
+
+	if v := opts.Volume; v != nil && opts.Operation == "volumeAttach" {
+		zone, err := InstanceAvailabilityZone(ctx, svc, store)
+		if err != nil {
+			addErr(err)
+		} else if zone != "" && v.AvailabilityZone != "" &&
+			zone != v.AvailabilityZone {
+			addErr(utils.NewAvailabilityZoneMismatchErr(
+				v.ID, v.AvailabilityZone, zone))
+		}
+	}
+
+	if v := opts.Volume; v != nil && !opts.Force {
+		attached := v.AttachmentState == types.VolumeAttached ||
+			v.AttachmentState == types.VolumeUnavailable
+		switch opts.Operation {
+		case "volumeAttach":
+			if attached {
+				addErr(goof.WithField(
+					"volumeID", v.ID, "volume already attached"))
+			}
+		case "volumeRemove":
+			if attached {
+				addErr(goof.WithField("volumeID", v.ID, "volume attached"))
+			}
+		}
+	}
+
+	if d, ok := driver.(types.StorageDriverWithValidate); ok {
+		if err := d.Validate(ctx, opts); err != nil {
+			addErr(err)
+		}
+	}
+
+	res.Volume = opts.Volume
+	res.Snapshot = opts.Snapshot
+
+	ctx.WithFields(map[string]interface{}{
+		"operation": res.Operation,
+		"errors":    len(res.Errors),
+	}).Info("dry-run operation")
+
+	return res
+}
+
+// dryRunQuotaUsage returns the usage requested by an operation, or nil if
+// the operation does not create a volume or snapshot.
+func dryRunQuotaUsage(opts *types.ValidateOpts) *types.QuotaUsage {
+	switch opts.Operation {
+	case "volumeCreate":
+		return utils.NewVolumeQuotaUsage(
+			opts.CreateOpts.Size, opts.CreateOpts.IOPS)
+	case "snapshotCreate":
+		size := opts.CreateOpts.Size
+		if size == nil && opts.Snapshot != nil {
+			size = &opts.Snapshot.VolumeSize
+		}
+		return utils.NewVolumeQuotaUsage(size, opts.CreateOpts.IOPS)
+	case "volumeCopy":
+		if v := opts.Volume; v != nil {
+			return utils.NewVolumeQuotaUsage(&v.Size, &v.IOPS)
+		}
+	case "volumeSnapshot", "snapshotCopy":
+		return &types.QuotaUsage{Snapshots: 1}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package httputils
+
+import (
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// InstanceAvailabilityZone returns the zone of the instance that made the
+// request. An empty string is returned if the request does not include an
+// instance ID or if the service's driver is not aware of zones.
+func InstanceAvailabilityZone(
+	ctx types.Context,
+	svc types.StorageService,
+	store types.Store) (string, error) {
+
+	if _, ok := context.InstanceID(ctx); !ok {
+		return "", nil
+	}
+	i, err := svc.Driver().InstanceInspect(ctx, store)
+	if err != nil {
+		return "", err
+	}
+	if i == nil {
+		return "", nil
+	}
+	return i.AvailabilityZone, nil
+}
+
+// DefaultAvailabilityZone sets the zone in which a volume is created to the
+// zone of the instance that made the request if the request does not specify
+// a zone. A failure to inspect the instance is logged and the driver's
+// default zone is used.
+func DefaultAvailabilityZone(
+	ctx types.Context,
+	svc types.StorageService,
+	store types.Store,
+	opts *types.VolumeCreateOpts) {
+
+	if opts.AvailabilityZone != nil && *opts.AvailabilityZone != "" {
+		return
+	}
+
+	zone, err := InstanceAvailabilityZone(ctx, svc, store)
+	if err != nil {
+		ctx.WithError(err).Warn(
+			"error inspecting instance for default availability zone")
+		return
+	}
+	if zone == "" {
+		return
+	}
+
+	ctx.WithField("availabilityZone", zone).Debug(
+		"defaulting to instance's availability zone")
+	opts.AvailabilityZone = &zone
+}
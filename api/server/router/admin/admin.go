@@ -0,0 +1,101 @@
+package admin
+
+import (
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/server/handlers"
+	"github.com/codedellemc/libstorage/api/server/httputils"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func init() {
+	registry.RegisterRouter(&router{})
+}
+
+type router struct {
+	config gofig.Config
+	routes []types.Route
+}
+
+func (r *router) Name() string {
+	return "admin-router"
+}
+
+func (r *router) Init(config gofig.Config) {
+	r.config = config
+	r.initRoutes()
+}
+
+// Routes returns the available routes.
+func (r *router) Routes() []types.Route {
+	return r.routes
+}
+
+func (r *router) initRoutes() {
+	r.routes = []types.Route{
+
+		// GET
+		httputils.NewGetRoute(
+			"admin",
+			"/admin",
+			r.adminInspect,
+			handlers.NewAdminHandler()),
+
+		// GET
+		httputils.NewGetRoute(
+			"adminLogLevel",
+			"/admin/loglevel",
+			r.logLevelInspect,
+			handlers.NewAdminHandler()),
+
+		// POST
+		httputils.NewPostRoute(
+			"adminLogLevelSet",
+			"/admin/loglevel",
+			r.logLevelSet,
+			handlers.NewAdminHandler()),
+
+		// GET
+		httputils.NewGetRoute(
+			"adminCaches",
+			"/admin/caches",
+			r.caches,
+			handlers.NewAdminHandler()),
+
+		// POST
+		httputils.NewPostRoute(
+			"adminCachesFlush",
+			"/admin/caches",
+			r.cachesFlush,
+			handlers.NewAdminHandler()),
+
+		// DELETE
+		httputils.NewDeleteRoute(
+			"adminTasksPurge",
+			"/admin/tasks",
+			r.tasksPurge,
+			handlers.NewAdminHandler()),
+
+		// GET
+		httputils.NewGetRoute(
+			"adminProfile",
+			"/admin/profiles/{profile}",
+			r.profileInspect,
+			handlers.NewAdminHandler()),
+
+		// GET
+		httputils.NewGetRoute(
+			"adminServices",
+			"/admin/services",
+			r.services,
+			handlers.NewAdminHandler()),
+
+		// GET
+		httputils.NewGetRoute(
+			"adminServiceInspect",
+			"/admin/services/{service}",
+			r.serviceInspect,
+			handlers.NewAdminHandler()),
+	}
+}
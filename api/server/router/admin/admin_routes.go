@@ -0,0 +1,231 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/server/httputils"
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+func (r *router) adminInspect(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	proto := "http"
+	if req.TLS != nil {
+		proto = "https"
+	}
+	rootURL := fmt.Sprintf("%s://%s", proto, req.Host)
+
+	reply := []string{
+		fmt.Sprintf("%s/admin/caches", rootURL),
+		fmt.Sprintf("%s/admin/loglevel", rootURL),
+		fmt.Sprintf("%s/admin/profiles/goroutine", rootURL),
+		fmt.Sprintf("%s/admin/profiles/heap", rootURL),
+		fmt.Sprintf("%s/admin/services", rootURL),
+		fmt.Sprintf("%s/admin/tasks", rootURL),
+	}
+
+	httputils.WriteJSON(w, http.StatusOK, reply)
+	return nil
+}
+
+func getLogLevels(ctx types.Context) *types.AdminLogLevels {
+	reply := &types.AdminLogLevels{Services: map[string]string{}}
+	if lvl, ok := context.GetLogLevel(services.ServerContext(ctx)); ok {
+		reply.Global = lvl.String()
+	}
+	for svc := range services.StorageServices(ctx) {
+		state := services.StorageServiceState(ctx, svc.Name())
+		if state != nil && state.LogLevel != "" {
+			reply.Services[state.Name] = state.LogLevel
+		}
+	}
+	return reply
+}
+
+func (r *router) logLevelInspect(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	httputils.WriteJSON(w, http.StatusOK, getLogLevels(ctx))
+	return nil
+}
+
+func (r *router) logLevelSet(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	var (
+		szLevel = store.GetString("level")
+		service = store.GetString("service")
+	)
+
+	// an empty or default level for a service removes its override
+	if service != "" && (szLevel == "" || strings.EqualFold(
+		szLevel, "default")) {
+		if err := services.SetStorageServiceLogLevel(
+			ctx, service, nil); err != nil {
+			return err
+		}
+		httputils.WriteJSON(w, http.StatusOK, getLogLevels(ctx))
+		return nil
+	}
+
+	lvl, err := log.ParseLevel(szLevel)
+	if err != nil {
+		return goof.WithFieldE("level", szLevel, "invalid log level", err)
+	}
+
+	if service != "" {
+		if err := services.SetStorageServiceLogLevel(
+			ctx, service, &lvl); err != nil {
+			return err
+		}
+		httputils.WriteJSON(w, http.StatusOK, getLogLevels(ctx))
+		return nil
+	}
+
+	// the server's logger is shared by the contexts derived from the
+	// server's context, so updating its level updates the level globally.
+	// the request's context may have its own logger, ex. a debug request.
+	context.SetSharedLogLevel(services.ServerContext(ctx), lvl)
+	log.SetLevel(lvl)
+	ctx.WithField("logLevel", lvl.String()).Info("set global log level")
+
+	httputils.WriteJSON(w, http.StatusOK, getLogLevels(ctx))
+	return nil
+}
+
+func (r *router) caches(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	reply := []string{}
+	for c := range registry.Caches() {
+		reply = append(reply, c.Name())
+	}
+
+	httputils.WriteJSON(w, http.StatusOK, reply)
+	return nil
+}
+
+func (r *router) cachesFlush(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	name := store.GetString("name")
+
+	reply := map[string]int{}
+	for c := range registry.Caches() {
+		if name != "" && !strings.EqualFold(name, c.Name()) {
+			continue
+		}
+		reply[c.Name()] += c.Flush(ctx)
+		ctx.WithField("cache", c.Name()).Info("flushed cache")
+	}
+
+	if name != "" && len(reply) == 0 {
+		return utils.NewNotFoundError(name)
+	}
+
+	httputils.WriteJSON(w, http.StatusOK, reply)
+	return nil
+}
+
+func (r *router) tasksPurge(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	httputils.WriteJSON(w, http.StatusOK, map[string]int{
+		"purged": services.TaskPurge(ctx),
+	})
+	return nil
+}
+
+func (r *router) profileInspect(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	name := store.GetString("profile")
+	p := pprof.Lookup(name)
+	if p == nil {
+		return utils.NewNotFoundError(name)
+	}
+
+	if name == "heap" && store.GetBool("gc") {
+		runtime.GC()
+	}
+
+	debug := store.GetInt("debug")
+	if debug > 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set(
+			"Content-Disposition",
+			fmt.Sprintf(`attachment; filename="%s.pprof"`, name))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	return p.WriteTo(w, debug)
+}
+
+func (r *router) services(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	reply := map[string]*types.ServiceState{}
+	for svc := range services.StorageServices(ctx) {
+		if state := services.StorageServiceState(
+			ctx, svc.Name()); state != nil {
+			reply[state.Name] = state
+		}
+	}
+
+	httputils.WriteJSON(w, http.StatusOK, reply)
+	return nil
+}
+
+func (r *router) serviceInspect(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	name := store.GetString("service")
+	state := services.StorageServiceState(ctx, name)
+	if state == nil {
+		return utils.NewNotFoundError(name)
+	}
+
+	httputils.WriteJSON(w, http.StatusOK, state)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	gofig "github.com/akutz/gofig/types"
 
 	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/server/executors"
 	"github.com/codedellemc/libstorage/api/server/httputils"
 	"github.com/codedellemc/libstorage/api/types"
 )
@@ -13,7 +14,8 @@ func init() {
 }
 
 type router struct {
-	routes []types.Route
+	routes           []types.Route
+	executorRegistry *executors.Registry
 }
 
 func (r *router) Name() string {
@@ -21,6 +23,8 @@ func (r *router) Name() string {
 }
 
 func (r *router) Init(config gofig.Config) {
+	r.executorRegistry = executors.NewRegistry(
+		config.GetString(types.ConfigServerExecutorsPath))
 	r.initRoutes()
 }
 
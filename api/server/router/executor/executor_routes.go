@@ -21,7 +21,7 @@ func (r *router) executors(
 	store types.Store) error {
 
 	var reply types.ExecutorsMap = map[string]*types.ExecutorInfo{}
-	for ei := range executors.ExecutorInfos() {
+	for ei := range r.executorRegistry.ExecutorInfos() {
 		reply[ei.Name] = &ei.ExecutorInfo
 	}
 
@@ -34,7 +34,8 @@ func (r *router) executorInspect(
 	req *http.Request,
 	store types.Store) error {
 
-	ei, err := executors.ExecutorInfoInspect(store.GetString("executor"), true)
+	ei, err := r.executorRegistry.ExecutorInfoInspect(
+		store.GetString("executor"), true)
 	if err != nil {
 		return err
 	}
@@ -48,7 +49,8 @@ func (r *router) executorHead(
 	req *http.Request,
 	store types.Store) error {
 
-	ei, err := executors.ExecutorInfoInspect(store.GetString("executor"), false)
+	ei, err := r.executorRegistry.ExecutorInfoInspect(
+		store.GetString("executor"), false)
 	if err != nil {
 		return err
 	}
@@ -60,6 +62,10 @@ const lastModTimeFmt = "Mon, 02 Jan 2006 15:04:05 MST"
 
 func writeFile(w http.ResponseWriter, ei *executors.ExecutorInfoEx) error {
 
+	if ow, ok := w.(httputils.ObjectWriter); ok && !ow.EncodesObjects() {
+		return ow.WriteObject(http.StatusOK, ei)
+	}
+
 	w.Header().Add("Accept-Ranges", "bytes")
 	w.Header().Add("Content-Length", fmt.Sprintf("%d", ei.Size))
 	w.Header().Add("Content-Type", "application/octet-stream")
@@ -70,6 +76,14 @@ func writeFile(w http.ResponseWriter, ei *executors.ExecutorInfoEx) error {
 	b64str := base64.StdEncoding.EncodeToString(hexBuf)
 	w.Header().Add("Content-MD5", b64str)
 
+	hexBuf, _ = hex.DecodeString(ei.SHA256Checksum)
+	b64str = base64.StdEncoding.EncodeToString(hexBuf)
+	w.Header().Add(types.DigestHeader, "SHA-256="+b64str)
+
+	if ei.Signature != "" {
+		w.Header().Add(types.ExecutorSignatureHeader, ei.Signature)
+	}
+
 	if len(ei.Data) > 0 {
 		if _, err := io.Copy(w, bytes.NewReader(ei.Data)); err != nil {
 			return err
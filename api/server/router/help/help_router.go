@@ -10,6 +10,7 @@ import (
 	"github.com/codedellemc/libstorage/api/server/httputils"
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/api/utils/secrets"
 )
 
 func (r *router) helpInspect(
@@ -60,7 +61,8 @@ func (r *router) configInspect(
 		return utils.NewBadAdminTokenError(actualToken)
 	}
 
-	httputils.WriteJSON(w, http.StatusOK, r.config.AllSettings())
+	httputils.WriteJSON(
+		w, http.StatusOK, secrets.MaskSettings(r.config.AllSettings()))
 	return nil
 }
 
@@ -80,6 +82,6 @@ func (r *router) envInspect(
 		return utils.NewBadAdminTokenError(actualToken)
 	}
 
-	httputils.WriteJSON(w, http.StatusOK, os.Environ())
+	httputils.WriteJSON(w, http.StatusOK, secrets.Mask(os.Environ()))
 	return nil
 }
@@ -0,0 +1,58 @@
+package quota
+
+import (
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/server/handlers"
+	"github.com/codedellemc/libstorage/api/server/httputils"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils/schema"
+)
+
+func init() {
+	registry.RegisterRouter(&router{})
+}
+
+type router struct {
+	config gofig.Config
+	routes []types.Route
+}
+
+func (r *router) Name() string {
+	return "quota-router"
+}
+
+func (r *router) Init(config gofig.Config) {
+	r.config = config
+	r.initRoutes()
+}
+
+// Routes returns the available routes.
+func (r *router) Routes() []types.Route {
+	return r.routes
+}
+
+func (r *router) initRoutes() {
+
+	r.routes = []types.Route{
+
+		// GET
+
+		// get the usage and limits of all subjects and services
+		httputils.NewGetRoute(
+			"quotas",
+			"/quotas",
+			r.quotas,
+			handlers.NewAdminHandler(),
+			handlers.NewSchemaValidator(nil, schema.QuotaReportSchema, nil)),
+
+		// get the usage and limits of a specific subject
+		httputils.NewGetRoute(
+			"quotaInspect",
+			"/quotas/{subject}",
+			r.quotaInspect,
+			handlers.NewAuthAllSvcsHandler(),
+			handlers.NewSchemaValidator(nil, schema.SubjectQuotaSchema, nil)),
+	}
+}
@@ -0,0 +1,57 @@
+package quota
+
+import (
+	"net/http"
+
+	"github.com/codedellemc/libstorage/api/server/handlers"
+	"github.com/codedellemc/libstorage/api/server/httputils"
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils/schema"
+)
+
+func (r *router) quotas(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	run := func(ctx types.Context) (interface{}, error) {
+		return services.QuotaReport(ctx)
+	}
+
+	return httputils.WriteTask(
+		ctx,
+		r.config,
+		w,
+		store,
+		services.TaskEnqueue(ctx, run, schema.QuotaReportSchema),
+		http.StatusOK)
+}
+
+func (r *router) quotaInspect(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	// only admins may inspect the quotas of other subjects
+	subject := store.GetString("subject")
+	if subject != services.RequestUser(ctx) &&
+		!handlers.IsAdmin(ctx, req, store) {
+		ctx.WithField("subject", subject).Error("quota inspection denied")
+		return &types.ErrSecTokInvalid{Denied: true}
+	}
+
+	run := func(ctx types.Context) (interface{}, error) {
+		return services.SubjectQuota(ctx, subject)
+	}
+
+	return httputils.WriteTask(
+		ctx,
+		r.config,
+		w,
+		store,
+		services.TaskEnqueue(ctx, run, schema.SubjectQuotaSchema),
+		http.StatusOK)
+}
@@ -27,6 +27,7 @@ func (r *router) root(
 
 	reply := []string{
 		fmt.Sprintf("%s/executors", rootURL),
+		fmt.Sprintf("%s/quotas", rootURL),
 		fmt.Sprintf("%s/services", rootURL),
 		fmt.Sprintf("%s/snapshots", rootURL),
 		fmt.Sprintf("%s/tasks", rootURL),
@@ -50,5 +50,14 @@ func (r *router) initRoutes() {
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
 			handlers.NewSchemaValidator(nil, schema.ServiceInfoSchema, nil)),
+
+		httputils.NewGetRoute(
+			"serviceClasses",
+			"/services/{service}/classes",
+			r.serviceClasses,
+			handlers.NewServiceValidator(),
+			handlers.NewAuthSvcHandler(),
+			handlers.NewSchemaValidator(
+				nil, schema.StorageClassMapSchema, nil)),
 	}
 }
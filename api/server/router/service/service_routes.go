@@ -45,6 +45,17 @@ func (r *router) serviceInspect(
 	return nil
 }
 
+func (r *router) serviceClasses(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	service := context.MustService(ctx)
+	httputils.WriteJSON(w, http.StatusOK, service.StorageClasses())
+	return nil
+}
+
 func toServiceInfo(
 	ctx types.Context,
 	service types.StorageService,
@@ -75,6 +86,13 @@ func toServiceInfo(
 		return nil, err
 	}
 
+	var zones []string
+	if store.GetBool("zones") {
+		if zones, err = availabilityZones(ctx, d); err != nil {
+			return nil, err
+		}
+	}
+
 	return &types.ServiceInfo{
 		Name:     service.Name(),
 		Instance: instance,
@@ -83,5 +101,25 @@ func toServiceInfo(
 			Type:       st,
 			NextDevice: nd,
 		},
+		AvailabilityZones: zones,
 	}, nil
 }
+
+// availabilityZones returns the zones in which a service can provision
+// volumes, or nil if the service's driver is not aware of zones.
+func availabilityZones(
+	ctx types.Context,
+	d types.StorageDriver) ([]string, error) {
+
+	sd, ok := d.(types.StorageDriverWithAvailabilityZones)
+	if !ok {
+		return nil, nil
+	}
+
+	// listing the zones may require a session with the storage platform
+	ctx, err := context.WithStorageSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sd.AvailabilityZones(ctx)
+}
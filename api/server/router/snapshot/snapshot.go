@@ -44,6 +44,7 @@ func (r *router) initRoutes() {
 			"/snapshots",
 			r.snapshots,
 			handlers.NewAuthAllSvcsHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewSchemaValidator(
 				nil, schema.ServiceSnapshotMapSchema, nil),
 		),
@@ -55,6 +56,7 @@ func (r *router) initRoutes() {
 			r.snapshotsForService,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 			handlers.NewSchemaValidator(
 				nil, schema.SnapshotMapSchema, nil),
@@ -67,6 +69,7 @@ func (r *router) initRoutes() {
 			r.snapshotInspect,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 			handlers.NewSchemaValidator(nil, schema.SnapshotSchema, nil),
 		),
@@ -80,6 +83,7 @@ func (r *router) initRoutes() {
 			r.volumeCreate,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 			handlers.NewSchemaValidator(
 				schema.VolumeCreateRequestSchema,
@@ -95,6 +99,7 @@ func (r *router) initRoutes() {
 			r.snapshotCopy,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 			handlers.NewSchemaValidator(
 				schema.SnapshotCopyRequestSchema,
@@ -112,6 +117,7 @@ func (r *router) initRoutes() {
 			r.snapshotRemove,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 		),
 	}
@@ -44,7 +44,9 @@ func (r *router) snapshots(
 
 			objMap := map[string]*types.Snapshot{}
 			for _, obj := range objs {
-				objMap[obj.ID] = obj
+				if utils.InNamespace(ctx, obj.Fields) {
+					objMap[obj.ID] = obj
+				}
 			}
 			return objMap, nil
 		}
@@ -103,7 +105,9 @@ func (r *router) snapshotsForService(
 		}
 
 		for _, obj := range objs {
-			reply[obj.ID] = obj
+			if utils.InNamespace(ctx, obj.Fields) {
+				reply[obj.ID] = obj
+			}
 		}
 		return reply, nil
 	}
@@ -129,10 +133,15 @@ func (r *router) snapshotInspect(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
-		return svc.Driver().SnapshotInspect(
-			ctx,
-			store.GetString("snapshotID"),
-			store)
+		snapshotID := store.GetString("snapshotID")
+		s, err := svc.Driver().SnapshotInspect(ctx, snapshotID, store)
+		if err != nil {
+			return nil, err
+		}
+		if s == nil || !utils.InNamespace(ctx, s.Fields) {
+			return nil, utils.NewNotFoundError(snapshotID)
+		}
+		return s, nil
 	}
 
 	return httputils.WriteTask(
@@ -152,10 +161,29 @@ func (r *router) snapshotRemove(
 
 	service := context.MustService(ctx)
 
+	if httputils.IsDryRun(store) {
+		return httputils.WriteDryRun(
+			ctx, r.config, w, store, service,
+			"", store.GetString("snapshotID"),
+			&types.ValidateOpts{
+				Operation: "snapshotRemove",
+				Opts:      store,
+			})
+	}
+
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
+		if err := utils.CheckSnapshotNamespace(
+			ctx, svc, store.GetString("snapshotID"), store); err != nil {
+			return nil, err
+		}
+		if err := services.PolicyCheck(
+			ctx, svc, "snapshotRemove", store, nil); err != nil {
+			return nil, err
+		}
+
 		return nil, svc.Driver().SnapshotRemove(
 			ctx,
 			store.GetString("snapshotID"),
@@ -179,25 +207,63 @@ func (r *router) volumeCreate(
 
 	service := context.MustService(ctx)
 
+	if httputils.IsDryRun(store) {
+		return httputils.WriteDryRun(
+			ctx, r.config, w, store, service,
+			"", store.GetString("snapshotID"),
+			&types.ValidateOpts{
+				Operation:  "snapshotCreate",
+				Name:       store.GetString("name"),
+				CreateOpts: newVolumeCreateOpts(store),
+				Opts:       store,
+			})
+	}
+
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
+		if err := utils.CheckSnapshotNamespace(
+			ctx, svc, store.GetString("snapshotID"), store); err != nil {
+			return nil, err
+		}
+
+		opts := newVolumeCreateOpts(store)
+		if err := utils.ApplyStorageClass(svc, opts); err != nil {
+			return nil, err
+		}
+		httputils.DefaultAvailabilityZone(ctx, svc, store, opts)
+
+		if err := services.PolicyCheck(
+			ctx, svc, "snapshotCreate", store, opts); err != nil {
+			return nil, err
+		}
+
+		requested, err := createQuotaUsage(ctx, svc, store, opts)
+		if err != nil {
+			return nil, err
+		}
+		release, err := services.QuotaReserve(ctx, svc, requested)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		utils.SetOwner(ctx, store)
+		utils.SetNamespace(ctx, store)
+
 		v, err := svc.Driver().VolumeCreateFromSnapshot(
 			ctx,
 			store.GetString("snapshotID"),
 			store.GetString("name"),
-			&types.VolumeCreateOpts{
-				AvailabilityZone: store.GetStringPtr("availabilityZone"),
-				IOPS:             store.GetInt64Ptr("iops"),
-				Size:             store.GetInt64Ptr("size"),
-				Type:             store.GetStringPtr("type"),
-				Opts:             store,
-			})
+			opts)
+		volume.VolumeChanged(svc, v)
 
 		if err != nil {
 			return nil, err
 		}
+		utils.RecordStorageClass(v, opts)
+		v.Fields = utils.RecordOwner(ctx, v.Fields)
+		v.Fields = utils.RecordNamespace(ctx, v.Fields)
 
 		if volume.OnVolume != nil {
 			ok, err := volume.OnVolume(ctx, req, store, v)
@@ -229,16 +295,53 @@ func (r *router) snapshotCopy(
 
 	service := context.MustService(ctx)
 
+	if httputils.IsDryRun(store) {
+		return httputils.WriteDryRun(
+			ctx, r.config, w, store, service,
+			"", store.GetString("snapshotID"),
+			&types.ValidateOpts{
+				Operation: "snapshotCopy",
+				Name:      store.GetString("snapshotName"),
+				Opts:      store,
+			})
+	}
+
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
-		return svc.Driver().SnapshotCopy(
+		if err := utils.CheckSnapshotNamespace(
+			ctx, svc, store.GetString("snapshotID"), store); err != nil {
+			return nil, err
+		}
+		if err := services.PolicyCheck(
+			ctx, svc, "snapshotCopy", store, nil); err != nil {
+			return nil, err
+		}
+
+		release, err := services.QuotaReserve(
+			ctx, svc, &types.QuotaUsage{Snapshots: 1})
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		utils.SetOwner(ctx, store)
+		utils.SetNamespace(ctx, store)
+
+		snap, err := svc.Driver().SnapshotCopy(
 			ctx,
 			store.GetString("snapshotID"),
 			store.GetString("snapshotName"),
 			store.GetString("destinationID"),
 			store)
+		if err != nil {
+			return nil, err
+		}
+		if snap != nil {
+			snap.Fields = utils.RecordOwner(ctx, snap.Fields)
+			snap.Fields = utils.RecordNamespace(ctx, snap.Fields)
+		}
+		return snap, nil
 	}
 
 	return httputils.WriteTask(
@@ -249,3 +352,34 @@ func (r *router) snapshotCopy(
 		service.TaskEnqueue(ctx, run, schema.SnapshotSchema),
 		http.StatusCreated)
 }
+
+// createQuotaUsage returns the usage requested by creating a volume from a
+// snapshot. If the request does not specify a size then the snapshot is
+// inspected for the size of its volume, but only if quotas are configured.
+func createQuotaUsage(
+	ctx types.Context,
+	svc types.StorageService,
+	store types.Store,
+	opts *types.VolumeCreateOpts) (*types.QuotaUsage, error) {
+
+	if opts.Size != nil || !services.QuotasEnabled(ctx) {
+		return utils.NewVolumeQuotaUsage(opts.Size, opts.IOPS), nil
+	}
+	s, err := svc.Driver().SnapshotInspect(
+		ctx, store.GetString("snapshotID"), store)
+	if err != nil {
+		return nil, err
+	}
+	return utils.NewVolumeQuotaUsage(&s.VolumeSize, opts.IOPS), nil
+}
+
+func newVolumeCreateOpts(store types.Store) *types.VolumeCreateOpts {
+	return &types.VolumeCreateOpts{
+		AvailabilityZone: store.GetStringPtr("availabilityZone"),
+		IOPS:             store.GetInt64Ptr("iops"),
+		Size:             store.GetInt64Ptr("size"),
+		Type:             store.GetStringPtr("type"),
+		Class:            store.GetStringPtr("class"),
+		Opts:             store,
+	}
+}
@@ -3,7 +3,10 @@ package tasks
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/handlers"
 	"github.com/codedellemc/libstorage/api/server/httputils"
 	"github.com/codedellemc/libstorage/api/server/services"
 	"github.com/codedellemc/libstorage/api/types"
@@ -16,9 +19,12 @@ func (r *router) tasks(
 	req *http.Request,
 	store types.Store) error {
 
+	admin := handlers.IsAdmin(ctx, req, store)
 	tasks := map[string]*types.Task{}
 	for t := range services.Tasks(ctx) {
-		tasks[fmt.Sprintf("%d", t.ID)] = t
+		if taskVisible(ctx, t, admin) {
+			tasks[fmt.Sprintf("%d", t.ID)] = t
+		}
 	}
 	httputils.WriteJSON(w, http.StatusOK, tasks)
 	return nil
@@ -31,6 +37,91 @@ func (r *router) taskInspect(
 	store types.Store) error {
 
 	task := services.TaskInspect(ctx, store.GetInt("taskID"))
+	if task == nil ||
+		!taskVisible(ctx, task, handlers.IsAdmin(ctx, req, store)) {
+		return utils.NewNotFoundError(store.GetString("taskID"))
+	}
+
+	httputils.WriteJSON(w, http.StatusOK, task)
+	return nil
+}
+
+// taskVisible returns a flag indicating whether or not a task is visible to
+// a request. A task is not visible if it operates on a service the endpoint
+// does not expose, or if it was created by a request that was not scoped to
+// the request's namespace. Only admins see the tasks of other users.
+func taskVisible(ctx types.Context, task *types.Task, admin bool) bool {
+	if name := services.TaskService(ctx, task.ID); name != "" &&
+		services.GetStorageService(ctx, name) == nil {
+		return false
+	}
+	if ns, ok := context.Namespace(ctx); ok {
+		taskNS, ok := services.TaskNamespace(ctx, task.ID)
+		if !ok || !strings.EqualFold(taskNS, ns) {
+			return false
+		}
+	}
+	return admin || task.User == services.RequestUser(ctx)
+}
+
+func (r *router) taskCancel(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	taskID := store.GetInt("taskID")
+	task := services.TaskInspect(ctx, taskID)
+	if task == nil {
+		return utils.NewNotFoundError(store.GetString("taskID"))
+	}
+
+	// a task that operates on a service the endpoint does not expose is not
+	// found, just as the service itself is not
+	var svc types.StorageService
+	if name := services.TaskService(ctx, taskID); name != "" {
+		if svc = services.GetStorageService(ctx, name); svc == nil {
+			return utils.NewNotFoundError(store.GetString("taskID"))
+		}
+	}
+
+	if handlers.IsAdmin(ctx, req, store) {
+		return cancelTask(ctx, w, req, store)
+	}
+
+	// only the user that created the task may cancel it otherwise. the
+	// security token of a service's task is validated with the service's
+	// auth config, as it is when the task is created
+	cancel := func(
+		ctx types.Context,
+		w http.ResponseWriter,
+		req *http.Request,
+		store types.Store) error {
+
+		if task.User != services.RequestUser(ctx) {
+			ctx.WithFields(map[string]interface{}{
+				"taskID":   taskID,
+				"taskUser": task.User,
+			}).Error("task cancellation denied")
+			return &types.ErrSecTokInvalid{Denied: true}
+		}
+		return cancelTask(ctx, w, req, store)
+	}
+
+	if svc == nil {
+		return cancel(ctx, w, req, store)
+	}
+	return handlers.NewAuthSvcHandler().Handler(cancel)(
+		context.WithStorageService(ctx, svc), w, req, store)
+}
+
+func cancelTask(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	task := services.TaskCancel(ctx, store.GetInt("taskID"))
 	if task == nil {
 		return utils.NewNotFoundError(store.GetString("taskID"))
 	}
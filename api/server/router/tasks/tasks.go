@@ -4,6 +4,7 @@ import (
 	gofig "github.com/akutz/gofig/types"
 
 	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/server/handlers"
 	"github.com/codedellemc/libstorage/api/server/httputils"
 	"github.com/codedellemc/libstorage/api/types"
 )
@@ -13,6 +14,7 @@ func init() {
 }
 
 type router struct {
+	config gofig.Config
 	routes []types.Route
 }
 
@@ -21,6 +23,7 @@ func (r *router) Name() string {
 }
 
 func (r *router) Init(config gofig.Config) {
+	r.config = config
 	r.initRoutes()
 }
 
@@ -37,12 +40,22 @@ func (r *router) initRoutes() {
 		httputils.NewGetRoute(
 			"tasks",
 			"/tasks",
-			r.tasks),
+			r.tasks,
+			handlers.NewAuthAllSvcsHandler(),
+			handlers.NewNamespaceHandler(r.config)),
 
 		// GET
 		httputils.NewGetRoute(
 			"taskInspect",
 			"/tasks/{taskID}",
-			r.taskInspect),
+			r.taskInspect,
+			handlers.NewAuthAllSvcsHandler(),
+			handlers.NewNamespaceHandler(r.config)),
+
+		// DELETE
+		httputils.NewDeleteRoute(
+			"taskCancel",
+			"/tasks/{taskID}",
+			r.taskCancel),
 	}
 }
@@ -57,9 +57,21 @@ func (r *router) initRoutes() {
 			"/volumes",
 			r.volumes,
 			handlers.NewAuthAllSvcsHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewSchemaValidator(nil, schema.ServiceVolumeMapSchema, nil),
 		),
 
+		// watch the volumes from a specific service for changes
+		httputils.NewGetRoute(
+			"volumesWatch",
+			"/volumes/{service}",
+			r.volumesWatch,
+			handlers.NewServiceValidator(),
+			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
+			handlers.NewStorageSessionHandler(),
+		).Queries("watch", "{watch:(?:1|t|T|true|TRUE|True)}"),
+
 		// get all volumes from a specific service
 		httputils.NewGetRoute(
 			"volumesForService",
@@ -67,6 +79,7 @@ func (r *router) initRoutes() {
 			r.volumesForService,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 			handlers.NewSchemaValidator(nil, schema.VolumeMapSchema, nil),
 		),
@@ -78,6 +91,7 @@ func (r *router) initRoutes() {
 			r.volumeInspect,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 			handlers.NewSchemaValidator(nil, schema.VolumeSchema, nil),
 		),
@@ -91,6 +105,7 @@ func (r *router) initRoutes() {
 			r.volumeDetachAllForService,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 			handlers.NewSchemaValidator(
 				schema.VolumeDetachRequestSchema,
@@ -106,6 +121,7 @@ func (r *router) initRoutes() {
 			r.volumeCreate,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 			handlers.NewSchemaValidator(
 				schema.VolumeCreateRequestSchema,
@@ -121,6 +137,7 @@ func (r *router) initRoutes() {
 			r.volumeCopy,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 			handlers.NewSchemaValidator(
 				schema.VolumeCopyRequestSchema,
@@ -136,6 +153,7 @@ func (r *router) initRoutes() {
 			r.volumeSnapshot,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 			handlers.NewSchemaValidator(
 				schema.VolumeSnapshotRequestSchema,
@@ -151,6 +169,7 @@ func (r *router) initRoutes() {
 			r.volumeAttach,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 			handlers.NewSchemaValidator(
 				schema.VolumeAttachRequestSchema,
@@ -165,6 +184,7 @@ func (r *router) initRoutes() {
 			"/volumes",
 			r.volumeDetachAll,
 			handlers.NewAuthAllSvcsHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewSchemaValidator(
 				schema.VolumeDetachRequestSchema,
 				schema.ServiceVolumeMapSchema,
@@ -179,6 +199,7 @@ func (r *router) initRoutes() {
 			r.volumeDetach,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 			handlers.NewSchemaValidator(
 				schema.VolumeDetachRequestSchema,
@@ -194,6 +215,7 @@ func (r *router) initRoutes() {
 			r.volumeRemove,
 			handlers.NewServiceValidator(),
 			handlers.NewAuthSvcHandler(),
+			handlers.NewNamespaceHandler(r.config),
 			handlers.NewStorageSessionHandler(),
 		),
 	}
@@ -0,0 +1,290 @@
+package volume
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gofig "github.com/akutz/gofig/types"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+var (
+	volCaches    = map[types.StorageService]*volumeCache{}
+	volCachesRWL = &sync.RWMutex{}
+)
+
+func init() {
+	registry.RegisterCache(&volumeCacheFlusher{})
+}
+
+// volumeCache caches the results of a service's Volumes and VolumeInspect
+// driver calls.
+type volumeCache struct {
+	sync.Mutex
+
+	store types.Store
+
+	// generation is incremented every time the cache is invalidated so that
+	// driver calls that began before the invalidation do not populate the
+	// cache with stale results.
+	generation int64
+
+	// calls are the driver calls in progress, keyed by cache key.
+	calls map[string]*volumeCacheCall
+}
+
+type volumeCacheCall struct {
+	done   chan struct{}
+	result interface{}
+	err    error
+}
+
+// volumeCacheEntry wraps a cached result so that an empty result is not
+// mistaken for a cache miss.
+type volumeCacheEntry struct {
+	result interface{}
+}
+
+type hasConfig interface {
+	Config() gofig.Config
+}
+
+// getVolumeCache returns the volume cache for a service, or nil if the
+// service's volumes are not cached.
+func getVolumeCache(service types.StorageService) *volumeCache {
+	volCachesRWL.RLock()
+	c, ok := volCaches[service]
+	volCachesRWL.RUnlock()
+	if ok {
+		return c
+	}
+
+	volCachesRWL.Lock()
+	defer volCachesRWL.Unlock()
+	if c, ok := volCaches[service]; ok {
+		return c
+	}
+
+	// the service's config is used so the TTL may be defined per service
+	sc, ok := service.(hasConfig)
+	if !ok {
+		volCaches[service] = nil
+		return nil
+	}
+	ttl, err := time.ParseDuration(
+		sc.Config().GetString(types.ConfigServerVolumesCacheTTL))
+	if err != nil || ttl <= 0 {
+		volCaches[service] = nil
+		return nil
+	}
+
+	c = &volumeCache{
+		store: utils.NewTTLStore(ttl, true),
+		calls: map[string]*volumeCacheCall{},
+	}
+	volCaches[service] = c
+	return c
+}
+
+// invalidateVolumeCache removes all of a service's cached results.
+func invalidateVolumeCache(service types.StorageService) {
+	volCachesRWL.RLock()
+	c := volCaches[service]
+	volCachesRWL.RUnlock()
+	if c != nil {
+		c.flush()
+	}
+}
+
+func (c *volumeCache) flush() int {
+	c.Lock()
+	defer c.Unlock()
+	c.generation++
+	c.calls = map[string]*volumeCacheCall{}
+	n := 0
+	for _, k := range c.store.Keys() {
+		c.store.Delete(k)
+		n++
+	}
+	return n
+}
+
+// get returns the cached result for the provided key. On a cache miss the
+// provided function is invoked, and concurrent misses for the same key share
+// a single invocation. A request with the "Cache-Control: no-cache" header
+// bypasses the cached result.
+func (c *volumeCache) get(
+	ctx types.Context,
+	req *http.Request,
+	key string,
+	f func() (interface{}, error)) (interface{}, error) {
+
+	noCache := isNoCache(req)
+
+	c.Lock()
+	if !noCache {
+		if e, ok := c.store.Get(key).(*volumeCacheEntry); ok {
+			c.Unlock()
+			ctx.WithField("cacheKey", key).Debug("volume cache hit")
+			return e.result, nil
+		}
+	}
+	if call, ok := c.calls[key]; ok && !noCache {
+		c.Unlock()
+		ctx.WithField("cacheKey", key).Debug("joined volume cache miss")
+		<-call.done
+		return call.result, call.err
+	}
+	call := &volumeCacheCall{done: make(chan struct{})}
+	if !noCache {
+		c.calls[key] = call
+	}
+	gen := c.generation
+	c.Unlock()
+
+	ctx.WithField("cacheKey", key).Debug("volume cache miss")
+	call.result, call.err = f()
+
+	c.Lock()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	if call.err == nil && c.generation == gen {
+		c.store.Set(key, &volumeCacheEntry{call.result})
+	}
+	c.Unlock()
+	close(call.done)
+
+	return call.result, call.err
+}
+
+func isNoCache(req *http.Request) bool {
+	if req == nil {
+		return false
+	}
+	for _, v := range req.Header["Cache-Control"] {
+		for _, d := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(d), "no-cache") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// volumeCacheKey returns the cache key for a driver call. Attachment
+// information depends upon the instance and local devices provided with the
+// request, so they are part of the key when attachments are requested.
+func volumeCacheKey(
+	ctx types.Context,
+	op, volumeID string,
+	attachments types.VolumeAttachmentsTypes) string {
+
+	key := fmt.Sprintf("%s:%s:%d", op, volumeID, attachments)
+	if !attachments.Requested() {
+		return key
+	}
+	if iid, ok := context.InstanceID(ctx); ok {
+		key = fmt.Sprintf("%s:%s", key, iid.String())
+	}
+	if attachments.Devices() {
+		if ld, ok := context.LocalDevices(ctx); ok {
+			key = fmt.Sprintf("%s:%s", key, ld.String())
+		}
+	}
+	return key
+}
+
+// cachedVolumes returns a service's volumes from the cache, if enabled, or
+// from the service's driver.
+func cachedVolumes(
+	ctx types.Context,
+	req *http.Request,
+	svc types.StorageService,
+	opts *types.VolumesOpts) ([]*types.Volume, error) {
+
+	c := getVolumeCache(svc)
+	if c == nil {
+		return svc.Driver().Volumes(ctx, opts)
+	}
+
+	key := volumeCacheKey(ctx, "volumes", "", opts.Attachments)
+	result, err := c.get(ctx, req, key, func() (interface{}, error) {
+		return svc.Driver().Volumes(ctx, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the volumes are copied since the router modifies the volumes it
+	// writes to the response
+	var vols []*types.Volume
+	if err := copyVolumes(result, &vols); err != nil {
+		return nil, err
+	}
+	return vols, nil
+}
+
+// cachedVolumeInspect returns a volume from the cache, if enabled, or from
+// the service's driver.
+func cachedVolumeInspect(
+	ctx types.Context,
+	req *http.Request,
+	svc types.StorageService,
+	volumeID string,
+	opts *types.VolumeInspectOpts) (*types.Volume, error) {
+
+	c := getVolumeCache(svc)
+	if c == nil {
+		return svc.Driver().VolumeInspect(ctx, volumeID, opts)
+	}
+
+	key := volumeCacheKey(ctx, "volume", volumeID, opts.Attachments)
+	result, err := c.get(ctx, req, key, func() (interface{}, error) {
+		return svc.Driver().VolumeInspect(ctx, volumeID, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var vol *types.Volume
+	if err := copyVolumes(result, &vol); err != nil {
+		return nil, err
+	}
+	return vol, nil
+}
+
+func copyVolumes(src, dst interface{}) error {
+	buf, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, dst)
+}
+
+// volumeCacheFlusher flushes the volume caches of all services.
+type volumeCacheFlusher struct{}
+
+func (f *volumeCacheFlusher) Name() string {
+	return "volumes"
+}
+
+func (f *volumeCacheFlusher) Flush(ctx types.Context) int {
+	volCachesRWL.RLock()
+	defer volCachesRWL.RUnlock()
+	n := 0
+	for _, c := range volCaches {
+		if c != nil {
+			n += c.flush()
+		}
+	}
+	return n
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/akutz/goof"
@@ -32,9 +33,9 @@ func (r *router) volumes(
 	}
 
 	var (
-		tasks   = map[string]*types.Task{}
-		taskIDs []int
-		opts    = &types.VolumesOpts{
+		tasks    = map[string]*types.Task{}
+		timeouts = map[string]time.Duration{}
+		opts     = &types.VolumesOpts{
 			Attachments: store.GetAttachments(),
 			Opts:        store,
 		}
@@ -58,28 +59,28 @@ func (r *router) volumes(
 		}
 
 		task := service.TaskEnqueue(ctx, run, schema.VolumeMapSchema)
-		taskIDs = append(taskIDs, task.ID)
 		tasks[service.Name()] = task
+		timeouts[service.Name()] = r.serviceTimeout(service)
 	}
 
 	run := func(ctx types.Context) (interface{}, error) {
 
-		services.TaskWaitAll(ctx, taskIDs...)
+		errs := waitServiceTasks(ctx, tasks, timeouts)
 
 		for k, v := range tasks {
-			if v.Error != nil {
-				return nil, utils.NewBatchProcessErr(reply, v.Error)
+			if _, ok := errs[k]; ok {
+				continue
 			}
 
 			objMap, ok := v.Result.(types.VolumeMap)
 			if !ok {
-				return nil, utils.NewBatchProcessErr(
-					reply, goof.New("error casting to types.VolumeMap"))
+				errs[k] = goof.New("error casting to types.VolumeMap")
+				continue
 			}
 			reply[k] = objMap
 		}
 
-		return reply, nil
+		return r.partialReply(store, reply, errs)
 	}
 
 	return httputils.WriteTask(
@@ -230,7 +231,7 @@ func getFilteredVolumes(
 
 	ctx.WithField("attachments", opts.Attachments).Debug("querying volumes")
 
-	objs, err := storSvc.Driver().Volumes(ctx, opts)
+	objs, err := cachedVolumes(ctx, req, storSvc, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -249,6 +250,11 @@ func getFilteredVolumes(
 			"volumeName":  obj.Name,
 		}
 
+		if !utils.InNamespace(ctx, obj.Fields) {
+			ctx.WithFields(lf).Debug("omitted volume outside of namespace")
+			continue
+		}
+
 		if filterOp == types.FilterEqualityMatch && filterLeft == "name" {
 			ctx.WithFields(lf).Debug("checking name filter")
 			if !strings.EqualFold(obj.Name, filterRight) {
@@ -257,6 +263,17 @@ func getFilteredVolumes(
 			}
 		}
 
+		if filterOp == types.FilterEqualityMatch &&
+			filterLeft == types.VolumeFieldNamespace {
+			ctx.WithFields(lf).Debug("checking namespace filter")
+			ns := obj.Fields[types.VolumeFieldNamespace]
+			if !strings.EqualFold(ns, filterRight) {
+				ctx.WithFields(lf).Debug(
+					"omitted volume due to namespace filter")
+				continue
+			}
+		}
+
 		if !handleVolAttachments(ctx, lf, iid, obj, opts.Attachments) {
 			continue
 		}
@@ -303,8 +320,10 @@ func (r *router) volumeInspect(
 			ctx types.Context,
 			svc types.StorageService) (interface{}, error) {
 
-			vols, err := svc.Driver().Volumes(
+			vols, err := cachedVolumes(
 				ctx,
+				req,
+				svc,
 				&types.VolumesOpts{
 					Attachments: attachments,
 					Opts:        store,
@@ -316,7 +335,9 @@ func (r *router) volumeInspect(
 
 			volID := store.GetString("volumeID")
 			for _, v := range vols {
-				if strings.EqualFold(v.Name, volID) {
+				// volume names are only unique within a namespace
+				if strings.EqualFold(v.Name, volID) &&
+					utils.InNamespace(ctx, v.Fields) {
 					if !handleVolAttachments(ctx, nil, iid, v, attachments) {
 						return nil, utils.NewNotFoundError(volID)
 					}
@@ -343,13 +364,17 @@ func (r *router) volumeInspect(
 			ctx types.Context,
 			svc types.StorageService) (interface{}, error) {
 
-			v, err := svc.Driver().VolumeInspect(
-				ctx, store.GetString("volumeID"), opts)
+			v, err := cachedVolumeInspect(
+				ctx, req, svc, store.GetString("volumeID"), opts)
 
 			if err != nil {
 				return nil, err
 			}
 
+			if !utils.InNamespace(ctx, v.Fields) {
+				return nil, utils.NewNotFoundError(v.ID)
+			}
+
 			if !handleVolAttachments(ctx, nil, iid, v, attachments) {
 				return nil, utils.NewNotFoundError(v.ID)
 			}
@@ -385,23 +410,48 @@ func (r *router) volumeCreate(
 
 	service := context.MustService(ctx)
 
+	if httputils.IsDryRun(store) {
+		return httputils.WriteDryRun(
+			ctx, r.config, w, store, service, "", "",
+			&types.ValidateOpts{
+				Operation:  "volumeCreate",
+				Name:       store.GetString("name"),
+				CreateOpts: newVolumeCreateOpts(store),
+				Opts:       store,
+			})
+	}
+
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
 		volumeName := store.GetString("name")
-		opts := &types.VolumeCreateOpts{
-			AvailabilityZone: store.GetStringPtr("availabilityZone"),
-			IOPS:             store.GetInt64Ptr("iops"),
-			Size:             store.GetInt64Ptr("size"),
-			Type:             store.GetStringPtr("type"),
-			Encrypted:        store.GetBoolPtr("encrypted"),
-			EncryptionKey:    store.GetStringPtr("encryptionKey"),
-			Opts:             store,
+		opts := newVolumeCreateOpts(store)
+		if err := utils.ApplyStorageClass(svc, opts); err != nil {
+			return nil, err
+		}
+		httputils.DefaultAvailabilityZone(ctx, svc, store, opts)
+
+		if err := services.PolicyCheck(
+			ctx, svc, "volumeCreate", store, opts); err != nil {
+			return nil, err
+		}
+
+		release, err := services.QuotaReserve(
+			ctx, svc, utils.NewVolumeQuotaUsage(opts.Size, opts.IOPS))
+		if err != nil {
+			return nil, err
 		}
+		defer release()
+		utils.SetOwner(ctx, store)
+		utils.SetNamespace(ctx, store)
+
 		fields := map[string]interface{}{
 			"volumeName": store.GetString("name"),
 		}
+		if opts.Class != nil {
+			fields["class"] = *opts.Class
+		}
 		if opts.AvailabilityZone != nil {
 			fields["availabilityZone"] = &opts.AvailabilityZone
 		}
@@ -423,11 +473,15 @@ func (r *router) volumeCreate(
 		ctx.WithFields(fields).Debug("creating volume")
 
 		v, err := svc.Driver().VolumeCreate(ctx, volumeName, opts)
+		VolumeChanged(svc, v)
 		if err != nil {
 			ctx.WithFields(fields).WithError(err).Error("error creating volume")
 			return nil, err
 		}
 		ctx.WithFields(fields).Debug("success creating volume")
+		utils.RecordStorageClass(v, opts)
+		v.Fields = utils.RecordOwner(ctx, v.Fields)
+		v.Fields = utils.RecordNamespace(ctx, v.Fields)
 
 		if OnVolume != nil {
 			ok, err := OnVolume(ctx, req, store, v)
@@ -465,19 +519,54 @@ func (r *router) volumeCopy(
 
 	service := context.MustService(ctx)
 
+	if httputils.IsDryRun(store) {
+		return httputils.WriteDryRun(
+			ctx, r.config, w, store, service,
+			store.GetString("volumeID"), "",
+			&types.ValidateOpts{
+				Operation: "volumeCopy",
+				Name:      store.GetString("volumeName"),
+				Opts:      store,
+			})
+	}
+
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
+		if err := utils.CheckVolumeNamespace(
+			ctx, svc, store.GetString("volumeID"), store); err != nil {
+			return nil, err
+		}
+		if err := services.PolicyCheck(
+			ctx, svc, "volumeCopy", store, nil); err != nil {
+			return nil, err
+		}
+
+		requested, err := copyQuotaUsage(ctx, svc, store)
+		if err != nil {
+			return nil, err
+		}
+		release, err := services.QuotaReserve(ctx, svc, requested)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		utils.SetOwner(ctx, store)
+		utils.SetNamespace(ctx, store)
+
 		v, err := svc.Driver().VolumeCopy(
 			ctx,
 			store.GetString("volumeID"),
 			store.GetString("volumeName"),
 			store)
+		VolumeChanged(svc, v)
 
 		if err != nil {
 			return nil, err
 		}
+		v.Fields = utils.RecordOwner(ctx, v.Fields)
+		v.Fields = utils.RecordNamespace(ctx, v.Fields)
 
 		if OnVolume != nil {
 			ok, err := OnVolume(ctx, req, store, v)
@@ -492,6 +581,7 @@ func (r *router) volumeCopy(
 		if v.AttachmentState == 0 {
 			v.AttachmentState = types.VolumeAvailable
 		}
+
 		return v, nil
 	}
 
@@ -512,15 +602,52 @@ func (r *router) volumeSnapshot(
 
 	service := context.MustService(ctx)
 
+	if httputils.IsDryRun(store) {
+		return httputils.WriteDryRun(
+			ctx, r.config, w, store, service,
+			store.GetString("volumeID"), "",
+			&types.ValidateOpts{
+				Operation: "volumeSnapshot",
+				Name:      store.GetString("snapshotName"),
+				Opts:      store,
+			})
+	}
+
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
-		return svc.Driver().VolumeSnapshot(
+		if err := utils.CheckVolumeNamespace(
+			ctx, svc, store.GetString("volumeID"), store); err != nil {
+			return nil, err
+		}
+		if err := services.PolicyCheck(
+			ctx, svc, "volumeSnapshot", store, nil); err != nil {
+			return nil, err
+		}
+
+		release, err := services.QuotaReserve(
+			ctx, svc, &types.QuotaUsage{Snapshots: 1})
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		utils.SetOwner(ctx, store)
+		utils.SetNamespace(ctx, store)
+
+		snap, err := svc.Driver().VolumeSnapshot(
 			ctx,
 			store.GetString("volumeID"),
 			store.GetString("snapshotName"),
 			store)
+		if err != nil {
+			return nil, err
+		}
+		if snap != nil {
+			snap.Fields = utils.RecordOwner(ctx, snap.Fields)
+			snap.Fields = utils.RecordNamespace(ctx, snap.Fields)
+		}
+		return snap, nil
 	}
 
 	return httputils.WriteTask(
@@ -543,10 +670,30 @@ func (r *router) volumeAttach(
 		return utils.NewMissingInstanceIDError(service.Name())
 	}
 
+	if httputils.IsDryRun(store) {
+		return httputils.WriteDryRun(
+			ctx, r.config, w, store, service,
+			store.GetString("volumeID"), "",
+			&types.ValidateOpts{
+				Operation: "volumeAttach",
+				Force:     store.GetBool("force"),
+				Opts:      store,
+			})
+	}
+
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
+		if err := utils.CheckVolumeNamespace(
+			ctx, svc, store.GetString("volumeID"), store); err != nil {
+			return nil, err
+		}
+		if err := services.PolicyCheck(
+			ctx, svc, "volumeAttach", store, nil); err != nil {
+			return nil, err
+		}
+
 		v, attTokn, err := svc.Driver().VolumeAttach(
 			ctx,
 			store.GetString("volumeID"),
@@ -555,6 +702,7 @@ func (r *router) volumeAttach(
 				Force:      store.GetBool("force"),
 				Opts:       store,
 			})
+		VolumeChanged(svc, v)
 
 		if err != nil {
 			return nil, err
@@ -600,10 +748,30 @@ func (r *router) volumeDetach(
 		return utils.NewMissingInstanceIDError(service.Name())
 	}
 
+	if httputils.IsDryRun(store) {
+		return httputils.WriteDryRun(
+			ctx, r.config, w, store, service,
+			store.GetString("volumeID"), "",
+			&types.ValidateOpts{
+				Operation: "volumeDetach",
+				Force:     store.GetBool("force"),
+				Opts:      store,
+			})
+	}
+
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
+		if err := utils.CheckVolumeNamespace(
+			ctx, svc, store.GetString("volumeID"), store); err != nil {
+			return nil, err
+		}
+		if err := services.PolicyCheck(
+			ctx, svc, "volumeDetach", store, nil); err != nil {
+			return nil, err
+		}
+
 		v, err := svc.Driver().VolumeDetach(
 			ctx,
 			store.GetString("volumeID"),
@@ -611,6 +779,7 @@ func (r *router) volumeDetach(
 				Force: store.GetBool("force"),
 				Opts:  store,
 			})
+		VolumeChanged(svc, v)
 
 		if err != nil {
 			return nil, err
@@ -653,11 +822,11 @@ func (r *router) volumeDetachAll(
 	store types.Store) error {
 
 	var (
-		taskIDs  []int
-		tasks                           = map[string]*types.Task{}
-		opts                            = &types.VolumesOpts{Opts: store}
-		reply    types.ServiceVolumeMap = map[string]types.VolumeMap{}
-		replyRWL                        = &sync.Mutex{}
+		tasks    = map[string]*types.Task{}
+		timeouts = map[string]time.Duration{}
+		opts     = &types.VolumesOpts{Opts: store}
+		reply    = types.ServiceVolumeMap{}
+		replyRWL = &sync.Mutex{}
 	)
 
 	for service := range services.StorageServices(ctx) {
@@ -669,7 +838,7 @@ func (r *router) volumeDetachAll(
 			ctx = context.WithStorageService(ctx, svc)
 
 			if _, ok := context.InstanceID(ctx); !ok {
-				return nil, utils.NewMissingInstanceIDError(service.Name())
+				return nil, utils.NewMissingInstanceIDError(svc.Name())
 			}
 
 			var err error
@@ -677,6 +846,11 @@ func (r *router) volumeDetachAll(
 				return nil, err
 			}
 
+			if err := services.PolicyCheck(
+				ctx, svc, "volumeDetachAll", store, nil); err != nil {
+				return nil, err
+			}
+
 			driver := svc.Driver()
 
 			volumes, err := driver.Volumes(ctx, opts)
@@ -690,11 +864,14 @@ func (r *router) volumeDetachAll(
 				if len(volumeMap) > 0 {
 					replyRWL.Lock()
 					defer replyRWL.Unlock()
-					reply[service.Name()] = volumeMap
+					reply[svc.Name()] = volumeMap
 				}
 			}()
 
 			for _, volume := range volumes {
+				if !utils.InNamespace(ctx, volume.Fields) {
+					continue
+				}
 				v, err := driver.VolumeDetach(
 					ctx,
 					volume.ID,
@@ -702,6 +879,7 @@ func (r *router) volumeDetachAll(
 						Force: store.GetBool("force"),
 						Opts:  store,
 					})
+				VolumeChanged(svc, v)
 				if err != nil {
 					return nil, err
 				}
@@ -735,18 +913,29 @@ func (r *router) volumeDetachAll(
 		}
 
 		task := service.TaskEnqueue(ctx, run, nil)
-		taskIDs = append(taskIDs, task.ID)
 		tasks[service.Name()] = task
+		timeouts[service.Name()] = r.serviceTimeout(service)
 	}
 
 	run := func(ctx types.Context) (interface{}, error) {
-		services.TaskWaitAll(ctx, taskIDs...)
-		for _, v := range tasks {
-			if v.Error != nil {
-				return nil, utils.NewBatchProcessErr(reply, v.Error)
+
+		errs := waitServiceTasks(ctx, tasks, timeouts)
+
+		// copy the volumes detached by the services that did not time out
+		// since those that did may still update the reply
+		completed := types.ServiceVolumeMap{}
+		replyRWL.Lock()
+		for k, v := range reply {
+			if err, ok := errs[k]; ok {
+				if _, ok := err.(*types.ErrServiceTimeout); ok {
+					continue
+				}
 			}
+			completed[k] = v
 		}
-		return reply, nil
+		replyRWL.Unlock()
+
+		return r.partialReply(store, completed, errs)
 	}
 
 	return httputils.WriteTask(
@@ -775,6 +964,11 @@ func (r *router) volumeDetachAllForService(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
+		if err := services.PolicyCheck(
+			ctx, svc, "volumeDetachAll", store, nil); err != nil {
+			return nil, err
+		}
+
 		driver := svc.Driver()
 
 		volumes, err := driver.Volumes(ctx, &types.VolumesOpts{Opts: store})
@@ -783,6 +977,9 @@ func (r *router) volumeDetachAllForService(
 		}
 
 		for _, volume := range volumes {
+			if !utils.InNamespace(ctx, volume.Fields) {
+				continue
+			}
 			v, err := driver.VolumeDetach(
 				ctx,
 				volume.ID,
@@ -790,6 +987,7 @@ func (r *router) volumeDetachAllForService(
 					Force: store.GetBool("force"),
 					Opts:  store,
 				})
+			VolumeChanged(svc, v)
 			if err != nil {
 				return nil, utils.NewBatchProcessErr(reply, err)
 			}
@@ -839,17 +1037,44 @@ func (r *router) volumeRemove(
 
 	service := context.MustService(ctx)
 
+	if httputils.IsDryRun(store) {
+		return httputils.WriteDryRun(
+			ctx, r.config, w, store, service,
+			store.GetString("volumeID"), "",
+			&types.ValidateOpts{
+				Operation: "volumeRemove",
+				Force:     store.GetBool("force"),
+				Opts:      store,
+			})
+	}
+
 	run := func(
 		ctx types.Context,
 		svc types.StorageService) (interface{}, error) {
 
-		return nil, svc.Driver().VolumeRemove(
+		volumeID := store.GetString("volumeID")
+		if err := utils.CheckVolumeNamespace(
+			ctx, svc, volumeID, store); err != nil {
+			return nil, err
+		}
+		if err := services.PolicyCheck(
+			ctx, svc, "volumeRemove", store, nil); err != nil {
+			return nil, err
+		}
+
+		if err := svc.Driver().VolumeRemove(
 			ctx,
-			store.GetString("volumeID"),
+			volumeID,
 			&types.VolumeRemoveOpts{
 				Force: store.GetBool("force"),
 				Opts:  store,
-			})
+			}); err != nil {
+			VolumeChanged(svc, nil)
+			return nil, err
+		}
+
+		volumeRemoved(svc, volumeID)
+		return nil, nil
 	}
 
 	return httputils.WriteTask(
@@ -861,6 +1086,130 @@ func (r *router) volumeRemove(
 		http.StatusNoContent)
 }
 
+// serviceTimeout returns the amount of time a service has to complete its
+// part of a request for multiple services. A zero value indicates there is
+// no timeout.
+func (r *router) serviceTimeout(service types.StorageService) time.Duration {
+	config := r.config
+	if sc, ok := service.(hasConfig); ok {
+		config = sc.Config()
+	}
+	d, err := time.ParseDuration(
+		config.GetString(types.ConfigServerVolumesServiceTimeout))
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// waitServiceTasks waits for the tasks of multiple services to complete. A
+// task that does not complete within its service's timeout is cancelled so
+// that it does not hold the service's queue for later requests. The errors of the services whose tasks failed or were abandoned are returned
+// keyed by service name.
+func waitServiceTasks(
+	ctx types.Context,
+	tasks map[string]*types.Task,
+	timeouts map[string]time.Duration) map[string]error {
+
+	var (
+		start = time.Now()
+		errs  = map[string]error{}
+	)
+
+	for name, task := range tasks {
+		done := services.TaskWaitC(ctx, task.ID)
+		if timeout := timeouts[name]; timeout > 0 {
+			timer := time.NewTimer(start.Add(timeout).Sub(time.Now()))
+			select {
+			case <-done:
+				timer.Stop()
+			case <-timer.C:
+				ctx.WithField("service", name).Warn("service timed out")
+				services.TaskCancel(ctx, task.ID)
+				errs[name] = utils.NewServiceTimeoutErr(name, timeout)
+				continue
+			}
+		} else {
+			<-done
+		}
+		if task.Error != nil {
+			errs[name] = task.Error
+		}
+	}
+
+	return errs
+}
+
+// partialReply returns the reply for a request for multiple services. If
+// any of the services failed then an error is returned unless partial
+// results are enabled, in which case the reply includes the errors.
+func (r *router) partialReply(
+	store types.Store,
+	reply types.ServiceVolumeMap,
+	errs map[string]error) (interface{}, error) {
+
+	if len(errs) == 0 {
+		return reply, nil
+	}
+
+	partial := r.config.GetBool(types.ConfigServerVolumesPartial)
+	if store.IsSet("partial") {
+		partial = store.GetBool("partial")
+	}
+
+	if !partial {
+		for _, err := range errs {
+			return nil, utils.NewBatchProcessErr(reply, err)
+		}
+	}
+
+	res := &types.PartialServiceVolumeMap{
+		Volumes: reply,
+		Errors:  map[string]*types.ServiceError{},
+	}
+	for k, err := range errs {
+		_, timeout := err.(*types.ErrServiceTimeout)
+		res.Errors[k] = &types.ServiceError{
+			Message: err.Error(),
+			Timeout: timeout,
+		}
+	}
+	return res, nil
+}
+
+// copyQuotaUsage returns the usage requested by copying a volume. The source
+// volume is only inspected if quotas are configured.
+func copyQuotaUsage(
+	ctx types.Context,
+	svc types.StorageService,
+	store types.Store) (*types.QuotaUsage, error) {
+
+	if !services.QuotasEnabled(ctx) {
+		return &types.QuotaUsage{Volumes: 1}, nil
+	}
+	v, err := svc.Driver().VolumeInspect(
+		ctx,
+		store.GetString("volumeID"),
+		&types.VolumeInspectOpts{Opts: store})
+	if err != nil {
+		return nil, err
+	}
+	return utils.NewVolumeQuotaUsage(&v.Size, &v.IOPS), nil
+}
+
+func newVolumeCreateOpts(store types.Store) *types.VolumeCreateOpts {
+	return &types.VolumeCreateOpts{
+		AvailabilityZone: store.GetStringPtr("availabilityZone"),
+		IOPS:             store.GetInt64Ptr("iops"),
+		Size:             store.GetInt64Ptr("size"),
+		Type:             store.GetStringPtr("type"),
+		Encrypted:        store.GetBoolPtr("encrypted"),
+		EncryptionKey:    store.GetStringPtr("encryptionKey"),
+		Class:            store.GetStringPtr("class"),
+		Opts:             store,
+	}
+}
+
 func parseFilter(store types.Store) (*types.Filter, error) {
 	if !store.IsSet("filter") {
 		return nil, nil
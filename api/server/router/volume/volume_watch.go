@@ -0,0 +1,450 @@
+package volume
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/httputils"
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+const (
+	// maxWatchTombstones is the number of removed volumes a watcher
+	// remembers. A watch with a revision older than the oldest forgotten
+	// removal receives the service's entire volume set.
+	maxWatchTombstones = 1024
+
+	defaultWatchTimeout = time.Minute
+	defaultWatchRelist  = 30 * time.Second
+)
+
+var (
+	watchers    = map[types.StorageService]*watcher{}
+	watchersRWL = &sync.RWMutex{}
+)
+
+// watcher tracks the revision of a service's volume set.
+type watcher struct {
+	sync.Mutex
+
+	// revision is incremented every time the volume set changes.
+	revision int64
+
+	// compacted is the revision of the most recent forgotten removal.
+	compacted int64
+
+	// listed is the time of the last re-list. It is zero until the volume
+	// set has been listed at least once.
+	listed time.Time
+
+	// listing is a flag that indicates a re-list is in progress.
+	listing bool
+
+	// watches is the number of watch requests in progress.
+	watches int
+
+	// relisting is a flag that indicates the background re-list is running.
+	relisting bool
+
+	volumes    map[string]*watchedVolume
+	tombstones map[string]*tombstone
+
+	// changed is closed and replaced whenever the revision is incremented.
+	changed chan struct{}
+}
+
+type watchedVolume struct {
+	volume      *types.Volume
+	fingerprint string
+	created     int64
+	modified    int64
+}
+
+// tombstone records the removal of a volume. The removed volume's fields are
+// retained so the removal is only reported to watchers of its namespace.
+type tombstone struct {
+	revision int64
+	fields   map[string]string
+}
+
+func getWatcher(service types.StorageService) *watcher {
+	watchersRWL.RLock()
+	w, ok := watchers[service]
+	watchersRWL.RUnlock()
+	if ok {
+		return w
+	}
+
+	watchersRWL.Lock()
+	defer watchersRWL.Unlock()
+	if w, ok := watchers[service]; ok {
+		return w
+	}
+	w = &watcher{
+		volumes:    map[string]*watchedVolume{},
+		tombstones: map[string]*tombstone{},
+		changed:    make(chan struct{}),
+	}
+	watchers[service] = w
+	return w
+}
+
+// lookupWatcher returns the watcher for a service if it has listed the
+// service's volumes at least once.
+func lookupWatcher(service types.StorageService) (*watcher, bool) {
+	watchersRWL.RLock()
+	defer watchersRWL.RUnlock()
+	w, ok := watchers[service]
+	if !ok {
+		return nil, false
+	}
+	w.Lock()
+	defer w.Unlock()
+	return w, !w.listed.IsZero()
+}
+
+// VolumeChanged is invoked by the mutating routes after they call a
+// service's driver. The service's volume cache is invalidated, and the
+// volume, if any, is reported to watchers of the service's volumes.
+func VolumeChanged(service types.StorageService, v *types.Volume) {
+	invalidateVolumeCache(service)
+	if v == nil || v.ID == "" {
+		return
+	}
+	if w, ok := lookupWatcher(service); ok {
+		w.Lock()
+		defer w.Unlock()
+		if w.upsert(w.revision+1, v) {
+			w.bump()
+		}
+	}
+}
+
+// volumeRemoved invalidates the service's volume cache and reports the
+// removal of a volume to watchers of the service's volumes.
+func volumeRemoved(service types.StorageService, volumeID string) {
+	invalidateVolumeCache(service)
+	if w, ok := lookupWatcher(service); ok {
+		w.Lock()
+		defer w.Unlock()
+		if w.remove(w.revision+1, volumeID) {
+			w.bump()
+		}
+	}
+}
+
+func fingerprint(v *types.Volume) string {
+	// the attachment state depends upon the instance that requested the
+	// volume and is omitted from the fingerprint
+	vc := *v
+	vc.AttachmentState = 0
+	buf, _ := json.Marshal(&vc)
+	return string(buf)
+}
+
+// upsert records a volume at the provided revision and returns a flag
+// indicating whether or not the volume is new or has changed. The lock must
+// be held by the caller.
+func (w *watcher) upsert(rev int64, v *types.Volume) bool {
+	fp := fingerprint(v)
+	if wv, ok := w.volumes[v.ID]; ok {
+		if wv.fingerprint == fp {
+			return false
+		}
+		wv.volume = v
+		wv.fingerprint = fp
+		wv.modified = rev
+		return true
+	}
+	delete(w.tombstones, v.ID)
+	w.volumes[v.ID] = &watchedVolume{
+		volume:      v,
+		fingerprint: fp,
+		created:     rev,
+		modified:    rev,
+	}
+	return true
+}
+
+// remove records the removal of a volume at the provided revision and
+// returns a flag indicating whether or not the volume was known. The lock
+// must be held by the caller.
+func (w *watcher) remove(rev int64, volumeID string) bool {
+	wv, ok := w.volumes[volumeID]
+	if !ok {
+		return false
+	}
+	delete(w.volumes, volumeID)
+	w.tombstones[volumeID] = &tombstone{
+		revision: rev,
+		fields:   wv.volume.Fields,
+	}
+	w.compact()
+	return true
+}
+
+// compact forgets the oldest removals once there are more than
+// maxWatchTombstones of them. The lock must be held by the caller.
+func (w *watcher) compact() {
+	for len(w.tombstones) > maxWatchTombstones {
+		var (
+			oldestID  string
+			oldestRev int64
+		)
+		for id, ts := range w.tombstones {
+			if oldestID == "" || ts.revision < oldestRev {
+				oldestID, oldestRev = id, ts.revision
+			}
+		}
+		delete(w.tombstones, oldestID)
+		if oldestRev > w.compacted {
+			w.compacted = oldestRev
+		}
+	}
+}
+
+// bump increments the revision and wakes any waiting watches. The lock must
+// be held by the caller.
+func (w *watcher) bump() {
+	w.revision++
+	close(w.changed)
+	w.changed = make(chan struct{})
+}
+
+// apply replaces the volume set with the result of a re-list. The lock must
+// be held by the caller.
+func (w *watcher) apply(vols []*types.Volume) {
+	var (
+		rev     = w.revision + 1
+		changed bool
+		listed  = map[string]bool{}
+	)
+	for _, v := range vols {
+		listed[v.ID] = true
+		if w.upsert(rev, v) {
+			changed = true
+		}
+	}
+	for id := range w.volumes {
+		if !listed[id] && w.remove(rev, id) {
+			changed = true
+		}
+	}
+	if changed {
+		w.bump()
+	}
+	w.listed = time.Now()
+}
+
+// relist lists the service's volumes if they have not been listed within
+// the provided interval and records any changes. Concurrent watches share a
+// single re-list.
+func (w *watcher) relist(
+	ctx types.Context,
+	svc types.StorageService,
+	interval time.Duration) error {
+
+	w.Lock()
+	if w.listing || (!w.listed.IsZero() && time.Since(w.listed) < interval) {
+		w.Unlock()
+		return nil
+	}
+	w.listing = true
+	w.Unlock()
+
+	defer func() {
+		w.Lock()
+		w.listing = false
+		w.Unlock()
+	}()
+
+	run := func(
+		ctx types.Context,
+		svc types.StorageService) (interface{}, error) {
+
+		return svc.Driver().Volumes(
+			ctx, &types.VolumesOpts{Attachments: types.VolAttReq})
+	}
+
+	task := svc.TaskEnqueue(ctx, run, nil)
+	services.TaskWait(ctx, task.ID)
+	if task.Error != nil {
+		return task.Error
+	}
+
+	vols, _ := task.Result.([]*types.Volume)
+
+	w.Lock()
+	defer w.Unlock()
+	w.apply(vols)
+
+	ctx.WithField("revision", w.revision).Debug("re-listed watched volumes")
+	return nil
+}
+
+// begin records the beginning of a watch request and starts the background
+// re-list of the service's volumes if it is not running.
+func (w *watcher) begin(
+	ctx types.Context,
+	svc types.StorageService,
+	interval time.Duration) {
+
+	w.Lock()
+	defer w.Unlock()
+	w.watches++
+	if w.relisting {
+		return
+	}
+	w.relisting = true
+
+	// the background re-list outlives the request that started it
+	go w.relistLoop(context.WithoutCancel(ctx), svc, interval)
+}
+
+// end records the end of a watch request.
+func (w *watcher) end() {
+	w.Lock()
+	defer w.Unlock()
+	w.watches--
+}
+
+// relistLoop re-lists the service's volumes at the provided interval until
+// there are no watch requests in progress.
+func (w *watcher) relistLoop(
+	ctx types.Context,
+	svc types.StorageService,
+	interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.Lock()
+		if w.watches == 0 {
+			w.relisting = false
+			w.Unlock()
+			ctx.Debug("stopped re-listing watched volumes")
+			return
+		}
+		w.Unlock()
+
+		if err := w.relist(ctx, svc, 0); err != nil {
+			ctx.WithError(err).Warn("error re-listing watched volumes")
+		}
+	}
+}
+
+// changes returns the changes to the volume set since the provided revision
+// and a channel that is closed at the next change. The response is nil if
+// there are no changes. Only the changes to the volumes in the namespace to
+// which the request is scoped are returned.
+func (w *watcher) changes(
+	ctx types.Context,
+	since int64) (*types.VolumeWatchResponse, <-chan struct{}) {
+
+	w.Lock()
+	defer w.Unlock()
+
+	if w.listed.IsZero() || since == w.revision {
+		return nil, w.changed
+	}
+
+	res := &types.VolumeWatchResponse{
+		Revision: w.revision,
+		Added:    types.VolumeMap{},
+		Modified: types.VolumeMap{},
+	}
+
+	// a revision older than the oldest remembered removal receives the
+	// entire volume set, as does a revision newer than the current one,
+	// which is the case when the server has restarted since the revision
+	if since < w.compacted || since > w.revision {
+		res.Reset = true
+		for id, wv := range w.volumes {
+			if utils.InNamespace(ctx, wv.volume.Fields) {
+				res.Added[id] = wv.volume
+			}
+		}
+		return res, w.changed
+	}
+
+	for id, wv := range w.volumes {
+		if !utils.InNamespace(ctx, wv.volume.Fields) {
+			continue
+		}
+		switch {
+		case wv.created > since:
+			res.Added[id] = wv.volume
+		case wv.modified > since:
+			res.Modified[id] = wv.volume
+		}
+	}
+	for id, ts := range w.tombstones {
+		if ts.revision > since && utils.InNamespace(ctx, ts.fields) {
+			res.Removed = append(res.Removed, id)
+		}
+	}
+
+	return res, w.changed
+}
+
+func (r *router) volumesWatch(
+	ctx types.Context,
+	w http.ResponseWriter,
+	req *http.Request,
+	store types.Store) error {
+
+	var (
+		service = context.MustService(ctx)
+		since   = store.GetInt64("since")
+		wat     = getWatcher(service)
+		timeout = r.watchDuration(
+			types.ConfigServerVolumesWatchTimeout, defaultWatchTimeout)
+		interval = r.watchDuration(
+			types.ConfigServerVolumesWatchRelist, defaultWatchRelist)
+	)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	if err := wat.relist(ctx, service, interval); err != nil {
+		return err
+	}
+
+	wat.begin(ctx, service, interval)
+	defer wat.end()
+
+	for {
+		res, changed := wat.changes(ctx, since)
+		if res != nil {
+			return httputils.WriteJSON(w, http.StatusOK, res)
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			// the client has gone away
+			return ctx.Err()
+		case <-timer.C:
+			wat.Lock()
+			rev := wat.revision
+			wat.Unlock()
+			return httputils.WriteJSON(
+				w, http.StatusOK, &types.VolumeWatchResponse{Revision: rev})
+		}
+	}
+}
+
+func (r *router) watchDuration(
+	key string, defaultVal time.Duration) time.Duration {
+
+	if d, err := time.ParseDuration(r.config.GetString(key)); err == nil {
+		return d
+	}
+	return defaultVal
+}
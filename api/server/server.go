@@ -2,7 +2,6 @@ package server
 
 import (
 	"fmt"
-	"io"
 	"os"
 	"os/signal"
 	"strings"
@@ -24,6 +23,7 @@ import (
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
 	apicnfg "github.com/codedellemc/libstorage/api/utils/config"
+	"github.com/codedellemc/libstorage/api/utils/secrets"
 
 	// imported to load routers
 	_ "github.com/codedellemc/libstorage/imports/routers"
@@ -48,16 +48,8 @@ type server struct {
 	closedSignal chan int
 	closeOnce    *sync.Once
 
-	routers        []types.Router
-	routeHandlers  map[string][]types.Middleware
-	globalHandlers []types.Middleware
-
-	logHTTPEnabled   bool
-	logHTTPRequests  bool
-	logHTTPResponses bool
-
-	stdOut io.WriteCloser
-	stdErr io.WriteCloser
+	routers       []types.Router
+	routeHandlers map[string][]types.Middleware
 }
 
 func newServer(goCtx gocontext.Context, config gofig.Config) (*server, error) {
@@ -114,9 +106,18 @@ func newServer(goCtx gocontext.Context, config gofig.Config) (*server, error) {
 		if config, err = apicnfg.NewConfig(ctx); err != nil {
 			return nil, err
 		}
+	} else if err := secrets.Resolve(ctx, config); err != nil {
+		return nil, err
 	}
 	config = config.Scope(types.ConfigServer)
 
+	// a configured admin token takes precedence over the generated one
+	if v := config.GetString(types.ConfigServerAdminToken); v != "" {
+		adminToken = v
+		secrets.Track(adminToken)
+		ctx = ctx.WithValue(context.AdminTokenKey, adminToken)
+	}
+
 	s := &server{
 		ctx:          ctx,
 		name:         serverName,
@@ -160,6 +161,28 @@ func newServer(goCtx gocontext.Context, config gofig.Config) (*server, error) {
 		s.ctx.WithFields(authFields).Info("configured global auth")
 	}
 
+	adminAuthFields := log.Fields{}
+	adminAuthConfig, err := utils.ParseAdminAuthConfig(
+		s.ctx, config, adminAuthFields)
+	if err != nil {
+		return nil, err
+	}
+	if adminAuthConfig != nil {
+		s.ctx = s.ctx.WithValue(context.AdminAuthConfigKey, adminAuthConfig)
+		s.ctx.WithFields(adminAuthFields).Info("configured admin auth")
+	}
+
+	debugAuthFields := log.Fields{}
+	debugAuthConfig, err := utils.ParseDebugAuthConfig(
+		s.ctx, config, debugAuthFields)
+	if err != nil {
+		return nil, err
+	}
+	if debugAuthConfig != nil {
+		s.ctx = s.ctx.WithValue(context.DebugAuthConfigKey, debugAuthConfig)
+		s.ctx.WithFields(debugAuthFields).Info("configured debug auth")
+	}
+
 	s.ctx.Info("initializing server")
 
 	if err := s.initEndpoints(s.ctx); err != nil {
@@ -172,16 +195,10 @@ func newServer(goCtx gocontext.Context, config gofig.Config) (*server, error) {
 	}
 	s.ctx.Info("initialized services")
 
-	if logConfig.HTTPRequests || logConfig.HTTPResponses {
-		s.logHTTPEnabled = true
-		s.logHTTPRequests = logConfig.HTTPRequests
-		s.logHTTPResponses = logConfig.HTTPResponses
-		s.stdOut = getLogIO(s.ctx, logConfig.Stdout, types.ConfigLogStdout)
-		s.stdErr = getLogIO(s.ctx, logConfig.Stderr, types.ConfigLogStderr)
+	for _, srv := range s.servers {
+		s.initGlobalMiddleware(srv)
 	}
 
-	s.initGlobalMiddleware()
-
 	if err := s.initRouters(); err != nil {
 		return nil, err
 	}
@@ -220,7 +237,10 @@ func Serve(
 	srvErrs := make(chan error, len(s.servers))
 
 	for _, srv := range s.servers {
-		srv.srv.Handler = s.createMux(srv.ctx)
+		if srv.l == nil {
+			continue
+		}
+		srv.srv.Handler = s.createMux(srv)
 		go func(srv *HTTPServer) {
 			srv.ctx.Info("api listening")
 			if err := srv.Serve(); err != nil {
@@ -248,9 +268,12 @@ func Serve(
 
 	// wait a second for all the configured endpoints to start. this isn't
 	// pretty, but the underlying golang http package doesn't really provide
-	// a better option
-	timeout := time.NewTimer(time.Second * 1)
-	<-timeout.C
+	// a better option. the endpoints of an embedded server do not listen,
+	// so there is nothing to wait for
+	if len(s.addrs) > 0 {
+		timeout := time.NewTimer(time.Second * 1)
+		<-timeout.C
+	}
 
 	s.ctx.Info("server started")
 
@@ -292,25 +315,23 @@ func (s *server) close() error {
 		if err := srv.Close(); err != nil {
 			srv.ctx.Error(err)
 		}
-		if srv.l.Addr().Network() == "unix" {
+		if srv.l != nil && srv.l.Addr().Network() == "unix" {
 			laddr := srv.l.Addr().String()
 			srv.ctx.WithField(
 				"path", laddr).Debug("removed unix socket")
 			os.RemoveAll(laddr)
 		}
-		srv.ctx.Debug("shutdown endpoint complete")
-	}
-
-	if s.stdOut != nil {
-		if err := s.stdOut.Close(); err != nil {
-			log.Error(err)
+		if srv.stdOut != nil {
+			if err := srv.stdOut.Close(); err != nil {
+				srv.ctx.Error(err)
+			}
 		}
-	}
-
-	if s.stdErr != nil {
-		if err := s.stdErr.Close(); err != nil {
-			log.Error(err)
+		if srv.stdErr != nil {
+			if err := srv.stdErr.Close(); err != nil {
+				srv.ctx.Error(err)
+			}
 		}
+		srv.ctx.Debug("shutdown endpoint complete")
 	}
 
 	s.ctx.Debug("shutdown server complete")
@@ -0,0 +1,853 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/server/executors"
+	"github.com/codedellemc/libstorage/api/server/handlers"
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// embeddedHost is the host of the endpoints of an embedded server.
+const embeddedHost = "libstorage-server"
+
+// API returns an API client that invokes the routes of the server's first
+// endpoint in process. The requests are handled by the routes' handlers and
+// the middleware that provides the transaction, auth, debug logging, instance
+// IDs and local devices of a request, but they are never encoded as HTTP
+// messages, and the objects the routes reply with are returned as is.
+func (s *server) API() types.APIClient {
+	return &embeddedClient{s: s, srv: s.servers[0]}
+}
+
+// route returns the route with the provided name.
+func (s *server) route(name string) (types.Route, bool) {
+	for _, router := range s.routers {
+		for _, r := range router.Routes() {
+			if r.GetName() == name {
+				return r, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// embeddedClient is the in-process API client of an embedded server.
+type embeddedClient struct {
+	s           *server
+	srv         *HTTPServer
+	cancelTasks bool
+}
+
+// embeddedResponseWriter records the object a route replies with.
+type embeddedResponseWriter struct {
+	header http.Header
+	status int
+	obj    interface{}
+}
+
+func (w *embeddedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *embeddedResponseWriter) Write(p []byte) (int, error) {
+	return 0, goof.New("embedded response must be written as an object")
+}
+
+func (w *embeddedResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *embeddedResponseWriter) WriteObject(
+	code int, v interface{}) error {
+
+	w.WriteHeader(code)
+	w.obj = v
+	return nil
+}
+
+func (w *embeddedResponseWriter) EncodesObjects() bool {
+	return false
+}
+
+// newEmbeddedRequest returns the embedded request for a call with the
+// provided context. The request includes the values the API client sends as
+// headers with an HTTP request.
+func newEmbeddedRequest(
+	ctx types.Context, body interface{}) *types.EmbeddedRequest {
+
+	ctx = context.RequireTX(ctx)
+	er := &types.EmbeddedRequest{
+		Transaction: context.MustTransaction(ctx),
+		Body:        body,
+	}
+
+	if iid, ok := context.InstanceID(ctx); ok {
+		er.InstanceIDs = []*types.InstanceID{iid}
+	} else if iidMap, ok := ctx.Value(
+		context.AllInstanceIDsKey).(types.InstanceIDMap); ok {
+		for _, iid := range iidMap {
+			er.InstanceIDs = append(er.InstanceIDs, iid)
+		}
+	}
+
+	if lds, ok := context.LocalDevices(ctx); ok {
+		er.LocalDevices = []*types.LocalDevices{lds}
+	} else if ldsMap, ok := ctx.Value(
+		context.AllLocalDevicesKey).(types.LocalDevicesMap); ok {
+		for _, lds := range ldsMap {
+			er.LocalDevices = append(er.LocalDevices, lds)
+		}
+	}
+
+	if tok, ok := ctx.Value(context.EncodedAuthTokenKey).(string); ok {
+		er.AuthToken = tok
+	}
+
+	// request server-side debug logging for the scope of the request if
+	// the client is logging at the debug level
+	if lvl, ok := context.GetLogLevel(ctx); ok && lvl >= log.DebugLevel {
+		er.Debug = true
+	}
+
+	return er
+}
+
+// invoke invokes a route and stores the object with which the route replies
+// in the value pointed to by reply. The vars and query are the values an HTTP
+// request provides with its path and query string.
+func (c *embeddedClient) invoke(
+	ctx types.Context,
+	name string,
+	vars map[string]string,
+	query map[string]interface{},
+	body, reply interface{}) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	route, ok := c.s.route(name)
+	if !ok {
+		return goof.WithField("route", name, "invalid route")
+	}
+
+	// the request's context is derived from the endpoint's context and is
+	// cancelled when the call returns, as it is when an HTTP client closes
+	// its connection. tasks are not cancelled along with the request unless
+	// the client asks for it
+	reqCtx, cancel := context.WithCancel(
+		c.srv.ctx.WithValue(context.RouteKey, route))
+	defer cancel()
+
+	req := &http.Request{
+		Method: route.GetMethod(),
+		URL:    &url.URL{Path: route.GetPath()},
+		Header: http.Header{},
+		Host:   embeddedHost,
+	}
+
+	reqCtx = context.WithRequestRoute(reqCtx, req, route)
+	reqCtx = reqCtx.WithValue(
+		context.EmbeddedRequestKey, newEmbeddedRequest(ctx, body))
+	reqCtx.Info("embedded request")
+
+	store := utils.NewStoreWithVars(vars)
+	for k, v := range query {
+		store.Set(k, v)
+	}
+	if c.cancelTasks &&
+		req.Method != http.MethodGet && req.Method != http.MethodHead {
+		store.Set("cancel", true)
+	}
+
+	var (
+		w       = &embeddedResponseWriter{header: http.Header{}}
+		errc    = make(chan error, 1)
+		handler = c.s.handleWithMiddleware(
+			reqCtx, c.srv.embeddedHandlers, route)
+	)
+
+	go func() {
+		errc <- handler(reqCtx, w, req, store)
+	}()
+
+	select {
+	case err := <-errc:
+		if err != nil {
+			return handlers.HTTPError(reqCtx, err)
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if w.status > 299 {
+		return embeddedHTTPError(w.status, w.obj)
+	}
+
+	if reply == nil || w.obj == nil {
+		return nil
+	}
+
+	rv := reflect.ValueOf(reply).Elem()
+	ov := reflect.ValueOf(w.obj)
+	if !ov.Type().AssignableTo(rv.Type()) {
+		return goof.WithFields(goof.Fields{
+			"route":    name,
+			"expected": rv.Type().String(),
+			"actual":   ov.Type().String(),
+		}, "invalid embedded reply")
+	}
+	rv.Set(ov)
+	return nil
+}
+
+// embeddedHTTPError returns the error for a reply whose status is not a
+// success. The route's error object is returned as is, so the caller may
+// inspect the typed error and its fields, as with a reply of the HTTP API.
+// The task of a request that timed out is identified by the error's fields.
+func embeddedHTTPError(status int, obj interface{}) error {
+	switch tobj := obj.(type) {
+	case goof.HTTPError:
+		return tobj
+	case error:
+		return goof.NewHTTPError(tobj, status)
+	case *types.Task:
+		return goof.NewHTTPError(goof.WithFields(goof.Fields{
+			"taskID": tobj.ID,
+			"state":  tobj.State,
+		}, http.StatusText(status)), status)
+	}
+	return goof.NewHTTPError(goof.New(http.StatusText(status)), status)
+}
+
+// invokeAsync invokes a route that enqueues a task and returns a copy of the
+// task.
+func (c *embeddedClient) invokeAsync(
+	ctx types.Context,
+	name string,
+	vars map[string]string,
+	query map[string]interface{},
+	body interface{}) (*types.Task, error) {
+
+	if query == nil {
+		query = map[string]interface{}{}
+	}
+	query["async"] = true
+
+	var task *types.Task
+	if err := c.invoke(ctx, name, vars, query, body, &task); err != nil {
+		return nil, err
+	}
+	return copyTask(task), nil
+}
+
+// copyTask returns a copy of a task so the caller does not share the task
+// the server updates as the task runs.
+func copyTask(task *types.Task) *types.Task {
+	if task == nil {
+		return nil
+	}
+	t := *task
+	return &t
+}
+
+// volumeMapReply returns the volumes and errors of a reply that is either a
+// ServiceVolumeMap or a partial result.
+func volumeMapReply(obj interface{}) (types.ServiceVolumeMap, error) {
+	switch tobj := obj.(type) {
+	case nil:
+		return types.ServiceVolumeMap{}, nil
+	case types.ServiceVolumeMap:
+		return tobj, nil
+	case *types.PartialServiceVolumeMap:
+		if len(tobj.Errors) == 0 {
+			return tobj.Volumes, nil
+		}
+		return tobj.Volumes, &types.ErrPartialResult{Errors: tobj.Errors}
+	}
+	return nil, goof.WithField(
+		"type", reflect.TypeOf(obj).String(), "invalid embedded reply")
+}
+
+func serviceVars(service string) map[string]string {
+	return map[string]string{"service": service}
+}
+
+func volumeVars(service, volumeID string) map[string]string {
+	return map[string]string{"service": service, "volumeID": volumeID}
+}
+
+func snapshotVars(service, snapshotID string) map[string]string {
+	return map[string]string{"service": service, "snapshotID": snapshotID}
+}
+
+func taskVars(taskID int) map[string]string {
+	return map[string]string{"taskID": strconv.Itoa(taskID)}
+}
+
+func flagQuery(name string) map[string]interface{} {
+	return map[string]interface{}{name: true}
+}
+
+func (c *embeddedClient) ServerName() string {
+	return c.s.name
+}
+
+// LogRequests is a no-op since the embedded requests are not HTTP requests.
+func (c *embeddedClient) LogRequests(enabled bool) {}
+
+// LogResponses is a no-op since the embedded responses are not HTTP
+// responses.
+func (c *embeddedClient) LogResponses(enabled bool) {}
+
+// UseContentType is a no-op since the embedded requests are not encoded.
+func (c *embeddedClient) UseContentType(contentType types.ContentType) {}
+
+// UseRetryPolicy is a no-op since the embedded requests cannot fail to reach
+// the server.
+func (c *embeddedClient) UseRetryPolicy(policy *types.RetryPolicy) {}
+
+func (c *embeddedClient) CancelTasks(enabled bool) {
+	c.cancelTasks = enabled
+}
+
+func (c *embeddedClient) Root(ctx types.Context) ([]string, error) {
+	var reply []string
+	if err := c.invoke(ctx, "root", nil, nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) Instances(
+	ctx types.Context) (map[string]*types.Instance, error) {
+
+	var reply map[string]*types.ServiceInfo
+	if err := c.invoke(ctx, "services",
+		nil, flagQuery("instance"), nil, &reply); err != nil {
+		return nil, err
+	}
+	instances := map[string]*types.Instance{}
+	for service, si := range reply {
+		instances[service] = si.Instance
+	}
+	return instances, nil
+}
+
+func (c *embeddedClient) InstanceInspect(
+	ctx types.Context, service string) (*types.Instance, error) {
+
+	var reply *types.ServiceInfo
+	if err := c.invoke(ctx, "serviceInspect",
+		serviceVars(service), flagQuery("instance"),
+		nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Instance, nil
+}
+
+func (c *embeddedClient) Services(
+	ctx types.Context) (map[string]*types.ServiceInfo, error) {
+
+	var reply map[string]*types.ServiceInfo
+	if err := c.invoke(ctx, "services", nil, nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) ServiceInspect(
+	ctx types.Context, name string) (*types.ServiceInfo, error) {
+
+	var query map[string]interface{}
+	if context.Zones(ctx) {
+		query = flagQuery("zones")
+	}
+
+	var reply *types.ServiceInfo
+	if err := c.invoke(ctx, "serviceInspect",
+		serviceVars(name), query, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) StorageClasses(
+	ctx types.Context, service string) (types.StorageClassMap, error) {
+
+	var reply types.StorageClassMap
+	if err := c.invoke(ctx, "serviceClasses",
+		serviceVars(service), nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) Quotas(
+	ctx types.Context) (*types.QuotaReport, error) {
+
+	var reply *types.QuotaReport
+	if err := c.invoke(ctx, "quotas", nil, nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) SubjectQuota(
+	ctx types.Context, subject string) (*types.SubjectQuota, error) {
+
+	var reply *types.SubjectQuota
+	if err := c.invoke(ctx, "quotaInspect",
+		map[string]string{"subject": subject}, nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) Volumes(
+	ctx types.Context,
+	attachments types.VolumeAttachmentsTypes) (types.ServiceVolumeMap, error) {
+
+	var reply interface{}
+	if err := c.invoke(ctx, "volumes", nil, map[string]interface{}{
+		"attachments": attachments,
+		"partial":     true,
+	}, nil, &reply); err != nil {
+		return nil, err
+	}
+	return volumeMapReply(reply)
+}
+
+func (c *embeddedClient) VolumesByService(
+	ctx types.Context,
+	service string,
+	attachments types.VolumeAttachmentsTypes) (types.VolumeMap, error) {
+
+	var reply types.VolumeMap
+	if err := c.invoke(ctx, "volumesForService",
+		serviceVars(service),
+		map[string]interface{}{"attachments": attachments},
+		nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) VolumesWatch(
+	ctx types.Context,
+	service string,
+	since int64) (*types.VolumeWatchResponse, error) {
+
+	var reply *types.VolumeWatchResponse
+	if err := c.invoke(ctx, "volumesWatch",
+		serviceVars(service),
+		map[string]interface{}{"watch": true, "since": since},
+		nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) VolumeInspect(
+	ctx types.Context,
+	service, volumeID string,
+	attachments types.VolumeAttachmentsTypes) (*types.Volume, error) {
+
+	var reply *types.Volume
+	if err := c.invoke(ctx, "volumeInspect",
+		volumeVars(service, volumeID),
+		map[string]interface{}{"attachments": attachments},
+		nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) VolumeCreate(
+	ctx types.Context,
+	service string,
+	request *types.VolumeCreateRequest) (*types.Volume, error) {
+
+	var reply *types.Volume
+	if err := c.invoke(ctx, "volumeCreate",
+		serviceVars(service), nil, request, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) VolumeCreateFromSnapshot(
+	ctx types.Context,
+	service, snapshotID string,
+	request *types.VolumeCreateRequest) (*types.Volume, error) {
+
+	var reply *types.Volume
+	if err := c.invoke(ctx, "snapshotCreate",
+		snapshotVars(service, snapshotID), flagQuery("create"),
+		request, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) VolumeCopy(
+	ctx types.Context,
+	service, volumeID string,
+	request *types.VolumeCopyRequest) (*types.Volume, error) {
+
+	var reply *types.Volume
+	if err := c.invoke(ctx, "volumeCopy",
+		volumeVars(service, volumeID), flagQuery("copy"),
+		request, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) VolumeRemove(
+	ctx types.Context,
+	service, volumeID string,
+	force bool) error {
+
+	return c.invoke(ctx, "volumeRemove",
+		volumeVars(service, volumeID),
+		map[string]interface{}{"force": force}, nil, nil)
+}
+
+func (c *embeddedClient) VolumeAttach(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeAttachRequest) (*types.Volume, string, error) {
+
+	var reply *types.VolumeAttachResponse
+	if err := c.invoke(ctx, "volumeAttach",
+		volumeVars(service, volumeID), flagQuery("attach"),
+		request, &reply); err != nil {
+		return nil, "", err
+	}
+	return reply.Volume, reply.AttachToken, nil
+}
+
+func (c *embeddedClient) VolumeDetach(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeDetachRequest) (*types.Volume, error) {
+
+	var reply *types.Volume
+	if err := c.invoke(ctx, "volumeDetach",
+		volumeVars(service, volumeID), flagQuery("detach"),
+		request, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) VolumeDetachAll(
+	ctx types.Context,
+	request *types.VolumeDetachRequest) (types.ServiceVolumeMap, error) {
+
+	var reply interface{}
+	if err := c.invoke(ctx, "volumesDetachAll",
+		nil, flagQuery("detach"), request, &reply); err != nil {
+		return nil, err
+	}
+	return volumeMapReply(reply)
+}
+
+func (c *embeddedClient) VolumeDetachAllForService(
+	ctx types.Context,
+	service string,
+	request *types.VolumeDetachRequest) (types.VolumeMap, error) {
+
+	var reply types.VolumeMap
+	if err := c.invoke(ctx, "volumesDetachForService",
+		serviceVars(service), flagQuery("detach"),
+		request, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) VolumeSnapshot(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeSnapshotRequest) (*types.Snapshot, error) {
+
+	var reply *types.Snapshot
+	if err := c.invoke(ctx, "volumeSnapshot",
+		volumeVars(service, volumeID), flagQuery("snapshot"),
+		request, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) Snapshots(
+	ctx types.Context) (types.ServiceSnapshotMap, error) {
+
+	var reply types.ServiceSnapshotMap
+	if err := c.invoke(ctx, "snapshots", nil, nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) SnapshotsByService(
+	ctx types.Context, service string) (types.SnapshotMap, error) {
+
+	var reply types.SnapshotMap
+	if err := c.invoke(ctx, "snapshotsForService",
+		serviceVars(service), nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) SnapshotInspect(
+	ctx types.Context,
+	service, snapshotID string) (*types.Snapshot, error) {
+
+	var reply *types.Snapshot
+	if err := c.invoke(ctx, "snapshotInspect",
+		snapshotVars(service, snapshotID), nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) SnapshotRemove(
+	ctx types.Context,
+	service, snapshotID string) error {
+
+	return c.invoke(ctx, "snapshotRemove",
+		snapshotVars(service, snapshotID), nil, nil, nil)
+}
+
+func (c *embeddedClient) SnapshotCopy(
+	ctx types.Context,
+	service, snapshotID string,
+	request *types.SnapshotCopyRequest) (*types.Snapshot, error) {
+
+	var reply *types.Snapshot
+	if err := c.invoke(ctx, "snapshotCopy",
+		snapshotVars(service, snapshotID), flagQuery("copy"),
+		request, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) VolumeCreateAsync(
+	ctx types.Context,
+	service string,
+	request *types.VolumeCreateRequest) (*types.Task, error) {
+
+	return c.invokeAsync(
+		ctx, "volumeCreate", serviceVars(service), nil, request)
+}
+
+func (c *embeddedClient) VolumeCreateFromSnapshotAsync(
+	ctx types.Context,
+	service, snapshotID string,
+	request *types.VolumeCreateRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, "snapshotCreate",
+		snapshotVars(service, snapshotID), flagQuery("create"), request)
+}
+
+func (c *embeddedClient) VolumeCopyAsync(
+	ctx types.Context,
+	service, volumeID string,
+	request *types.VolumeCopyRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, "volumeCopy",
+		volumeVars(service, volumeID), flagQuery("copy"), request)
+}
+
+func (c *embeddedClient) VolumeRemoveAsync(
+	ctx types.Context,
+	service, volumeID string,
+	force bool) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, "volumeRemove",
+		volumeVars(service, volumeID),
+		map[string]interface{}{"force": force}, nil)
+}
+
+func (c *embeddedClient) VolumeAttachAsync(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeAttachRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, "volumeAttach",
+		volumeVars(service, volumeID), flagQuery("attach"), request)
+}
+
+func (c *embeddedClient) VolumeDetachAsync(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeDetachRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, "volumeDetach",
+		volumeVars(service, volumeID), flagQuery("detach"), request)
+}
+
+func (c *embeddedClient) VolumeSnapshotAsync(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeSnapshotRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, "volumeSnapshot",
+		volumeVars(service, volumeID), flagQuery("snapshot"), request)
+}
+
+func (c *embeddedClient) SnapshotRemoveAsync(
+	ctx types.Context,
+	service, snapshotID string) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, "snapshotRemove",
+		snapshotVars(service, snapshotID), nil, nil)
+}
+
+func (c *embeddedClient) SnapshotCopyAsync(
+	ctx types.Context,
+	service, snapshotID string,
+	request *types.SnapshotCopyRequest) (*types.Task, error) {
+
+	return c.invokeAsync(ctx, "snapshotCopy",
+		snapshotVars(service, snapshotID), flagQuery("copy"), request)
+}
+
+func (c *embeddedClient) Tasks(
+	ctx types.Context) (map[string]*types.Task, error) {
+
+	var reply map[string]*types.Task
+	if err := c.invoke(ctx, "tasks", nil, nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	tasks := map[string]*types.Task{}
+	for id, task := range reply {
+		tasks[id] = copyTask(task)
+	}
+	return tasks, nil
+}
+
+func (c *embeddedClient) TaskInspect(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	var reply *types.Task
+	if err := c.invoke(ctx, "taskInspect",
+		taskVars(taskID), nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	return copyTask(reply), nil
+}
+
+func (c *embeddedClient) TaskCancel(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	var reply *types.Task
+	if err := c.invoke(ctx, "taskCancel",
+		taskVars(taskID), nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	return copyTask(reply), nil
+}
+
+// TaskWait inspects the task with the task route, so the task is subject to
+// the same auth as any other request, and then waits for the task to
+// complete without polling.
+func (c *embeddedClient) TaskWait(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	for {
+		task, err := c.TaskInspect(ctx, taskID)
+		if err != nil {
+			return nil, err
+		}
+		if task.State == types.TaskStateSuccess ||
+			task.State == types.TaskStateError {
+			return task, task.Error
+		}
+
+		select {
+		case <-services.TaskWaitC(c.srv.ctx, taskID):
+		case <-ctx.Done():
+			if c.cancelTasks {
+				if _, err := c.TaskCancel(
+					context.WithoutCancel(ctx), taskID); err != nil {
+					ctx.WithError(err).Warn("error cancelling task")
+				}
+			}
+			return task, ctx.Err()
+		}
+	}
+}
+
+func (c *embeddedClient) Executors(
+	ctx types.Context) (map[string]*types.ExecutorInfo, error) {
+
+	var reply types.ExecutorsMap
+	if err := c.invoke(ctx, "executors", nil, nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) executor(
+	ctx types.Context,
+	route, name string) (*executors.ExecutorInfoEx, error) {
+
+	var reply *executors.ExecutorInfoEx
+	if err := c.invoke(ctx, route,
+		map[string]string{"executor": name}, nil, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (c *embeddedClient) ExecutorHead(
+	ctx types.Context,
+	name string) (*types.ExecutorInfo, error) {
+
+	ei, err := c.executor(ctx, "executorHead", name)
+	if err != nil {
+		return nil, err
+	}
+	info := ei.ExecutorInfo
+	return &info, nil
+}
+
+func (c *embeddedClient) ExecutorGet(
+	ctx types.Context, name string) (io.ReadCloser, error) {
+
+	ei, err := c.executor(ctx, "executorInspect", name)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(ei.Data)), nil
+}
@@ -0,0 +1,367 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/akutz/goof"
+	gocontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/codedellemc/libstorage/api/rpc"
+	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// newGRPCServer returns a gRPC server that serves the libStorage gRPC service
+// for an endpoint. Each call is handled as a request for the HTTP route to
+// which the call's method maps, so the endpoint's routers, middleware, auth
+// and transaction handling all apply to gRPC calls.
+func newGRPCServer(
+	srv *HTTPServer, tlsConfig *types.TLSConfig) *grpc.Server {
+
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts,
+			grpc.Creds(credentials.NewTLS(&tlsConfig.Config)))
+	}
+
+	gs := grpc.NewServer(opts...)
+	gs.RegisterService(newGRPCServiceDesc(), &grpcService{srv})
+	return gs
+}
+
+func newGRPCServiceDesc() *grpc.ServiceDesc {
+
+	desc := &grpc.ServiceDesc{
+		ServiceName: rpc.ServiceName,
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{
+			{
+				StreamName:    rpc.TaskWatch.Name,
+				Handler:       grpcTaskWatchHandler,
+				ServerStreams: true,
+			},
+			{
+				StreamName:    rpc.ExecutorGet.Name,
+				Handler:       grpcExecutorGetHandler,
+				ServerStreams: true,
+			},
+		},
+	}
+
+	for _, m := range rpc.UnaryMethods {
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{
+			MethodName: m.Name,
+			Handler:    newGRPCUnaryHandler(m),
+		})
+	}
+
+	return desc
+}
+
+func newGRPCUnaryHandler(m *rpc.Method) func(
+	interface{},
+	gocontext.Context,
+	func(interface{}) error,
+	grpc.UnaryServerInterceptor) (interface{}, error) {
+
+	return func(
+		srv interface{},
+		ctx gocontext.Context,
+		dec func(interface{}) error,
+		interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+
+		in := &rpc.Request{}
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+
+		handler := func(
+			ctx gocontext.Context, req interface{}) (interface{}, error) {
+
+			s := srv.(*grpcService)
+			call := &grpcCall{grpcMetadata: &grpcUnaryMetadata{ctx}}
+			w, err := s.serve(
+				ctx, call, m, req.(*rpc.Request), newResponseWriter())
+			if err != nil {
+				return nil, err
+			}
+			return w.response(), nil
+		}
+
+		if interceptor == nil {
+			return handler(ctx, in)
+		}
+		return interceptor(ctx, in, &grpc.UnaryServerInfo{
+			Server:     srv,
+			FullMethod: m.FullName(),
+		}, handler)
+	}
+}
+
+// grpcTaskWatchHandler sends the task and, if the task is not complete,
+// waits for the task to complete without polling and then sends the task
+// again. The completed task is served by the task route as well, so it is
+// subject to the same auth as the first request.
+func grpcTaskWatchHandler(srv interface{}, stream grpc.ServerStream) error {
+
+	in := &rpc.Request{}
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+
+	var (
+		s    = srv.(*grpcService)
+		ctx  = stream.Context()
+		call = &grpcCall{grpcMetadata: stream}
+	)
+
+	w, err := s.serve(ctx, call, rpc.TaskWatch, in, newResponseWriter())
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(w.response()); err != nil {
+		return err
+	}
+
+	// only the ID and state are decoded since a task's error cannot be
+	// decoded into the error interface
+	task := &struct {
+		ID    int             `json:"id"`
+		State types.TaskState `json:"state"`
+	}{}
+	if err := json.Unmarshal(w.body.Bytes(), task); err != nil {
+		return err
+	}
+	if task.State == types.TaskStateSuccess ||
+		task.State == types.TaskStateError {
+		return nil
+	}
+
+	select {
+	case <-services.TaskWaitC(s.srv.ctx, task.ID):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	w, err = s.serve(ctx, call, rpc.TaskWatch, in, newResponseWriter())
+	if err != nil {
+		return err
+	}
+	return stream.SendMsg(w.response())
+}
+
+// grpcExecutorGetHandler streams the executor in chunks as the executor
+// route writes it rather than buffering the executor.
+func grpcExecutorGetHandler(srv interface{}, stream grpc.ServerStream) error {
+
+	in := &rpc.Request{}
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+
+	s := srv.(*grpcService)
+	call := &grpcCall{grpcMetadata: stream}
+	w := newResponseWriter()
+	w.call = call
+	w.stream = stream
+	_, err := s.serve(stream.Context(), call, rpc.ExecutorGet, in, w)
+	return err
+}
+
+// grpcMetadata sends the header and sets the trailer of a gRPC call.
+type grpcMetadata interface {
+	SendHeader(metadata.MD) error
+	SetTrailer(metadata.MD)
+}
+
+// grpcCall is a call to a method of the gRPC service.
+type grpcCall struct {
+	grpcMetadata
+	headerSent bool
+}
+
+// sendHeader sends the call's header, which includes the server's name.
+// Streaming methods, such as TaskWatch, may serve several requests, but the
+// header is sent only once.
+func (c *grpcCall) sendHeader(w *responseWriter) error {
+	if c.headerSent {
+		return nil
+	}
+	c.headerSent = true
+	return c.SendHeader(metadata.Pairs(
+		rpc.ServerNameMetadataKey, w.header.Get(types.ServerNameHeader)))
+}
+
+type grpcUnaryMetadata struct {
+	ctx gocontext.Context
+}
+
+func (m *grpcUnaryMetadata) SendHeader(md metadata.MD) error {
+	return grpc.SendHeader(m.ctx, md)
+}
+
+func (m *grpcUnaryMetadata) SetTrailer(md metadata.MD) {
+	grpc.SetTrailer(m.ctx, md)
+}
+
+type grpcService struct {
+	srv *HTTPServer
+}
+
+// serve handles a call to a method of the gRPC service as a request for the
+// HTTP route to which the method maps and records the response with the
+// provided writer. If the route returns an error then a gRPC error is
+// returned and the JSON encoded API error is set in the call's trailer.
+func (s *grpcService) serve(
+	ctx gocontext.Context,
+	call *grpcCall,
+	m *rpc.Method,
+	in *rpc.Request,
+	w *responseWriter) (*responseWriter, error) {
+
+	req, err := newGRPCHTTPRequest(ctx, m, in)
+	if err != nil {
+		return nil, err
+	}
+
+	s.srv.srv.Handler.ServeHTTP(w, req)
+
+	if err := call.sendHeader(w); err != nil {
+		s.srv.ctx.WithError(err).Warn("error sending grpc header")
+	}
+
+	if w.status > 299 {
+		call.SetTrailer(metadata.Pairs(
+			rpc.ErrorMetadataKey, w.body.String()))
+		msg := http.StatusText(w.status)
+		if httpErr, err := goof.DecodeHTTPError(
+			bytes.NewReader(w.body.Bytes())); err == nil {
+			msg = httpErr.Error()
+		}
+		return nil, grpc.Errorf(rpc.Code(w.status), "%s", msg)
+	}
+
+	return w, nil
+}
+
+// newGRPCHTTPRequest returns the HTTP request for a call to a method of the
+// gRPC service. The call's metadata become the request's headers.
+func newGRPCHTTPRequest(
+	ctx gocontext.Context,
+	m *rpc.Method,
+	in *rpc.Request) (*http.Request, error) {
+
+	req, err := http.NewRequest(
+		m.HTTPMethod, m.URL(in).String(), bytes.NewReader(in.JSON))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	if md, ok := metadata.FromContext(ctx); ok {
+		for k, v := range md {
+			if strings.HasPrefix(k, ":") ||
+				strings.HasPrefix(k, "grpc-") ||
+				k == "content-type" ||
+				k == "accept" ||
+				k == "user-agent" ||
+				k == "te" {
+				continue
+			}
+			k = http.CanonicalHeaderKey(k)
+			req.Header[k] = append(req.Header[k], v...)
+		}
+	}
+
+	req.Header.Set("Accept", types.ContentTypeJSON.String())
+	if len(in.JSON) > 0 {
+		req.Header.Set("Content-Type", types.ContentTypeJSON.String())
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		req.RemoteAddr = p.Addr.String()
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			req.TLS = &tlsInfo.State
+		}
+	}
+
+	return req, nil
+}
+
+// responseWriter records the response to a gRPC call. If the writer has a
+// stream then the body of a successful response is sent to the stream in
+// chunks rather than recorded.
+type responseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	call   *grpcCall
+	stream grpc.ServerStream
+}
+
+func newResponseWriter() *responseWriter {
+	return &responseWriter{header: http.Header{}}
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	// an error is recorded so it can be returned as the call's error
+	if w.stream == nil || w.status > 299 {
+		return w.body.Write(p)
+	}
+
+	// the header must be sent before the first chunk
+	if err := w.call.sendHeader(w); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for len(p) > 0 {
+		size := len(p)
+		if size > rpc.ChunkSize {
+			size = rpc.ChunkSize
+		}
+		if err := w.stream.SendMsg(&rpc.Chunk{Data: p[:size]}); err != nil {
+			return n, err
+		}
+		n += size
+		p = p[size:]
+	}
+	return n, nil
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// response returns the response of a unary method or the message streamed
+// by TaskWatch.
+func (w *responseWriter) response() *rpc.Response {
+	res := &rpc.Response{JSON: w.body.Bytes()}
+	for k, v := range w.header {
+		if len(v) == 0 || k == types.ServerNameHeader {
+			continue
+		}
+		if res.Headers == nil {
+			res.Headers = map[string]string{}
+		}
+		res.Headers[k] = v[0]
+	}
+	return res
+}
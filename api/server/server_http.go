@@ -16,6 +16,7 @@ import (
 	"github.com/akutz/goof"
 	"github.com/akutz/gotil"
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
 
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
@@ -77,9 +78,20 @@ func (s *server) initEndpoints(ctx types.Context) error {
 		}
 	}
 
+	embedded := s.config.GetBool(types.ConfigEmbedded)
+
 	for endpointName := range endpoints {
 
 		endpoint := fmt.Sprintf("%s.%s", types.ConfigEndpoints, endpointName)
+
+		if embedded {
+			if err := s.initEmbeddedEndpoint(
+				ctx, endpointName, endpoint); err != nil {
+				return err
+			}
+			continue
+		}
+
 		address := fmt.Sprintf("%s.address", endpoint)
 		laddr := s.config.GetString(address)
 		if laddr == "" {
@@ -108,6 +120,19 @@ func (s *server) initEndpoints(ctx types.Context) error {
 			s.ctx.WithField("endpoint", endpoint).Info(
 				"initializing auto unix endpoint")
 
+		case types.GRPCEndpoint:
+
+			var tcpPort int
+			func() {
+				tcpPortLock.Lock()
+				defer tcpPortLock.Unlock()
+				tcpPort = gotil.RandomTCPPort()
+			}()
+
+			laddr = fmt.Sprintf("grpc://127.0.0.1:%d", tcpPort)
+			s.ctx.WithField("endpoint", endpoint).Info(
+				"initializing auto grpc endpoint")
+
 		}
 
 		s.ctx.WithFields(log.Fields{
@@ -115,7 +140,7 @@ func (s *server) initEndpoints(ctx types.Context) error {
 
 		s.addrs = append(s.addrs, laddr)
 
-		proto, addr, err := gotil.ParseAddress(laddr)
+		proto, addr, err := utils.ParseAddress(laddr)
 		if err != nil {
 			return err
 		}
@@ -140,13 +165,36 @@ func (s *server) initEndpoints(ctx types.Context) error {
 			}
 		}
 
+		ep, err := s.parseEndpoint(
+			endpointName, endpoint, strings.EqualFold(proto, "unix"), logFields)
+		if err != nil {
+			return err
+		}
+
+		logConfig, err := utils.ParseLoggingConfig(
+			s.config, logFields, endpoint, types.ConfigServer)
+		if err != nil {
+			return err
+		}
+
 		ctx.WithFields(logFields).Info("configured endpoint")
 
-		srv, err := s.newHTTPServer(proto, addr, tlsConfig)
+		srv, err := s.newHTTPServer(proto, addr, tlsConfig, ep)
 		if err != nil {
 			return err
 		}
 
+		context.SetLogLevel(srv.ctx, logConfig.Level)
+		if logConfig.HTTPRequests || logConfig.HTTPResponses {
+			srv.logHTTPEnabled = true
+			srv.logHTTPRequests = logConfig.HTTPRequests
+			srv.logHTTPResponses = logConfig.HTTPResponses
+			srv.stdOut = getLogIO(
+				srv.ctx, logConfig.Stdout, types.ConfigLogStdout)
+			srv.stdErr = getLogIO(
+				srv.ctx, logConfig.Stderr, types.ConfigLogStderr)
+		}
+
 		ctx.Info("server created")
 		s.servers = append(s.servers, srv)
 	}
@@ -154,6 +202,94 @@ func (s *server) initEndpoints(ctx types.Context) error {
 	return nil
 }
 
+// initEmbeddedEndpoint initializes an endpoint of an embedded server. The
+// endpoint does not listen for requests, but the services it exposes and its
+// auth and logging configurations apply to the requests of the server's
+// in-process API client.
+func (s *server) initEmbeddedEndpoint(
+	ctx types.Context, name, endpoint string) error {
+
+	logFields := map[string]interface{}{
+		"endpoint": name,
+		"embedded": true,
+	}
+
+	ep, err := s.parseEndpoint(name, endpoint, true, logFields)
+	if err != nil {
+		return err
+	}
+
+	logConfig, err := utils.ParseLoggingConfig(
+		s.config, logFields, endpoint, types.ConfigServer)
+	if err != nil {
+		return err
+	}
+
+	ctx.WithFields(logFields).Info("configured endpoint")
+
+	srvCtx := s.ctx.WithValue(context.HostKey, embeddedHost)
+	srvCtx = srvCtx.WithValue(context.TLSKey, false)
+	srvCtx = srvCtx.WithValue(context.EndpointKey, ep)
+
+	srv := &HTTPServer{
+		srv:      &http.Server{},
+		ctx:      srvCtx,
+		endpoint: ep,
+	}
+	context.SetLogLevel(srv.ctx, logConfig.Level)
+
+	ctx.Info("server created")
+	s.servers = append(s.servers, srv)
+	return nil
+}
+
+// parseEndpoint parses the services an endpoint exposes and the endpoint's
+// auth configuration. An endpoint's auth properties take precedence over the
+// server's auth properties. Auth may only be disabled for local endpoints, ex.
+// UNIX sockets, since otherwise the API is exposed to the network
+// unauthenticated.
+func (s *server) parseEndpoint(
+	name, endpoint string,
+	local bool,
+	logFields log.Fields) (*types.Endpoint, error) {
+
+	ep := &types.Endpoint{Name: name}
+
+	for _, svc := range s.config.GetStringSlice(fmt.Sprintf(
+		"%s.%s", endpoint, types.ConfigEndpointServices)) {
+		ep.Services = append(ep.Services, strings.ToLower(svc))
+	}
+	if len(ep.Services) > 0 {
+		logFields["services"] = ep.Services
+	}
+
+	authConfig, err := utils.ParseAuthConfig(
+		s.ctx, s.config, logFields, endpoint, types.ConfigServer)
+	if err != nil {
+		return nil, err
+	}
+	ep.Auth = authConfig
+
+	// an endpoint may disable auth even if no allow or deny lists are
+	// configured, in which case the storage services' auth is disabled too
+	if ep.Auth == nil && s.config.GetBool(strings.Replace(
+		types.ConfigServerAuthDisabled,
+		types.ConfigServer, endpoint, 1)) {
+		ep.Auth = &types.AuthConfig{Disabled: true}
+	}
+	if ep.AuthDisabled() {
+		if !local {
+			return nil, goof.WithFields(goof.Fields{
+				"endpoint": name,
+				"address":  logFields["address"],
+			}, "auth may only be disabled for unix socket endpoints")
+		}
+		logFields["authDisabled"] = true
+	}
+
+	return ep, nil
+}
+
 func (s *server) initRouters() error {
 	for r := range registry.Routers() {
 		r.Init(s.config)
@@ -176,13 +312,27 @@ func (s *server) addRouter(r types.Router) {
 
 func (s *server) makeHTTPHandler(
 	ctx types.Context,
+	srv *HTTPServer,
 	route types.Route) http.HandlerFunc {
 
 	return func(w http.ResponseWriter, req *http.Request) {
 
 		w.Header().Set(types.ServerNameHeader, s.name)
 
-		ctx := context.WithRequestRoute(ctx, req, route)
+		// cancel the request's context when the client cancels the request
+		// or closes the connection. tasks are not cancelled along with the
+		// request unless the client asks for it
+		reqCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			select {
+			case <-req.Context().Done():
+				cancel()
+			case <-reqCtx.Done():
+			}
+		}()
+
+		ctx := context.WithRequestRoute(reqCtx, req, route)
 
 		if req.TLS != nil {
 			if len(req.TLS.PeerCertificates) > 0 {
@@ -199,7 +349,7 @@ func (s *server) makeHTTPHandler(
 		}
 		store := utils.NewStoreWithVars(vars)
 
-		handlerFunc := s.handleWithMiddleware(ctx, route)
+		handlerFunc := s.handleWithMiddleware(ctx, srv.globalHandlers, route)
 		if err := handlerFunc(ctx, w, req, store); err != nil {
 			ctx.Error(err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -207,14 +357,14 @@ func (s *server) makeHTTPHandler(
 	}
 }
 
-func (s *server) createMux(ctx types.Context) *mux.Router {
+func (s *server) createMux(srv *HTTPServer) *mux.Router {
 	m := mux.NewRouter()
 	for _, apiRouter := range s.routers {
 		for _, r := range apiRouter.Routes() {
 
-			ctx := ctx.WithValue(context.RouteKey, r)
+			ctx := srv.ctx.WithValue(context.RouteKey, r)
 
-			f := s.makeHTTPHandler(ctx, r)
+			f := s.makeHTTPHandler(ctx, srv, r)
 			mr := m.Path(r.GetPath())
 			mr = mr.Name(r.GetName())
 			mr = mr.Methods(r.GetMethod())
@@ -237,14 +387,21 @@ func (s *server) createMux(ctx types.Context) *mux.Router {
 }
 
 func (s *server) newHTTPServer(
-	proto, laddr string, tlsConfig *types.TLSConfig) (*HTTPServer, error) {
+	proto, laddr string,
+	tlsConfig *types.TLSConfig,
+	ep *types.Endpoint) (*HTTPServer, error) {
 
 	var (
-		l   net.Listener
-		err error
+		l      net.Listener
+		err    error
+		isGRPC = types.ParseEndpointType(proto) == types.GRPCEndpoint
 	)
 
-	if tlsConfig != nil {
+	// gRPC endpoints listen on TCP and handle TLS with the gRPC server's
+	// transport credentials
+	if isGRPC {
+		l, err = net.Listen("tcp", laddr)
+	} else if tlsConfig != nil {
 		l, err = tls.Listen(proto, laddr, &tlsConfig.Config)
 	} else {
 		l, err = net.Listen(proto, laddr)
@@ -257,6 +414,7 @@ func (s *server) newHTTPServer(
 	host := fmt.Sprintf("%s://%s", proto, laddr)
 	ctx := s.ctx.WithValue(context.HostKey, host)
 	ctx = ctx.WithValue(context.TLSKey, tlsConfig != nil)
+	ctx = ctx.WithValue(context.EndpointKey, ep)
 
 	logger := ctx.Value(context.LoggerKey).(*log.Logger)
 	errLogger := &httpServerErrLogger{logger}
@@ -264,11 +422,17 @@ func (s *server) newHTTPServer(
 	srv := &http.Server{Addr: l.Addr().String()}
 	srv.ErrorLog = golog.New(errLogger, "", 0)
 
-	return &HTTPServer{
-		srv: srv,
-		l:   l,
-		ctx: ctx,
-	}, nil
+	hs := &HTTPServer{
+		srv:      srv,
+		l:        l,
+		ctx:      ctx,
+		endpoint: ep,
+	}
+	if isGRPC {
+		hs.grpc = newGRPCServer(hs, tlsConfig)
+	}
+
+	return hs, nil
 }
 
 // HTTPServer contains an instance of http server and the listener.
@@ -278,19 +442,54 @@ func (s *server) newHTTPServer(
 //
 // l   net.Listener, is a TCP or Socket listener that dispatches incoming
 // request to the router.
+//
+// endpoint *types.Endpoint, is the configuration of the endpoint, such as
+// the services it exposes and its auth configuration.
+//
+// grpc *grpc.Server, serves the gRPC API for grpc:// endpoints. The gRPC
+// calls are handled by srv's router.
+//
+// The listener of an embedded server's endpoint is nil. The requests of the
+// server's in-process API client are handled with the embeddedHandlers
+// rather than the globalHandlers.
 type HTTPServer struct {
-	srv *http.Server
-	l   net.Listener
-	ctx types.Context
+	srv      *http.Server
+	l        net.Listener
+	ctx      types.Context
+	endpoint *types.Endpoint
+	grpc     *grpc.Server
+
+	globalHandlers   []types.Middleware
+	embeddedHandlers []types.Middleware
+
+	logHTTPEnabled   bool
+	logHTTPRequests  bool
+	logHTTPResponses bool
+
+	stdOut io.WriteCloser
+	stdErr io.WriteCloser
 }
 
 // Serve starts listening for inbound requests.
 func (s *HTTPServer) Serve() error {
+	if s.l == nil {
+		return nil
+	}
+	if s.grpc != nil {
+		return s.grpc.Serve(s.l)
+	}
 	return s.srv.Serve(s.l)
 }
 
 // Close closes the HTTPServer from listening for the inbound requests.
 func (s *HTTPServer) Close() error {
+	if s.l == nil {
+		return nil
+	}
+	if s.grpc != nil {
+		s.grpc.Stop()
+		return nil
+	}
 	return s.l.Close()
 }
 
@@ -6,22 +6,49 @@ import (
 	"github.com/codedellemc/libstorage/api/types"
 )
 
-func (s *server) initGlobalMiddleware() {
-
-	s.addGlobalMiddleware(handlers.NewQueryParamsHandler())
-	if s.logHTTPEnabled {
-		s.addGlobalMiddleware(handlers.NewLoggingHandler(
-			s.stdOut,
-			s.logHTTPRequests,
-			s.logHTTPResponses))
+// initGlobalMiddleware initializes the global middleware of an endpoint.
+// Each endpoint has its own global middleware so that the endpoint's logging
+// and auth configurations are used for the requests it receives.
+//
+// The requests of the embedded API client are handled without the middleware
+// that decodes query parameters, logs HTTP messages, negotiates the content
+// type and encodes errors since those requests are not HTTP messages.
+func (s *server) initGlobalMiddleware(srv *HTTPServer) {
+
+	var (
+		transaction  = handlers.NewTransactionHandler()
+		authGlobal   = handlers.NewAuthGlobalHandler(srv.endpoint.Auth)
+		debug        = handlers.NewDebugHandler()
+		localDevices = handlers.NewLocalDevicesHandler()
+		onRequest    = handlers.NewOnRequestHandler()
+		instanceID   = handlers.NewInstanceIDHandler(
+			services.StorageServices(s.ctx))
+	)
+
+	srv.addGlobalMiddleware(handlers.NewQueryParamsHandler())
+	if srv.logHTTPEnabled {
+		srv.addGlobalMiddleware(handlers.NewLoggingHandler(
+			srv.stdOut,
+			srv.logHTTPRequests,
+			srv.logHTTPResponses))
+	}
+	srv.addGlobalMiddleware(handlers.NewContentTypeHandler())
+	srv.addGlobalMiddleware(transaction)
+	srv.addGlobalMiddleware(handlers.NewErrorHandler())
+	srv.addGlobalMiddleware(authGlobal)
+	srv.addGlobalMiddleware(debug)
+	srv.addGlobalMiddleware(instanceID)
+	srv.addGlobalMiddleware(localDevices)
+	srv.addGlobalMiddleware(onRequest)
+
+	srv.embeddedHandlers = []types.Middleware{
+		transaction,
+		authGlobal,
+		debug,
+		instanceID,
+		localDevices,
+		onRequest,
 	}
-	s.addGlobalMiddleware(handlers.NewTransactionHandler())
-	s.addGlobalMiddleware(handlers.NewErrorHandler())
-	s.addGlobalMiddleware(handlers.NewAuthGlobalHandler(s.authConfig))
-	s.addGlobalMiddleware(
-		handlers.NewInstanceIDHandler(services.StorageServices(s.ctx)))
-	s.addGlobalMiddleware(handlers.NewLocalDevicesHandler())
-	s.addGlobalMiddleware(handlers.NewOnRequestHandler())
 }
 
 func (s *server) initRouteMiddleware() {
@@ -47,12 +74,13 @@ func (s *server) addRouterMiddleware(
 	s.routeHandlers[r.GetName()] = middlewaresForRouteName
 }
 
-func (s *server) addGlobalMiddleware(m types.Middleware) {
+func (s *HTTPServer) addGlobalMiddleware(m types.Middleware) {
 	s.globalHandlers = append(s.globalHandlers, m)
 }
 
 func (s *server) handleWithMiddleware(
 	ctx types.Context,
+	globalHandlers []types.Middleware,
 	route types.Route) types.APIFunc {
 
 	/*if route.GetMethod() == "HEAD" {
@@ -73,7 +101,7 @@ func (s *server) handleWithMiddleware(
 	}
 
 	// add the global handlers
-	for h := range reverse(s.globalHandlers) {
+	for h := range reverse(globalHandlers) {
 		handler = h.Handler(handler)
 		ctx.WithField(
 			"middleware", h.Name()).Debug("added global middleware")
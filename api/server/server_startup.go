@@ -12,6 +12,7 @@ import (
 	"github.com/codedellemc/libstorage/api"
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/server/services"
+	"github.com/codedellemc/libstorage/api/utils/secrets"
 )
 
 var (
@@ -70,7 +71,8 @@ func (s *server) PrintServerStartupHeader(w io.Writer) {
 	fmt.Fprint(b, strings.Repeat(" ", trunc80(n)))
 	fmt.Fprintln(b, "##")
 
-	n, _ = fmt.Fprintf(b, "##      token:      %s", s.adminToken)
+	n, _ = fmt.Fprintf(
+		b, "##      token:      %s", secrets.MaskString(s.adminToken))
 	fmt.Fprint(b, strings.Repeat(" ", trunc80(n)))
 	fmt.Fprintln(b, "##")
 
@@ -5,11 +5,13 @@ import (
 	"strings"
 	"sync"
 
+	log "github.com/Sirupsen/logrus"
 	gofig "github.com/akutz/gofig/types"
 	"github.com/akutz/goof"
 
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
 )
 
 var (
@@ -18,9 +20,12 @@ var (
 )
 
 type serviceContainer struct {
+	ctx             types.Context
 	config          gofig.Config
 	storageServices map[string]types.StorageService
 	taskService     *globalTaskService
+	quotaService    *quotaService
+	policyService   *policyService
 }
 
 // Init initializes the types.
@@ -34,7 +39,10 @@ func Init(ctx types.Context, config gofig.Config) error {
 	ctx.Info("initializing server services")
 
 	sc := &serviceContainer{
+		ctx:             ctx,
 		taskService:     &globalTaskService{name: "global-task-service"},
+		quotaService:    &quotaService{},
+		policyService:   &policyService{},
 		storageServices: map[string]types.StorageService{},
 	}
 
@@ -60,6 +68,41 @@ func (sc *serviceContainer) Init(ctx types.Context, config gofig.Config) error {
 		return err
 	}
 
+	if err := sc.checkNamespaces(ctx); err != nil {
+		return err
+	}
+
+	if err := sc.quotaService.Init(ctx, config); err != nil {
+		return err
+	}
+	if err := sc.quotaService.checkLabels(
+		ctx, sc.storageServices); err != nil {
+		return err
+	}
+
+	if err := sc.policyService.Init(ctx, config); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkNamespaces returns an error if namespaces are enabled and the driver
+// of a service does not persist the namespaces of the volumes and snapshots
+// it creates, since they would otherwise be visible to every namespace.
+func (sc *serviceContainer) checkNamespaces(ctx types.Context) error {
+	if !sc.config.GetBool(types.ConfigServerNamespacesEnabled) {
+		return nil
+	}
+	for name, svc := range sc.storageServices {
+		ctx := context.WithStorageService(ctx, svc)
+		if !utils.PersistsLabels(ctx, svc.Driver()) {
+			return goof.WithFields(goof.Fields{
+				"service": name,
+				"driver":  svc.Driver().Name(),
+			}, "namespaces require a driver that persists labels")
+		}
+	}
 	return nil
 }
 
@@ -75,29 +118,72 @@ func getStorageServices(
 }
 
 // GetStorageService returns the storage service specified by the given name;
-// otherwise a nil value is returned if no such service exists.
+// otherwise a nil value is returned if no such service exists or if the
+// service is not exposed by the endpoint that received the request.
 func GetStorageService(
 	ctx types.Context, name string) types.StorageService {
 	servicesByServerRWL.RLock()
 	defer servicesByServerRWL.RUnlock()
 	name = strings.ToLower(name)
 	ctx.WithField("service", name).Debug("getting storage service")
+	if !endpointHasService(ctx, name) {
+		return nil
+	}
 	return getStorageServices(ctx)[name]
 }
 
-// StorageServices returns a channel on which all the storage services are
-// received.
+// StorageServices returns a channel on which all the storage services
+// exposed by the endpoint that received the request are received.
 func StorageServices(ctx types.Context) <-chan types.StorageService {
 	c := make(chan types.StorageService)
 	go func() {
-		for _, v := range getStorageServices(ctx) {
-			c <- v
+		for k, v := range getStorageServices(ctx) {
+			if endpointHasService(ctx, k) {
+				c <- v
+			}
 		}
 		close(c)
 	}()
 	return c
 }
 
+func endpointHasService(ctx types.Context, name string) bool {
+	if ep, ok := context.Endpoint(ctx); ok {
+		return ep.HasService(name)
+	}
+	return true
+}
+
+// StorageServiceState returns the runtime state of the storage service
+// specified by the given name; otherwise a nil value is returned if no such
+// service exists.
+func StorageServiceState(
+	ctx types.Context, name string) *types.ServiceState {
+
+	if svc, ok := GetStorageService(ctx, name).(*storageService); ok {
+		return svc.State()
+	}
+	return nil
+}
+
+// SetStorageServiceLogLevel sets the log level override for the storage
+// service specified by the given name. A nil level removes the override.
+func SetStorageServiceLogLevel(
+	ctx types.Context, name string, lvl *log.Level) error {
+
+	svc, ok := GetStorageService(ctx, name).(*storageService)
+	if !ok {
+		return goof.WithField("service", name, "unknown service")
+	}
+	svc.SetLogLevel(lvl)
+	fields := log.Fields{"service": name}
+	if lvl != nil {
+		fields["logLevel"] = lvl.String()
+	}
+	ctx.WithFields(fields).Info("set service log level")
+	return nil
+}
+
 func (sc *serviceContainer) initStorageServices(ctx types.Context) error {
 	if ctx == nil {
 		panic("ctx is nil")
@@ -163,6 +249,19 @@ func getTaskService(ctx types.Context) *globalTaskService {
 	return servicesByServer[serverName].taskService
 }
 
+// ServerContext returns the context of the server that received the request.
+func ServerContext(ctx types.Context) types.Context {
+
+	serverName, ok := context.Server(ctx)
+	if !ok {
+		panic("ctx is missing ServerName")
+	}
+
+	servicesByServerRWL.RLock()
+	defer servicesByServerRWL.RUnlock()
+	return servicesByServer[serverName].ctx
+}
+
 // Tasks returns a channel on which all tasks are received.
 func Tasks(ctx types.Context) <-chan *types.Task {
 	return getTaskService(ctx).Tasks()
@@ -186,6 +285,39 @@ func TaskInspect(ctx types.Context, taskID int) *types.Task {
 	return getTaskService(ctx).TaskInspect(taskID)
 }
 
+// TaskCancel cancels the task with the specified ID and returns the task,
+// or nil if there is no such task.
+func TaskCancel(ctx types.Context, taskID int) *types.Task {
+	return getTaskService(ctx).TaskCancel(ctx, taskID)
+}
+
+// TaskService returns the name of the storage service on which the specified
+// task operates, or an empty string if the task does not operate on a single
+// service.
+func TaskService(ctx types.Context, taskID int) string {
+	return getTaskService(ctx).TaskService(taskID)
+}
+
+// TaskNamespace returns the namespace to which the request that created the
+// specified task was scoped. The returned flag is false if there is no such
+// task or if the request was not scoped to a namespace.
+func TaskNamespace(ctx types.Context, taskID int) (string, bool) {
+	return getTaskService(ctx).TaskNamespace(taskID)
+}
+
+// RequestUser returns the user that sent a request, which is the subject of
+// the request's security token or the name of the request's TLS client. An
+// empty string is returned if the request is anonymous.
+func RequestUser(ctx types.Context) string {
+	if tok, ok := context.AuthToken(ctx); ok {
+		return tok.Subject
+	}
+	if v, ok := ctx.Value(context.UserKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
 // TaskWait blocks until the specified task is completed.
 func TaskWait(ctx types.Context, taskID int) {
 	getTaskService(ctx).TaskWait(taskID)
@@ -197,6 +329,12 @@ func TaskWaitC(ctx types.Context, taskID int) <-chan int {
 	return getTaskService(ctx).TaskWaitC(taskID)
 }
 
+// TaskPurge removes all completed tasks and returns the number of tasks that
+// were removed.
+func TaskPurge(ctx types.Context) int {
+	return getTaskService(ctx).TaskPurge(ctx)
+}
+
 // TaskWaitAll blocks until all the specified task are complete.
 func TaskWaitAll(ctx types.Context, taskIDs ...int) {
 	getTaskService(ctx).TaskWaitAll(taskIDs...)
@@ -207,3 +345,9 @@ func TaskWaitAll(ctx types.Context, taskIDs ...int) {
 func TaskWaitAllC(ctx types.Context, taskIDs ...int) <-chan int {
 	return getTaskService(ctx).TaskWaitAllC(taskIDs...)
 }
+
+// TaskFields adds the provided fields to the fields of the task with which
+// the context is associated, if any.
+func TaskFields(ctx types.Context, fields map[string]string) {
+	getTaskService(ctx).TaskFields(ctx, fields)
+}
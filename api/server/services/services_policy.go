@@ -0,0 +1,331 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/api/utils/policy"
+)
+
+type policyService struct {
+	policy   *policy.Policy
+	endpoint string
+	client   *http.Client
+	failOpen bool
+}
+
+func (s *policyService) Init(ctx types.Context, config gofig.Config) error {
+
+	if f := config.GetString(types.ConfigServerPolicyFile); f != "" {
+		p, err := policy.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		s.policy = p
+		ctx.WithFields(map[string]interface{}{
+			"file":  f,
+			"rules": p.Len(),
+		}).Info("configured policy rules")
+	}
+
+	if ep := config.GetString(types.ConfigServerPolicyEndpoint); ep != "" {
+		timeout, err := time.ParseDuration(
+			config.GetString(types.ConfigServerPolicyTimeout))
+		if err != nil {
+			timeout = time.Duration(time.Second * 10)
+		}
+		s.endpoint = ep
+		s.client = &http.Client{Timeout: timeout}
+		s.failOpen = config.GetBool(types.ConfigServerPolicyFailOpen)
+		ctx.WithFields(map[string]interface{}{
+			"endpoint": ep,
+			"timeout":  timeout,
+			"failOpen": s.failOpen,
+		}).Info("configured policy endpoint")
+	}
+
+	return nil
+}
+
+func getPolicyService(ctx types.Context) *policyService {
+
+	serverName, ok := context.Server(ctx)
+	if !ok {
+		panic("ctx is missing ServerName")
+	}
+
+	servicesByServerRWL.RLock()
+	defer servicesByServerRWL.RUnlock()
+	return servicesByServer[serverName].policyService
+}
+
+// PolicyCheck evaluates the server's policy for a mutating operation of the
+// provided service. The decision is recorded in the fields of the request's
+// task. If the operation is denied an *types.ErrPolicyDenied error is
+// returned. Otherwise the request fields the policy sets are set in the
+// store and, if not nil, the options for creating a volume, and the custom
+// fields the policy adds are set in the store if the request does not
+// already specify them.
+func PolicyCheck(
+	ctx types.Context,
+	svc types.StorageService,
+	op string,
+	store types.Store,
+	opts *types.VolumeCreateOpts) error {
+
+	s := getPolicyService(ctx)
+	if s.policy == nil && s.endpoint == "" {
+		return nil
+	}
+
+	in, err := newPolicyInput(ctx, svc, op, store, opts)
+	if err != nil {
+		return err
+	}
+
+	d, err := s.evaluate(ctx, in)
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]string{"policy": string(types.PolicyActionAllow)}
+	if !d.Allow {
+		fields["policy"] = string(types.PolicyActionDeny)
+	}
+	if d.Rule != "" {
+		fields["policyRule"] = d.Rule
+	}
+	if d.Reason != "" {
+		fields["policyReason"] = d.Reason
+	}
+	if len(d.Mutators) > 0 {
+		fields["policyMutators"] = strings.Join(d.Mutators, ",")
+	}
+	TaskFields(ctx, fields)
+
+	lf := map[string]interface{}{"operation": op}
+	for k, v := range fields {
+		lf[k] = v
+	}
+	if !d.Allow {
+		ctx.WithFields(lf).Warn("denied by policy")
+		return utils.NewPolicyDeniedErr(op, d.Rule, d.Reason)
+	}
+	ctx.WithFields(lf).Debug("allowed by policy")
+
+	for k, v := range d.Set {
+		if i, ok := policy.Int64(v); ok && isIntOpt(k) {
+			v = i
+		}
+		store.Set(k, v)
+		if opts != nil {
+			setVolumeCreateOpt(opts, store, k)
+		}
+	}
+	if len(d.Fields) > 0 {
+		ropts := store.GetStore("opts")
+		for k, v := range d.Fields {
+			if ropts == nil || !ropts.IsSet(k) {
+				utils.SetRequestField(store, k, v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isIntOpt returns a flag indicating whether or not the request field with
+// the provided name is an integer.
+func isIntOpt(k string) bool {
+	switch strings.ToLower(k) {
+	case "size", "iops":
+		return true
+	}
+	return false
+}
+
+func setVolumeCreateOpt(
+	opts *types.VolumeCreateOpts, store types.Store, k string) {
+
+	switch strings.ToLower(k) {
+	case "size":
+		opts.Size = store.GetInt64Ptr(k)
+	case "iops":
+		opts.IOPS = store.GetInt64Ptr(k)
+	case "type":
+		opts.Type = store.GetStringPtr(k)
+	case "encrypted":
+		opts.Encrypted = store.GetBoolPtr(k)
+	case "availabilityzone":
+		opts.AvailabilityZone = store.GetStringPtr(k)
+	}
+}
+
+// evaluate evaluates the policy rules and then, unless the rules deny the
+// operation, the policy endpoint, which observes the changes to the request
+// made by the rules
+func (s *policyService) evaluate(
+	ctx types.Context,
+	in *types.PolicyInput) (*types.PolicyDecision, error) {
+
+	d := &types.PolicyDecision{Allow: true}
+
+	if s.policy != nil {
+		var err error
+		if d, err = s.policy.Evaluate(in); err != nil {
+			return nil, err
+		}
+		if !d.Allow || s.endpoint == "" {
+			return d, nil
+		}
+		for k, v := range d.Set {
+			in.Request[strings.ToLower(k)] = v
+		}
+	}
+
+	ed, err := s.post(in)
+	if err != nil {
+		if !s.failOpen {
+			return nil, err
+		}
+		ctx.WithError(err).Warn("ignoring policy endpoint error")
+		return d, nil
+	}
+
+	d.Allow = ed.Allow
+	if ed.Rule != "" || ed.Reason != "" || !ed.Allow {
+		d.Rule = ed.Rule
+		d.Reason = ed.Reason
+	}
+	for k, v := range ed.Set {
+		if d.Set == nil {
+			d.Set = map[string]interface{}{}
+		}
+		d.Set[k] = v
+	}
+	for k, v := range ed.Fields {
+		if d.Fields == nil {
+			d.Fields = map[string]string{}
+		}
+		if _, ok := d.Fields[k]; !ok {
+			d.Fields[k] = v
+		}
+	}
+	d.Mutators = append(d.Mutators, ed.Mutators...)
+
+	return d, nil
+}
+
+func (s *policyService) post(
+	in *types.PolicyInput) (*types.PolicyDecision, error) {
+
+	buf, err := json.Marshal(in)
+	if err != nil {
+		return nil, goof.WithError("error encoding policy input", err)
+	}
+
+	res, err := s.client.Post(
+		s.endpoint, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"endpoint", s.endpoint, "error calling policy endpoint", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, goof.WithFields(goof.Fields{
+			"endpoint": s.endpoint,
+			"status":   res.StatusCode,
+		}, "policy endpoint error")
+	}
+
+	d := &types.PolicyDecision{}
+	if err := json.NewDecoder(res.Body).Decode(d); err != nil {
+		return nil, goof.WithFieldE(
+			"endpoint", s.endpoint, "invalid policy decision", err)
+	}
+	return d, nil
+}
+
+func newPolicyInput(
+	ctx types.Context,
+	svc types.StorageService,
+	op string,
+	store types.Store,
+	opts *types.VolumeCreateOpts) (*types.PolicyInput, error) {
+
+	in := &types.PolicyInput{
+		Operation: op,
+		Service:   svc.Name(),
+		Driver:    svc.Driver().Name(),
+		Request:   map[string]interface{}{},
+	}
+	if tok, ok := context.AuthToken(ctx); ok {
+		in.Subject = tok.Subject
+		in.Roles = tok.Roles
+	}
+	if ns, ok := context.Namespace(ctx); ok {
+		in.Namespace = ns
+	}
+	if iid, ok := context.InstanceID(ctx); ok {
+		in.Instance = iid
+	}
+
+	for k, v := range store.Map() {
+		// encryption keys are never disclosed to the policy
+		if strings.EqualFold(k, "encryptionKey") {
+			continue
+		}
+		if vs, ok := v.(types.Store); ok {
+			v = vs.Map()
+		}
+		in.Request[k] = v
+	}
+
+	// the options for creating a volume include the defaults of the
+	// volume's storage class and availability zone
+	if opts != nil {
+		if opts.Size != nil {
+			in.Request["size"] = *opts.Size
+		}
+		if opts.IOPS != nil {
+			in.Request["iops"] = *opts.IOPS
+		}
+		if opts.Type != nil {
+			in.Request["type"] = *opts.Type
+		}
+		if opts.Encrypted != nil {
+			in.Request["encrypted"] = *opts.Encrypted
+		}
+		if opts.AvailabilityZone != nil {
+			in.Request["availabilityzone"] = *opts.AvailabilityZone
+		}
+	}
+
+	if id := store.GetString("volumeID"); id != "" {
+		v, err := svc.Driver().VolumeInspect(
+			ctx, id, &types.VolumeInspectOpts{Opts: utils.NewStore()})
+		if err != nil {
+			return nil, err
+		}
+		in.Volume = v
+	}
+	if id := store.GetString("snapshotID"); id != "" {
+		snap, err := svc.Driver().SnapshotInspect(ctx, id, utils.NewStore())
+		if err != nil {
+			return nil, err
+		}
+		in.Snapshot = snap
+	}
+
+	return in, nil
+}
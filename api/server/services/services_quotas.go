@@ -0,0 +1,515 @@
+package services
+
+import (
+	"strings"
+	"sync"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+type quotaService struct {
+	config *types.QuotaConfig
+
+	// pendingL guards the reservations and the listings in progress. a
+	// check lists usage without holding the lock and then checks and
+	// reserves while holding it, so that a check always observes the
+	// reservations of the checks that preceded it
+	pendingL sync.Mutex
+	pending  map[*quotaReservation]struct{}
+
+	// seq is incremented each time a reservation is released
+	seq uint64
+
+	// listings is the number of usage listings in progress keyed by the
+	// value of seq when they began
+	listings map[uint64]int
+}
+
+type quotaReservation struct {
+	service string
+	owner   string
+	usage   *types.QuotaUsage
+
+	// released is the value of seq after the reservation was released, or
+	// zero if the reservation is pending
+	released uint64
+}
+
+type quotaCheck struct {
+	scope  string
+	name   string
+	limits *types.QuotaLimits
+}
+
+// usageMap is usage keyed by service and then by owner
+type usageMap map[string]map[string]*types.QuotaUsage
+
+func (m usageMap) get(service, owner string) *types.QuotaUsage {
+	svcUsage, ok := m[service]
+	if !ok {
+		svcUsage = map[string]*types.QuotaUsage{}
+		m[service] = svcUsage
+	}
+	u, ok := svcUsage[owner]
+	if !ok {
+		u = &types.QuotaUsage{}
+		svcUsage[owner] = u
+	}
+	return u
+}
+
+func (m usageMap) service(service string) *types.QuotaUsage {
+	u := &types.QuotaUsage{}
+	for _, ou := range m[service] {
+		u.Add(ou)
+	}
+	return u
+}
+
+func (m usageMap) owner(owner string) *types.QuotaUsage {
+	u := &types.QuotaUsage{}
+	for _, svcUsage := range m {
+		if ou, ok := svcUsage[owner]; ok {
+			u.Add(ou)
+		}
+	}
+	return u
+}
+
+func (s *quotaService) Init(ctx types.Context, config gofig.Config) error {
+	s.pending = map[*quotaReservation]struct{}{}
+	s.listings = map[uint64]int{}
+
+	qc, err := utils.ParseQuotaConfig(config)
+	if err != nil {
+		return err
+	}
+	s.config = qc
+	if s.config != nil {
+		ctx.WithFields(map[string]interface{}{
+			"subjects": len(qc.Subjects),
+			"roles":    len(qc.Roles),
+			"services": len(qc.Services),
+		}).Info("configured quotas")
+	}
+
+	return nil
+}
+
+func getQuotaService(ctx types.Context) *quotaService {
+
+	serverName, ok := context.Server(ctx)
+	if !ok {
+		panic("ctx is missing ServerName")
+	}
+
+	servicesByServerRWL.RLock()
+	defer servicesByServerRWL.RUnlock()
+	return servicesByServer[serverName].quotaService
+}
+
+func storageServiceList(ctx types.Context) []types.StorageService {
+	servicesByServerRWL.RLock()
+	defer servicesByServerRWL.RUnlock()
+	svcs := []types.StorageService{}
+	for _, svc := range getStorageServices(ctx) {
+		svcs = append(svcs, svc)
+	}
+	return svcs
+}
+
+// checkLabels returns an error if subject or role quotas are configured and
+// the driver of a service does not persist the owner of the volumes and
+// snapshots it creates, since the usage of subjects could not be counted.
+func (s *quotaService) checkLabels(
+	ctx types.Context,
+	svcs map[string]types.StorageService) error {
+
+	if s.config == nil ||
+		(len(s.config.Subjects) == 0 && len(s.config.Roles) == 0) {
+		return nil
+	}
+	for name, svc := range svcs {
+		ctx := context.WithStorageService(ctx, svc)
+		if !utils.PersistsLabels(ctx, svc.Driver()) {
+			return goof.WithFields(goof.Fields{
+				"service": name,
+				"driver":  svc.Driver().Name(),
+			}, "subject and role quotas require a driver that "+
+				"persists labels")
+		}
+	}
+	return nil
+}
+
+// QuotasEnabled returns a flag indicating whether or not quotas are
+// configured.
+func QuotasEnabled(ctx types.Context) bool {
+	return getQuotaService(ctx).config != nil
+}
+
+// QuotaReserve checks whether the usage requested by a request for the
+// provided service is within the limits of the service and of the subject
+// and roles of the request's auth token. If it is, the requested usage is
+// reserved until the returned function is invoked, which should occur once
+// the driver has created the volume or snapshot. Otherwise an
+// *types.ErrQuotaExceeded error is returned.
+//
+// The usage is listed before the check acquires the lock that serializes
+// checks, so a reservation that is released after the listing began is still
+// counted. A volume or snapshot created while a check is in progress may thus
+// be counted as both usage and a reservation, erring on the side of the
+// limits. A request to create a volume without a size is rejected with an
+// *types.ErrQuotaSizeRequired error if a size limit applies to it.
+func QuotaReserve(
+	ctx types.Context,
+	svc types.StorageService,
+	requested *types.QuotaUsage) (func(), error) {
+
+	return getQuotaService(ctx).check(ctx, svc, requested, true)
+}
+
+// QuotaCheck checks whether the usage requested by a request for the
+// provided service is within the limits that apply to the request, as
+// QuotaReserve does, without reserving the usage. It is used to describe
+// the outcome of a dry-run request.
+func QuotaCheck(
+	ctx types.Context,
+	svc types.StorageService,
+	requested *types.QuotaUsage) error {
+
+	_, err := getQuotaService(ctx).check(ctx, svc, requested, false)
+	return err
+}
+
+func (s *quotaService) check(
+	ctx types.Context,
+	svc types.StorageService,
+	requested *types.QuotaUsage,
+	reserve bool) (func(), error) {
+
+	noop := func() {}
+
+	if s.config == nil {
+		return noop, nil
+	}
+
+	var (
+		service = strings.ToLower(svc.Name())
+		owner   string
+		checks  []*quotaCheck
+	)
+
+	if l, ok := s.config.Services[service]; ok {
+		checks = append(checks, &quotaCheck{
+			scope: types.QuotaScopeService, name: service, limits: l})
+	}
+	if tok, ok := context.AuthToken(ctx); ok {
+		owner = strings.ToLower(tok.Subject)
+		if l, ok := s.config.Subjects[owner]; ok {
+			checks = append(checks, &quotaCheck{
+				scope: types.QuotaScopeSubject, name: owner, limits: l})
+		}
+		for _, r := range tok.Roles {
+			r = strings.ToLower(r)
+			if l, ok := s.config.Roles[r]; ok {
+				checks = append(checks, &quotaCheck{
+					scope: types.QuotaScopeRole, name: r, limits: l})
+			}
+		}
+	}
+	if len(checks) == 0 {
+		return noop, nil
+	}
+
+	if requested.Volumes > 0 && requested.Size == 0 {
+		for _, c := range checks {
+			if c.limits.Size != nil {
+				return nil, utils.NewQuotaSizeRequiredErr(c.scope, c.name)
+			}
+		}
+	}
+
+	// the usage of a subject spans all services, so only the usage of the
+	// request's service is needed if no subject or role limits apply
+	svcs := []types.StorageService{svc}
+	for _, c := range checks {
+		if c.scope != types.QuotaScopeService {
+			svcs = storageServiceList(ctx)
+			break
+		}
+	}
+
+	seq := s.beginListing()
+	defer s.endListing(seq)
+
+	usage, err := s.usage(
+		ctx, svcs, requested.Volumes > 0, requested.Snapshots > 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	s.pendingL.Lock()
+	defer s.pendingL.Unlock()
+
+	for r := range s.pending {
+		if r.released == 0 || r.released > seq {
+			usage.get(r.service, r.owner).Add(r.usage)
+		}
+	}
+
+	for _, c := range checks {
+		var u *types.QuotaUsage
+		if c.scope == types.QuotaScopeService {
+			u = usage.service(service)
+		} else {
+			u = usage.owner(owner)
+		}
+		if err := checkQuota(ctx, c, u, requested); err != nil {
+			return nil, err
+		}
+	}
+	if !reserve {
+		return noop, nil
+	}
+
+	r := &quotaReservation{service: service, owner: owner, usage: requested}
+	s.pending[r] = struct{}{}
+
+	return func() {
+		s.pendingL.Lock()
+		defer s.pendingL.Unlock()
+		s.seq++
+		r.released = s.seq
+		s.prune()
+	}, nil
+}
+
+// beginListing records the beginning of a usage listing and returns the
+// sequence number at which it began.
+func (s *quotaService) beginListing() uint64 {
+	s.pendingL.Lock()
+	defer s.pendingL.Unlock()
+	s.listings[s.seq]++
+	return s.seq
+}
+
+// endListing records the end of a usage listing that began at the provided
+// sequence number.
+func (s *quotaService) endListing(seq uint64) {
+	s.pendingL.Lock()
+	defer s.pendingL.Unlock()
+	if s.listings[seq]--; s.listings[seq] <= 0 {
+		delete(s.listings, seq)
+	}
+	s.prune()
+}
+
+// prune removes the released reservations that no listing in progress could
+// have missed. The caller must hold pendingL.
+func (s *quotaService) prune() {
+	oldest := s.seq
+	for seq := range s.listings {
+		if seq < oldest {
+			oldest = seq
+		}
+	}
+	for r := range s.pending {
+		if r.released != 0 && r.released <= oldest {
+			delete(s.pending, r)
+		}
+	}
+}
+
+func checkQuota(
+	ctx types.Context,
+	c *quotaCheck,
+	usage, requested *types.QuotaUsage) error {
+
+	check := func(resource string, limit *int64, used, req int64) error {
+		if limit == nil || req == 0 || used+req <= *limit {
+			return nil
+		}
+		ctx.WithFields(map[string]interface{}{
+			"scope":     c.scope,
+			"name":      c.name,
+			"resource":  resource,
+			"limit":     *limit,
+			"usage":     used,
+			"requested": req,
+		}).Warn("quota exceeded")
+		return utils.NewQuotaExceededErr(
+			c.scope, c.name, resource, *limit, used, req)
+	}
+
+	l := c.limits
+	if err := check(
+		"volumes", l.Volumes, usage.Volumes, requested.Volumes); err != nil {
+		return err
+	}
+	if err := check("size", l.Size, usage.Size, requested.Size); err != nil {
+		return err
+	}
+	if err := check("iops", l.IOPS, usage.IOPS, requested.IOPS); err != nil {
+		return err
+	}
+	return check(
+		"snapshots", l.Snapshots, usage.Snapshots, requested.Snapshots)
+}
+
+// usage returns the usage of the provided services keyed by service and
+// owner. Drivers that do not implement listing snapshots are treated as
+// having no snapshots if ignoreNotImpl is true.
+func (s *quotaService) usage(
+	ctx types.Context,
+	svcs []types.StorageService,
+	volumes, snapshots, ignoreNotImpl bool) (usageMap, error) {
+
+	usage := usageMap{}
+
+	for _, svc := range svcs {
+
+		var (
+			err     error
+			service = strings.ToLower(svc.Name())
+			ctx     = context.WithStorageService(ctx, svc)
+		)
+
+		// every service's usage is included, even when the service has none
+		usage.get(service, "")
+
+		if ctx, err = context.WithStorageSession(ctx); err != nil {
+			return nil, err
+		}
+
+		if volumes {
+			vols, err := svc.Driver().Volumes(
+				ctx, &types.VolumesOpts{Opts: utils.NewStore()})
+			if err != nil {
+				return nil, goof.WithFieldE(
+					"service", service, "error listing volumes", err)
+			}
+			for _, v := range vols {
+				u := usage.get(service, utils.Owner(v.Fields))
+				u.Volumes++
+				u.Size += v.Size
+				u.IOPS += v.IOPS
+			}
+		}
+
+		if snapshots {
+			snaps, err := svc.Driver().Snapshots(ctx, utils.NewStore())
+			if err != nil {
+				if ignoreNotImpl && err == types.ErrNotImplemented {
+					continue
+				}
+				return nil, goof.WithFieldE(
+					"service", service, "error listing snapshots", err)
+			}
+			for _, v := range snaps {
+				usage.get(service, utils.Owner(v.Fields)).Snapshots++
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// QuotaReport returns the usage and limits of all auth subjects and
+// services.
+func QuotaReport(ctx types.Context) (*types.QuotaReport, error) {
+
+	s := getQuotaService(ctx)
+	usage, err := s.usage(ctx, storageServiceList(ctx), true, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	qc := s.config
+	if qc == nil {
+		qc = &types.QuotaConfig{}
+	}
+
+	report := &types.QuotaReport{
+		Subjects: map[string]*types.SubjectQuota{},
+		Services: map[string]*types.ServiceQuota{},
+	}
+
+	for service, svcUsage := range usage {
+		report.Services[service] = &types.ServiceQuota{
+			Usage:  usage.service(service),
+			Limits: qc.Services[service],
+		}
+		for owner := range svcUsage {
+			if owner == "" {
+				continue
+			}
+			if _, ok := report.Subjects[owner]; !ok {
+				report.Subjects[owner] = s.subjectQuota(ctx, usage, owner)
+			}
+		}
+	}
+	for subject := range qc.Subjects {
+		if _, ok := report.Subjects[subject]; !ok {
+			report.Subjects[subject] = s.subjectQuota(ctx, usage, subject)
+		}
+	}
+
+	return report, nil
+}
+
+// SubjectQuota returns the usage and limits of an auth subject.
+func SubjectQuota(
+	ctx types.Context, subject string) (*types.SubjectQuota, error) {
+
+	s := getQuotaService(ctx)
+	usage, err := s.usage(ctx, storageServiceList(ctx), true, true, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.subjectQuota(ctx, usage, strings.ToLower(subject)), nil
+}
+
+func (s *quotaService) subjectQuota(
+	ctx types.Context,
+	usage usageMap,
+	subject string) *types.SubjectQuota {
+
+	sq := &types.SubjectQuota{
+		Subject:  subject,
+		Usage:    usage.owner(subject),
+		Services: map[string]*types.QuotaUsage{},
+	}
+	for service, svcUsage := range usage {
+		if u, ok := svcUsage[subject]; ok {
+			sq.Services[service] = u
+		}
+	}
+
+	if s.config == nil {
+		return sq
+	}
+	sq.Limits = s.config.Subjects[subject]
+
+	// a subject's roles are only known from the request's token
+	tok, ok := context.AuthToken(ctx)
+	if !ok || !strings.EqualFold(tok.Subject, subject) {
+		return sq
+	}
+	for _, r := range tok.Roles {
+		r = strings.ToLower(r)
+		if l, ok := s.config.Roles[r]; ok {
+			if sq.Roles == nil {
+				sq.Roles = map[string]*types.QuotaLimits{}
+			}
+			sq.Roles[r] = l
+		}
+	}
+
+	return sq
+}
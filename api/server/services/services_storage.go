@@ -2,7 +2,10 @@ package services
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 
+	log "github.com/Sirupsen/logrus"
 	gofig "github.com/akutz/gofig/types"
 	"github.com/akutz/goof"
 
@@ -13,11 +16,22 @@ import (
 )
 
 type storageService struct {
+	// the following fields are accessed atomically and describe the state
+	// of the service's task queue. they are first in the struct in order to
+	// guarantee 64-bit alignment on 32-bit platforms.
+	tasksQueued    int64
+	tasksRunning   int64
+	tasksCompleted int64
+
 	name          string
 	driver        types.StorageDriver
 	config        gofig.Config
 	authConfig    *types.AuthConfig
+	classes       types.StorageClassMap
 	taskExecQueue chan *task
+
+	logLevel    *log.Level
+	logLevelRWL sync.RWMutex
 }
 
 func (s *storageService) Init(ctx types.Context, config gofig.Config) error {
@@ -30,7 +44,11 @@ func (s *storageService) Init(ctx types.Context, config gofig.Config) error {
 	s.taskExecQueue = make(chan *task)
 	go func() {
 		for t := range s.taskExecQueue {
+			atomic.AddInt64(&s.tasksQueued, -1)
+			atomic.AddInt64(&s.tasksRunning, 1)
 			execTask(t)
+			atomic.AddInt64(&s.tasksRunning, -1)
+			atomic.AddInt64(&s.tasksCompleted, 1)
 		}
 	}()
 
@@ -46,6 +64,14 @@ func (s *storageService) Init(ctx types.Context, config gofig.Config) error {
 		ctx.WithFields(authFields).Info("configured service auth")
 	}
 
+	if s.classes, err = utils.ParseStorageClasses(config); err != nil {
+		return goof.WithFieldE("service", s.name, "error parsing classes", err)
+	}
+	if len(s.classes) > 0 {
+		ctx.WithField("count", len(s.classes)).Info(
+			"configured storage classes")
+	}
+
 	return nil
 }
 
@@ -86,6 +112,10 @@ func (s *storageService) AuthConfig() *types.AuthConfig {
 	return s.authConfig
 }
 
+func (s *storageService) StorageClasses() types.StorageClassMap {
+	return s.classes
+}
+
 func (s *storageService) Driver() types.StorageDriver {
 	return s.driver
 }
@@ -96,10 +126,45 @@ func (s *storageService) TaskEnqueue(
 	schema []byte) *types.Task {
 
 	t := newStorageServiceTask(ctx, run, s, schema)
+	atomic.AddInt64(&s.tasksQueued, 1)
 	go func() { s.taskExecQueue <- t }()
 	return &t.Task
 }
 
+// LogLevel returns the service's log level override. A false value is
+// returned if the service uses the server's global log level.
+func (s *storageService) LogLevel() (log.Level, bool) {
+	s.logLevelRWL.RLock()
+	defer s.logLevelRWL.RUnlock()
+	if s.logLevel == nil {
+		return 0, false
+	}
+	return *s.logLevel, true
+}
+
+// SetLogLevel sets the service's log level override. A nil value removes
+// the override.
+func (s *storageService) SetLogLevel(lvl *log.Level) {
+	s.logLevelRWL.Lock()
+	defer s.logLevelRWL.Unlock()
+	s.logLevel = lvl
+}
+
+// State returns the service's runtime state.
+func (s *storageService) State() *types.ServiceState {
+	state := &types.ServiceState{
+		Name:      s.name,
+		Driver:    s.driver.Name(),
+		Queued:    atomic.LoadInt64(&s.tasksQueued),
+		Running:   atomic.LoadInt64(&s.tasksRunning),
+		Completed: atomic.LoadInt64(&s.tasksCompleted),
+	}
+	if lvl, ok := s.LogLevel(); ok {
+		state.LogLevel = lvl.String()
+	}
+	return state
+}
+
 func (s *storageService) Name() string {
 	return s.name
 }
@@ -3,6 +3,7 @@ package services
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -25,12 +26,12 @@ type task struct {
 	resultSchema                  []byte
 	resultSchemaValidationEnabled bool
 	done                          chan int
+	cancel                        func()
 }
 
 func newTask(ctx types.Context, schema []byte) *task {
 	t := getTaskService(ctx).taskTrack(ctx)
 	t.resultSchema = schema
-	t.done = make(chan int)
 	return t
 }
 
@@ -66,9 +67,15 @@ func execTask(t *task) {
 			t.State = types.TaskStateSuccess
 		}
 		close(t.done)
+		t.cancel()
 		t.ctx.Debug("task completed")
 	}()
 
+	// a task that was cancelled while it was queued is not executed
+	if t.Error = t.ctx.Err(); t.Error != nil {
+		return
+	}
+
 	t.State = types.TaskStateRunning
 	t.StartTime = time.Now().Unix()
 
@@ -117,6 +124,7 @@ type globalTaskService struct {
 	name                          string
 	config                        gofig.Config
 	tasks                         map[int]*task
+	nextTaskID                    int
 	resultSchemaValidationEnabled bool
 }
 
@@ -162,19 +170,28 @@ func (s *globalTaskService) TaskTrack(ctx types.Context) *types.Task {
 }
 func (s *globalTaskService) taskTrack(ctx types.Context) *task {
 
+	// task IDs are never reused, even after tasks are removed from the map
 	now := time.Now().Unix()
-	s.RLock()
-	taskID := len(s.tasks)
-	s.RUnlock()
+	s.Lock()
+	taskID := s.nextTaskID
+	s.nextTaskID++
+	s.Unlock()
+
+	// a task is not cancelled along with the request that created it since
+	// the task may outlive the request, but it may be cancelled on its own
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
 
 	t := &task{
 		Task: types.Task{
 			ID:        taskID,
+			User:      RequestUser(ctx),
 			QueueTime: now,
 		},
 		resultSchemaValidationEnabled: s.resultSchemaValidationEnabled,
 		ctx: ctx.WithValue(context.TaskKey, fmt.Sprintf("%d", taskID)),
 	}
+	t.done = make(chan int)
+	t.cancel = cancel
 
 	s.Lock()
 	s.tasks[taskID] = t
@@ -204,6 +221,96 @@ func (s *globalTaskService) TaskInspect(taskID int) *types.Task {
 	return nil
 }
 
+// TaskCancel cancels the context of the task with the specified ID, unless
+// the task is complete, and returns the task. A storage driver that honors
+// the cancellation of the context aborts the task's operation, and a task
+// that is still queued is not executed. Nil is returned if there is no such
+// task.
+func (s *globalTaskService) TaskCancel(
+	ctx types.Context, taskID int) *types.Task {
+
+	s.RLock()
+	t, ok := s.tasks[taskID]
+	s.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	select {
+	case <-t.done:
+	default:
+		t.cancel()
+		t.ctx.WithField("user", ctx.Value(context.UserKey)).Info(
+			"cancelled task")
+	}
+	return &t.Task
+}
+
+// TaskService returns the name of the storage service on which the task with
+// the specified ID operates. An empty string is returned if there is no such
+// task or if the task does not operate on a single service.
+func (s *globalTaskService) TaskService(taskID int) string {
+	s.RLock()
+	defer s.RUnlock()
+	t, ok := s.tasks[taskID]
+	if !ok {
+		return ""
+	}
+	if t.storService != nil {
+		return t.storService.Name()
+	}
+	if svc, ok := context.Service(t.ctx); ok {
+		return svc.Name()
+	}
+	return ""
+}
+
+// TaskNamespace returns the namespace to which the request that created the
+// task with the specified ID was scoped. The returned flag is false if there
+// is no such task or if the request was not scoped to a namespace.
+func (s *globalTaskService) TaskNamespace(taskID int) (string, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	t, ok := s.tasks[taskID]
+	if !ok {
+		return "", false
+	}
+	return context.Namespace(t.ctx)
+}
+
+// TaskFields adds the provided fields to the fields of the task with which
+// the context is associated, if any.
+func (s *globalTaskService) TaskFields(
+	ctx types.Context, fields map[string]string) {
+
+	szID, ok := ctx.Value(context.TaskKey).(string)
+	if !ok {
+		return
+	}
+	taskID, err := strconv.Atoi(szID)
+	if err != nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	t, ok := s.tasks[taskID]
+	if !ok {
+		return
+	}
+
+	// the fields are replaced rather than modified since the task may be
+	// read while it is running
+	tf := map[string]string{}
+	for k, v := range t.Fields {
+		tf[k] = v
+	}
+	for k, v := range fields {
+		tf[k] = v
+	}
+	t.Fields = tf
+}
+
 // TaskWait blocks until the specified task is completed.
 func (s *globalTaskService) TaskWait(taskID int) {
 	<-s.TaskWaitC(taskID)
@@ -265,6 +372,30 @@ func (s *globalTaskService) taskRemoveAfter(t *task) {
 	}()
 }
 
+// TaskPurge removes all completed tasks and returns the number of tasks that
+// were removed.
+func (s *globalTaskService) TaskPurge(ctx types.Context) int {
+	s.Lock()
+	defer s.Unlock()
+
+	purged := 0
+	for id, t := range s.tasks {
+		select {
+		case <-t.done:
+			delete(s.tasks, id)
+			purged++
+		default:
+		}
+	}
+
+	ctx.WithFields(log.Fields{
+		"purged":   purged,
+		"tasksLen": len(s.tasks),
+	}).Info("purged completed tasks")
+
+	return purged
+}
+
 // TaskWaitAll blocks until all the specified task are complete.
 func (s *globalTaskService) TaskWaitAll(taskIDs ...int) {
 	<-s.TaskWaitAllC(taskIDs...)
@@ -27,6 +27,7 @@ import (
 	"github.com/codedellemc/libstorage/api/server/executors"
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/api/utils/secrets"
 	"github.com/codedellemc/libstorage/client"
 )
 
@@ -47,6 +48,11 @@ var (
 	sockTest, _    = strconv.ParseBool(os.Getenv("LIBSTORAGE_TEST_SOCK"))
 	sockTLSTest, _ = strconv.ParseBool(os.Getenv("LIBSTORAGE_TEST_SOCK_TLS"))
 
+	grpcTest, _ = strconv.ParseBool(os.Getenv("LIBSTORAGE_TEST_GRPC"))
+
+	embeddedTest, _ = strconv.ParseBool(
+		os.Getenv("LIBSTORAGE_TEST_EMBEDDED"))
+
 	printConfigOnFail, _ = strconv.ParseBool(os.Getenv(
 		"LIBSTORAGE_TEST_PRINT_CONFIG_ON_FAIL"))
 
@@ -99,12 +105,14 @@ libstorage:
 )
 
 // APITestFunc is a function that wraps a block of test logic for testing the
-// API. An APITestFunc is executed four times:
+// API. An APITestFunc is executed once for each enabled endpoint type:
 //
 //  1 - tcp
 //  2 - tcp+tls
 //  3 - sock
 //  4 - sock+tls
+//  5 - grpc
+//  6 - embedded, the server's in-process API client
 type APITestFunc func(config gofig.Config, client types.Client, t *testing.T)
 
 // testHarness can be used by StorageDriver developers to quickly create
@@ -374,7 +382,7 @@ func (th *testHarness) run(
 
 				th.servers = append(th.servers, server)
 
-				c, err := client.New(ctx, config)
+				c, err := newClient(ctx, server, configNames[x], config)
 				if onNewClientError != nil {
 					onNewClientError(err)
 				} else if err != nil {
@@ -385,7 +393,7 @@ func (th *testHarness) run(
 					for _, test := range tests {
 						test(config, c, t)
 						if t.Failed() && printConfigOnFail {
-							cj, err := config.ToJSON()
+							cj, err := secrets.ToJSON(config)
 							if err != nil {
 								t.Fatal(err)
 							}
@@ -423,7 +431,8 @@ func (th *testHarness) run(
 
 					th.servers = append(th.servers, server)
 
-					c, err := client.New(ctx, config)
+					c, err := newClient(
+						ctx, server, configNames[x], config)
 					if onNewClientError != nil {
 						onNewClientError(err)
 					} else if err != nil {
@@ -435,7 +444,7 @@ func (th *testHarness) run(
 					}
 
 					if t.Failed() && printConfigOnFail {
-						cj, err := config.ToJSON()
+						cj, err := secrets.ToJSON(config)
 						if err != nil {
 							t.Fatal(err)
 						}
@@ -559,10 +568,38 @@ func getTestConfigs(
 			"libstorage.tests.unixTLSPeers").Scope(
 			"test"))
 	}
+	if grpcTest {
+		configNames[len(configNames)] = "grpc"
+		configs = append(configs, config.Scope(
+			"libstorage.tests.grpc").Scope(
+			"test"))
+	}
+	if embeddedTest {
+		configNames[len(configNames)] = embeddedConfigName
+		configs = append(configs, config.Scope(
+			"libstorage.tests.embedded").Scope(
+			"test"))
+	}
 
 	return configNames, configs
 }
 
+// embeddedConfigName is the name of the test config whose client uses the
+// server's in-process API client instead of connecting to the server.
+const embeddedConfigName = "embedded"
+
+func newClient(
+	ctx types.Context,
+	server types.Server,
+	configName string,
+	config gofig.Config) (types.Client, error) {
+
+	if configName == embeddedConfigName {
+		ctx = ctx.WithValue(context.APIClientKey, server.API())
+	}
+	return client.New(ctx, config)
+}
+
 func (th *testHarness) closeServers(t *testing.T) {
 	for _, server := range th.servers {
 		if server == nil {
@@ -579,6 +616,8 @@ func initTestConfigs(ctx types.Context, config map[string]interface{}) {
 	tcpTLSHost := fmt.Sprintf("tcp://127.0.0.1:%d", gotil.RandomTCPPort())
 	unixHost := fmt.Sprintf("unix://%s", utils.GetTempSockFile(ctx))
 	unixTLSHost := fmt.Sprintf("unix://%s", utils.GetTempSockFile(ctx))
+	grpcHost := fmt.Sprintf("grpc://127.0.0.1:%d", gotil.RandomTCPPort())
+	embeddedHost := fmt.Sprintf("unix://%s", utils.GetTempSockFile(ctx))
 
 	clientTLSConfig := func(peers bool) map[string]interface{} {
 		if peers {
@@ -685,6 +724,33 @@ func initTestConfigs(ctx types.Context, config map[string]interface{}) {
 				},
 			},
 		},
+
+		"grpc": map[string]interface{}{
+			"libstorage": map[string]interface{}{
+				"tls":  false,
+				"host": grpcHost,
+				"server": map[string]interface{}{
+					"endpoints": map[string]interface{}{
+						"localhost": map[string]interface{}{
+							"address": grpcHost,
+						},
+					},
+				},
+			},
+		},
+
+		"embedded": map[string]interface{}{
+			"libstorage": map[string]interface{}{
+				"embedded": true,
+				"server": map[string]interface{}{
+					"endpoints": map[string]interface{}{
+						"localhost": map[string]interface{}{
+							"address": embeddedHost,
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -20,7 +20,9 @@ var TestExecutors = func(
 		t.Fatal(err)
 	}
 	//assertLSXWindows(t, reply["lsx-windows.exe"])
-	assertLSXLinux(t, reply["lsx-linux"])
+	assertLSXLinux(t, reply[lsxLinuxInfo.Name])
+	assert.Equal(t, "linux", reply[lsxLinuxInfo.Name].OS)
+	assert.Equal(t, "amd64", reply[lsxLinuxInfo.Name].Arch)
 	//assertLSXDarwin(t, reply["lsx-darwin"])
 }
 
@@ -47,12 +49,12 @@ var TestExecutorsWithControllerClient = func(
 	assertLSXWindows(t, reply)
 }*/
 
-// TestHeadExecutorLinux tests the HEAD /executors/lsx-linux route.
+// TestHeadExecutorLinux tests the HEAD /executors/lsx-linux-amd64 route.
 var TestHeadExecutorLinux = func(
 	config gofig.Config,
 	client types.Client, t *testing.T) {
 
-	reply, err := client.API().ExecutorHead(nil, "lsx-linux")
+	reply, err := client.API().ExecutorHead(nil, lsxLinuxInfo.Name)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -88,7 +90,8 @@ var TestHeadExecutorLinux = func(
 	assert.EqualValues(t, lsxWindowsInfo.Size, len(buf))
 }*/
 
-// TestGetExecutorLinux tests the GET /executors/lsx-linux route.
+// TestGetExecutorLinux tests the GET /executors/lsx-linux route, which
+// returns the linux/amd64 executor.
 var TestGetExecutorLinux = func(
 	config gofig.Config,
 	client types.Client, t *testing.T) {
@@ -132,6 +135,8 @@ func assertLSXLinux(t *testing.T, i *types.ExecutorInfo) {
 	assert.Equal(t, lsxLinuxInfo.Name, i.Name)
 	assert.EqualValues(t, lsxLinuxInfo.Size, i.Size)
 	assert.Equal(t, lsxLinuxInfo.MD5Checksum, i.MD5Checksum)
+	assert.Equal(t, lsxLinuxInfo.SHA256Checksum, i.SHA256Checksum)
+	assert.Equal(t, lsxLinuxInfo.Signature, i.Signature)
 }
 
 /*func assertLSXDarwin(t *testing.T, i *types.ExecutorInfo) {
@@ -0,0 +1,48 @@
+package types
+
+// Cache is a type that caches data and may be flushed at runtime, such as
+// via the server's admin API.
+type Cache interface {
+
+	// Name returns the name of the cache.
+	Name() string
+
+	// Flush removes all of the entries from the cache and returns the number
+	// of removed entries.
+	Flush(ctx Context) int
+}
+
+// AdminLogLevels is the log level configuration reported and modified by
+// the admin API.
+type AdminLogLevels struct {
+
+	// Global is the server's global log level.
+	Global string `json:"global" yaml:"global"`
+
+	// Services is a map of service names to their log level overrides.
+	Services map[string]string `json:"services,omitempty" yaml:",omitempty"`
+}
+
+// ServiceState is the runtime state of a storage service as reported by
+// the admin API.
+type ServiceState struct {
+
+	// Name is the name of the service.
+	Name string `json:"name" yaml:"name"`
+
+	// Driver is the name of the service's storage driver.
+	Driver string `json:"driver" yaml:"driver"`
+
+	// LogLevel is the service's log level override, if any.
+	LogLevel string `json:"logLevel,omitempty" yaml:"logLevel,omitempty"`
+
+	// Queued is the number of tasks waiting in the service's queue.
+	Queued int64 `json:"queued" yaml:"queued"`
+
+	// Running is the number of the service's tasks currently executing.
+	Running int64 `json:"running" yaml:"running"`
+
+	// Completed is the number of tasks the service has completed since the
+	// server was started.
+	Completed int64 `json:"completed" yaml:"completed"`
+}
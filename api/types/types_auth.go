@@ -5,19 +5,27 @@ package types
 // All fields related to times are stored as UTC epochs in seconds.
 type AuthToken struct {
 	// Subject is the intended principal of the token.
-	Subject string `json:"sub"`
+	Subject string `json:"sub" yaml:"sub"`
 
 	// Expires is the time at which the token expires.
-	Expires int64 `json:"exp"`
+	Expires int64 `json:"exp" yaml:"exp"`
 
 	// NotBefore is the the time at which the token becomes valid.
-	NotBefore int64 `json:"nbf"`
+	NotBefore int64 `json:"nbf" yaml:"nbf"`
 
 	// IssuedAt is the time at which the token was issued.
-	IssuedAt int64 `json:"iat"`
+	IssuedAt int64 `json:"iat" yaml:"iat"`
+
+	// Roles are the roles granted to the principal by the token's roles
+	// claim.
+	Roles []string `json:"roles,omitempty" yaml:"roles,omitempty"`
+
+	// Namespace is the namespace of the principal from the token's namespace
+	// claim.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
 
 	// Encoded is the encoded JWT string.
-	Encoded string `json:"enc"`
+	Encoded string `json:"enc" yaml:"enc"`
 }
 
 // String returns the subject of the security token.
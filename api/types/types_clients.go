@@ -3,6 +3,7 @@ package types
 import (
 	"io"
 	"strings"
+	"time"
 )
 
 // ClientType is a client's type.
@@ -88,6 +89,18 @@ type APIClient interface {
 	// LogResponses enables or disables the logging of client HTTP responses.
 	LogResponses(enabled bool)
 
+	// UseContentType sets the media type used to encode request payloads
+	// and requested for response bodies. The default is ContentTypeJSON.
+	UseContentType(contentType ContentType)
+
+	// UseRetryPolicy sets the policy with which failed requests are
+	// retried. A nil policy disables retries.
+	UseRetryPolicy(policy *RetryPolicy)
+
+	// CancelTasks enables or disables the cancellation of the server-side
+	// task of a request when the request's context is cancelled.
+	CancelTasks(enabled bool)
+
 	// Root returns a list of root resources.
 	Root(ctx Context) ([]string, error)
 
@@ -100,10 +113,24 @@ type APIClient interface {
 	// Services returns a map of the configured Services.
 	Services(ctx Context) (map[string]*ServiceInfo, error)
 
-	// ServiceInspect returns information about a service.
+	// ServiceInspect returns information about a service. The zones in which
+	// the service can provision volumes are included if the context was
+	// created with context.WithZones.
 	ServiceInspect(ctx Context, name string) (*ServiceInfo, error)
 
-	// Volumes returns a list of all Volumes for all Services.
+	// StorageClasses returns the storage classes of a service.
+	StorageClasses(ctx Context, service string) (StorageClassMap, error)
+
+	// Quotas returns the quota usage and limits of all auth subjects and
+	// services.
+	Quotas(ctx Context) (*QuotaReport, error)
+
+	// SubjectQuota returns the quota usage and limits of an auth subject.
+	SubjectQuota(ctx Context, subject string) (*SubjectQuota, error)
+
+	// Volumes returns a list of all Volumes for all Services. If some of the
+	// services fail then the volumes of the remaining services are returned
+	// along with an *ErrPartialResult error.
 	Volumes(
 		ctx Context,
 		attachments VolumeAttachmentsTypes) (ServiceVolumeMap, error)
@@ -114,6 +141,15 @@ type APIClient interface {
 		service string,
 		attachments VolumeAttachmentsTypes) (VolumeMap, error)
 
+	// VolumesWatch blocks until the volumes for a service change after the
+	// provided revision or the server's watch timeout elapses. The changes
+	// are returned along with the revision to provide to the next watch. A
+	// revision of zero returns all of the service's volumes as added.
+	VolumesWatch(
+		ctx Context,
+		service string,
+		since int64) (*VolumeWatchResponse, error)
+
 	// VolumeInspect gets information about a single volume.
 	VolumeInspect(
 		ctx Context,
@@ -199,6 +235,91 @@ type APIClient interface {
 		service, snapshotID string,
 		request *SnapshotCopyRequest) (*Snapshot, error)
 
+	// VolumeCreateAsync enqueues the creation of a single volume. The
+	// result of the returned task is a *Volume.
+	VolumeCreateAsync(
+		ctx Context,
+		service string,
+		request *VolumeCreateRequest) (*Task, error)
+
+	// VolumeCreateFromSnapshotAsync enqueues the creation of a single volume
+	// from a snapshot. The result of the returned task is a *Volume.
+	VolumeCreateFromSnapshotAsync(
+		ctx Context,
+		service, snapshotID string,
+		request *VolumeCreateRequest) (*Task, error)
+
+	// VolumeCopyAsync enqueues the copy of a single volume. The result of
+	// the returned task is a *Volume.
+	VolumeCopyAsync(
+		ctx Context,
+		service, volumeID string,
+		request *VolumeCopyRequest) (*Task, error)
+
+	// VolumeRemoveAsync enqueues the removal of a single volume. The
+	// returned task has no result.
+	VolumeRemoveAsync(
+		ctx Context,
+		service, volumeID string,
+		force bool) (*Task, error)
+
+	// VolumeAttachAsync enqueues the attachment of a single volume. The
+	// result of the returned task is a *VolumeAttachResponse.
+	VolumeAttachAsync(
+		ctx Context,
+		service string,
+		volumeID string,
+		request *VolumeAttachRequest) (*Task, error)
+
+	// VolumeDetachAsync enqueues the detachment of a single volume. The
+	// result of the returned task is a *Volume.
+	VolumeDetachAsync(
+		ctx Context,
+		service string,
+		volumeID string,
+		request *VolumeDetachRequest) (*Task, error)
+
+	// VolumeSnapshotAsync enqueues the creation of a single snapshot. The
+	// result of the returned task is a *Snapshot.
+	VolumeSnapshotAsync(
+		ctx Context,
+		service string,
+		volumeID string,
+		request *VolumeSnapshotRequest) (*Task, error)
+
+	// SnapshotRemoveAsync enqueues the removal of a single snapshot. The
+	// returned task has no result.
+	SnapshotRemoveAsync(
+		ctx Context,
+		service, snapshotID string) (*Task, error)
+
+	// SnapshotCopyAsync enqueues the copy of a snapshot to a new snapshot.
+	// The result of the returned task is a *Snapshot.
+	SnapshotCopyAsync(
+		ctx Context,
+		service, snapshotID string,
+		request *SnapshotCopyRequest) (*Task, error)
+
+	// Tasks returns the server's tasks keyed by their IDs.
+	Tasks(ctx Context) (map[string]*Task, error)
+
+	// TaskInspect gets information about a single task. The result of a
+	// task created by one of the client's async methods is decoded into the
+	// model type of the operation, while the result of any other task is
+	// decoded into generic maps and slices.
+	TaskInspect(ctx Context, taskID int) (*Task, error)
+
+	// TaskCancel cancels a task. The server cancels the context of the task,
+	// so the operation is aborted only if the storage driver honors the
+	// cancellation, or if the task has not started yet.
+	TaskCancel(ctx Context, taskID int) (*Task, error)
+
+	// TaskWait blocks until a task completes or the context is cancelled.
+	// If the task failed then the task is returned along with its error. If
+	// task cancellation is enabled then cancelling the context also cancels
+	// the task.
+	TaskWait(ctx Context, taskID int) (*Task, error)
+
 	// Executors returns information about the executors.
 	Executors(
 		ctx Context) (map[string]*ExecutorInfo, error)
@@ -212,3 +333,21 @@ type APIClient interface {
 	ExecutorGet(
 		ctx Context, name string) (io.ReadCloser, error)
 }
+
+// RetryPolicy is the policy with which an API client retries failed
+// requests. Requests that read resources are retried after transport errors
+// and 408, 429 and 5xx responses. Requests that modify resources are retried
+// after a failure to connect to the server, and after 429, 502, 503 and 504
+// responses only if the caller declared them idempotent.
+type RetryPolicy struct {
+
+	// Attempts is the maximum number of times a request is retried.
+	Attempts int
+
+	// MinBackoff is the delay before the first retry. The delay doubles
+	// with each subsequent retry.
+	MinBackoff time.Duration
+
+	// MaxBackoff is the maximum delay between two attempts.
+	MaxBackoff time.Duration
+}
@@ -75,12 +75,20 @@ const (
 	// ConfigServices is a config key.
 	ConfigServices = ConfigServer + ".services"
 
+	// ConfigServiceClasses is a config key relative to the configuration
+	// of a service.
+	ConfigServiceClasses = "classes"
+
 	// ConfigServerAutoEndpointMode is a config key.
 	ConfigServerAutoEndpointMode = ConfigServer + ".autoEndpointMode"
 
 	// ConfigEndpoints is a config key.
 	ConfigEndpoints = ConfigServer + ".endpoints"
 
+	// ConfigEndpointServices is a config key relative to the configuration
+	// of an endpoint.
+	ConfigEndpointServices = "services"
+
 	// ConfigServerParseRequestOpts is a config key.
 	ConfigServerParseRequestOpts = ConfigServer + ".parseRequestOpts"
 
@@ -90,9 +98,39 @@ const (
 	// ConfigExecutorNoDownload is a config key.
 	ConfigExecutorNoDownload = ConfigRoot + ".executor.disableDownload"
 
+	// ConfigExecutorServe is a config key.
+	ConfigExecutorServe = ConfigRoot + ".executor.serve"
+
+	// ConfigExecutorTrustedKeys is a config key.
+	ConfigExecutorTrustedKeys = ConfigRoot + ".executor.trustedKeys"
+
 	// ConfigClientCacheInstanceID is a config key.
 	ConfigClientCacheInstanceID = ConfigClient + ".cache.instanceID"
 
+	// ConfigClientCacheSupported is a config key.
+	ConfigClientCacheSupported = ConfigClient + ".cache.supported"
+
+	// ConfigClientCacheDisk is a config key.
+	ConfigClientCacheDisk = ConfigClient + ".cache.disk"
+
+	// ConfigClientHostsRetryInterval is a config key.
+	ConfigClientHostsRetryInterval = ConfigClient + ".hosts.retryInterval"
+
+	// ConfigClientHTTPRetry is a config key.
+	ConfigClientHTTPRetry = ConfigClient + ".http.retry"
+
+	// ConfigClientHTTPRetryAttempts is a config key.
+	ConfigClientHTTPRetryAttempts = ConfigClientHTTPRetry + ".attempts"
+
+	// ConfigClientHTTPRetryMinBackoff is a config key.
+	ConfigClientHTTPRetryMinBackoff = ConfigClientHTTPRetry + ".minBackoff"
+
+	// ConfigClientHTTPRetryMaxBackoff is a config key.
+	ConfigClientHTTPRetryMaxBackoff = ConfigClientHTTPRetry + ".maxBackoff"
+
+	// ConfigClientTasksCancel is a config key.
+	ConfigClientTasksCancel = ConfigClient + ".tasks.cancel"
+
 	// ConfigTLS is a config key.
 	ConfigTLS = ConfigRoot + ".tls"
 
@@ -142,6 +180,60 @@ const (
 	// ConfigServerTasksLogTimeout is a config key.
 	ConfigServerTasksLogTimeout = ConfigServerTasks + ".logTimeout"
 
+	// ConfigServerExecutorsPath is a config key.
+	ConfigServerExecutorsPath = ConfigServer + ".executors.path"
+
+	// ConfigServerVolumes is a config key.
+	ConfigServerVolumes = ConfigServer + ".volumes"
+
+	// ConfigServerVolumesWatch is a config key.
+	ConfigServerVolumesWatch = ConfigServerVolumes + ".watch"
+
+	// ConfigServerVolumesWatchTimeout is a config key.
+	ConfigServerVolumesWatchTimeout = ConfigServerVolumesWatch + ".timeout"
+
+	// ConfigServerVolumesWatchRelist is a config key.
+	ConfigServerVolumesWatchRelist = ConfigServerVolumesWatch + ".relist"
+
+	// ConfigServerVolumesCache is a config key.
+	ConfigServerVolumesCache = ConfigServerVolumes + ".cache"
+
+	// ConfigServerVolumesCacheTTL is a config key.
+	ConfigServerVolumesCacheTTL = ConfigServerVolumesCache + ".ttl"
+
+	// ConfigServerVolumesPartial is a config key.
+	ConfigServerVolumesPartial = ConfigServerVolumes + ".partial"
+
+	// ConfigServerVolumesServiceTimeout is a config key.
+	ConfigServerVolumesServiceTimeout = ConfigServerVolumes + ".serviceTimeout"
+
+	// ConfigServerQuotas is a config key.
+	ConfigServerQuotas = ConfigServer + ".quotas"
+
+	// ConfigServerNamespaces is a config key.
+	ConfigServerNamespaces = ConfigServer + ".namespaces"
+
+	// ConfigServerNamespacesEnabled is a config key.
+	ConfigServerNamespacesEnabled = ConfigServerNamespaces + ".enabled"
+
+	// ConfigServerNamespacesSubjects is a config key.
+	ConfigServerNamespacesSubjects = ConfigServerNamespaces + ".subjects"
+
+	// ConfigServerPolicy is a config key.
+	ConfigServerPolicy = ConfigServer + ".policy"
+
+	// ConfigServerPolicyFile is a config key.
+	ConfigServerPolicyFile = ConfigServerPolicy + ".file"
+
+	// ConfigServerPolicyEndpoint is a config key.
+	ConfigServerPolicyEndpoint = ConfigServerPolicy + ".endpoint"
+
+	// ConfigServerPolicyTimeout is a config key.
+	ConfigServerPolicyTimeout = ConfigServerPolicy + ".timeout"
+
+	// ConfigServerPolicyFailOpen is a config key.
+	ConfigServerPolicyFailOpen = ConfigServerPolicy + ".failOpen"
+
 	// ConfigClientAuth is a config key.
 	ConfigClientAuth = ConfigClient + ".auth"
 
@@ -165,4 +257,19 @@ const (
 
 	// ConfigServerAuthDisabled is a config key.
 	ConfigServerAuthDisabled = ConfigServerAuth + ".disabled"
+
+	// ConfigServerAdmin is a config key.
+	ConfigServerAdmin = ConfigServer + ".admin"
+
+	// ConfigServerAdminToken is a config key.
+	ConfigServerAdminToken = ConfigServerAdmin + ".token"
+
+	// ConfigServerAdminAllow is a config key.
+	ConfigServerAdminAllow = ConfigServerAdmin + ".allow"
+
+	// ConfigServerDebug is a config key.
+	ConfigServerDebug = ConfigServer + ".debug"
+
+	// ConfigServerDebugAllow is a config key.
+	ConfigServerDebugAllow = ConfigServerDebug + ".allow"
 )
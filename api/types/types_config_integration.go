@@ -73,6 +73,9 @@ const (
 	// ConfigIgVolOpsCreateDefaultIOPS is a config key.
 	ConfigIgVolOpsCreateDefaultIOPS = ConfigIgVolOpsCreateDefault + ".IOPS"
 
+	// ConfigIgVolOpsCreateDefaultClass is a config key.
+	ConfigIgVolOpsCreateDefaultClass = ConfigIgVolOpsCreateDefault + ".class"
+
 	// ConfigIgVolOpsRemove is a config key.
 	ConfigIgVolOpsRemove = ConfigIgVolOps + ".remove"
 
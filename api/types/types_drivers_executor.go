@@ -46,6 +46,11 @@ const (
 
 	// LSXCmdMounts is the command for getting a list of mount info objects.
 	LSXCmdMounts = "mounts"
+
+	// LSXCmdServe is the command that runs the executor as a daemon that
+	// executes the commands it receives as LSXRequest objects and replies
+	// with LSXResponse objects.
+	LSXCmdServe = "serve"
 )
 
 const (
@@ -115,6 +120,47 @@ type WaitForDeviceOpts struct {
 	Timeout time.Duration
 }
 
+// LSXRequest is a request to execute a command that is sent to an executor
+// daemon as a single line of JSON.
+type LSXRequest struct {
+
+	// ID is the ID of the request. The response to the request has the
+	// same ID.
+	ID int `json:"id" yaml:"id"`
+
+	// Args are the command and its arguments, ex. ["localDevices", "quick"].
+	Args []string `json:"args" yaml:"args"`
+
+	// Transaction is the transaction of the operation that sent the request.
+	Transaction *Transaction `json:"tx,omitempty" yaml:"tx,omitempty"`
+}
+
+// LSXResponse is an executor daemon's response to a request, sent as a
+// single line of JSON. A daemon sends a response with the Ready flag set
+// when it is ready to receive requests.
+type LSXResponse struct {
+
+	// ID is the ID of the request to which the response belongs.
+	ID int `json:"id" yaml:"id"`
+
+	// Ready indicates the daemon is ready to receive requests.
+	Ready bool `json:"ready,omitempty" yaml:"ready,omitempty"`
+
+	// Checksum is the SHA-256 checksum of the daemon's executor. It is set
+	// in the response that indicates the daemon is ready.
+	Checksum string `json:"checksum,omitempty" yaml:"checksum,omitempty"`
+
+	// ExitCode is the code with which the executor would have exited had
+	// the command been executed by invoking the executor.
+	ExitCode int `json:"exitCode" yaml:"exitCode"`
+
+	// Stdout is the output of the command.
+	Stdout []byte `json:"stdout,omitempty" yaml:"stdout,omitempty"`
+
+	// Stderr is the error output of the command.
+	Stderr string `json:"stderr,omitempty" yaml:"stderr,omitempty"`
+}
+
 // NewStorageExecutor is a function that constructs a new StorageExecutors.
 type NewStorageExecutor func() StorageExecutor
 
@@ -229,6 +229,7 @@ type VolumeCreateOpts struct {
 	Type             *string
 	Encrypted        *bool
 	EncryptionKey    *string
+	Class            *string
 	Opts             Store
 }
 
@@ -251,6 +252,35 @@ type VolumeRemoveOpts struct {
 	Opts  Store
 }
 
+// ValidateOpts are options for validating an operation that is dry-run.
+type ValidateOpts struct {
+
+	// Operation is the name of the route that would perform the operation,
+	// for example "volumeCreate" or "snapshotRemove".
+	Operation string
+
+	// Volume is the volume the operation targets. It is nil for operations
+	// that do not target an existing volume.
+	Volume *Volume
+
+	// Snapshot is the snapshot the operation targets. It is nil for
+	// operations that do not target an existing snapshot.
+	Snapshot *Snapshot
+
+	// Name is the name of the volume or snapshot the operation would
+	// create.
+	Name string
+
+	// Force is the value of the operation's force flag.
+	Force bool
+
+	// CreateOpts are the options of an operation that would create a
+	// volume.
+	CreateOpts *VolumeCreateOpts
+
+	Opts Store
+}
+
 // StorageDriverManager is the management wrapper for a StorageDriver.
 type StorageDriverManager interface {
 	StorageDriver
@@ -375,3 +405,40 @@ type StorageDriverWithLogin interface {
 	Login(
 		ctx Context) (interface{}, error)
 }
+
+// StorageDriverWithValidate is a StorageDriver with a Validate function.
+type StorageDriverWithValidate interface {
+	StorageDriver
+
+	// Validate performs backend-specific checks of an operation that is
+	// dry-run, such as whether the combination of a volume's type and IOPS
+	// is supported. An error is returned if the operation would fail.
+	Validate(
+		ctx Context,
+		opts *ValidateOpts) error
+}
+
+// StorageDriverWithAvailabilityZones is a StorageDriver with an
+// AvailabilityZones function.
+type StorageDriverWithAvailabilityZones interface {
+	StorageDriver
+
+	// AvailabilityZones returns the zones in which the driver can provision
+	// volumes.
+	AvailabilityZones(
+		ctx Context) ([]string, error)
+}
+
+// StorageDriverWithLabels is a StorageDriver that persists the labels the
+// server records on the volumes and snapshots it creates, such as their
+// owner, namespace, and storage class.
+type StorageDriverWithLabels interface {
+	StorageDriver
+
+	// PersistsLabels returns a flag indicating whether or not the driver
+	// persists the labels in the "opts" store of the requests that create
+	// volumes and snapshots, returning them in the Fields of the volumes and
+	// snapshots it lists and inspects.
+	PersistsLabels(
+		ctx Context) bool
+}
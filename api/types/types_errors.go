@@ -52,6 +52,47 @@ type ErrBatchProcess struct{ goof.Goof }
 // string.
 type ErrBadFilter struct{ goof.Goof }
 
+// ErrAvailabilityZoneMismatch occurs when a volume cannot be attached to an
+// instance because the volume and instance are in different zones.
+type ErrAvailabilityZoneMismatch struct{ goof.Goof }
+
+// ErrBadStorageClass occurs when a request refers to a storage class that
+// is not defined for the service.
+type ErrBadStorageClass struct{ goof.Goof }
+
+// ErrQuotaExceeded occurs when a request would cause the usage of a scope
+// to exceed one of the scope's quota limits.
+type ErrQuotaExceeded struct{ goof.Goof }
+
+// ErrQuotaSizeRequired occurs when a request to create a volume does not
+// specify the volume's size while a quota limits the size of volumes.
+type ErrQuotaSizeRequired struct{ goof.Goof }
+
+// ErrNamespaceDenied occurs when a request that is not from an admin asks for
+// a namespace other than the caller's namespace.
+type ErrNamespaceDenied struct{ goof.Goof }
+
+// ErrPolicyDenied occurs when the server's policy denies an operation.
+type ErrPolicyDenied struct{ goof.Goof }
+
+// ErrServiceTimeout occurs when a service does not complete its part of a
+// request for multiple services within the service's timeout.
+type ErrServiceTimeout struct{ goof.Goof }
+
+// ErrPartialResult occurs when a request for multiple services fails for
+// some, but not all, of the services. The results of the services that
+// succeeded are returned along with this error.
+type ErrPartialResult struct {
+	// Errors are the errors of the services that failed, keyed by the names
+	// of the services.
+	Errors map[string]*ServiceError `json:"errors" yaml:"errors"`
+}
+
+// Error returns the error string.
+func (e *ErrPartialResult) Error() string {
+	return "partial result"
+}
+
 // ErrMissingStorageService occurs when the storage service is expected in
 // the provided context but is not there.
 var ErrMissingStorageService = goof.New("missing storage service")
@@ -60,22 +101,22 @@ var ErrMissingStorageService = goof.New("missing storage service")
 type ErrSecTokInvalid struct {
 	// InvalidToken is a flag that indicates whether or not the token was able
 	// to be parsed at all.
-	InvalidToken bool `json:"invalidToken"`
+	InvalidToken bool `json:"invalidToken" yaml:"invalidToken"`
 
 	// InvalidSig is a flag that indicates whether or not the security token
 	// has a valid signature.
-	InvalidSig bool `json:"invalidSig"`
+	InvalidSig bool `json:"invalidSig" yaml:"invalidSig"`
 
 	// MissingClaim is empty if all claims are missing or set to the name
 	// of the first, detected, missing claim.
-	MissingClaim string `json:"claim"`
+	MissingClaim string `json:"claim" yaml:"claim"`
 
 	// Denied is a flag that indicates whether or not the security token
 	// was denied access.
 	Denied bool
 
 	// InnerError is the inner error that caused this one.
-	InnerError error `json:"innerError,omitempty"`
+	InnerError error `json:"innerError,omitempty" yaml:"innerError,omitempty"`
 }
 
 // Error returns the error string.
@@ -1,5 +1,9 @@
 package types
 
+import (
+	"strings"
+)
+
 // All header names below follow the Golang canonical format for header keys.
 // Please do not alter their casing to your liking or you will break stuff.
 const (
@@ -22,4 +26,57 @@ const (
 	// AuthorizationHeader is the HTTP header that contains the Authorization
 	// information.
 	AuthorizationHeader = "Authorization"
+
+	// AdminTokenHeader is the HTTP header that contains the server's admin
+	// token when accessing the admin API.
+	AdminTokenHeader = "Libstorage-Admintoken"
+
+	// DebugHeader is the HTTP header that requests debug logging for the
+	// scope of a single request.
+	DebugHeader = "Libstorage-Debug"
+
+	// DigestHeader is the HTTP header that contains the SHA-256 digest of
+	// an executor.
+	DigestHeader = "Digest"
+
+	// ExecutorSignatureHeader is the HTTP header that contains the detached
+	// signature of an executor.
+	ExecutorSignatureHeader = "Libstorage-Executorsignature"
+)
+
+// ContentType is the media type of an HTTP request or response body.
+type ContentType string
+
+const (
+	// ContentTypeJSON is the JSON media type. It is the default media type
+	// for requests and responses.
+	ContentTypeJSON ContentType = "application/json"
+
+	// ContentTypeYAML is the YAML media type. Clients request it with the
+	// Accept header and send it with the Content-Type header.
+	ContentTypeYAML ContentType = "application/yaml"
 )
+
+// IsYAML returns a flag indicating whether or not the provided media type
+// is one of the names commonly used for YAML.
+func (c ContentType) IsYAML() bool {
+	switch c {
+	case ContentTypeYAML, "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of the media type.
+func (c ContentType) String() string {
+	return string(c)
+}
+
+// ParseContentType parses the media type from the value of a Content-Type
+// header, ignoring any parameters such as the charset.
+func ParseContentType(header string) ContentType {
+	if i := strings.IndexByte(header, ';'); i >= 0 {
+		header = header[:i]
+	}
+	return ContentType(strings.ToLower(strings.TrimSpace(header)))
+}
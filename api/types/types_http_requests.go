@@ -6,49 +6,50 @@ type NewRequestObjFunc func() interface{}
 
 // VolumeCreateRequest is the JSON body for creating a new volume.
 type VolumeCreateRequest struct {
-	Name             string                 `json:"name"`
-	AvailabilityZone *string                `json:"availabilityZone,omitempty"`
-	Encrypted        *bool                  `json:"encrypted,omitempty"`
-	EncryptionKey    *string                `json:"encryptionKey,omitempty"`
-	IOPS             *int64                 `json:"iops,omitempty"`
-	Size             *int64                 `json:"size,omitempty"`
-	Type             *string                `json:"type,omitempty"`
-	Opts             map[string]interface{} `json:"opts,omitempty"`
+	Name             string                 `json:"name" yaml:"name"`
+	AvailabilityZone *string                `json:"availabilityZone,omitempty" yaml:"availabilityZone,omitempty"`
+	Encrypted        *bool                  `json:"encrypted,omitempty" yaml:"encrypted,omitempty"`
+	EncryptionKey    *string                `json:"encryptionKey,omitempty" yaml:"encryptionKey,omitempty"`
+	IOPS             *int64                 `json:"iops,omitempty" yaml:"iops,omitempty"`
+	Size             *int64                 `json:"size,omitempty" yaml:"size,omitempty"`
+	Type             *string                `json:"type,omitempty" yaml:"type,omitempty"`
+	Class            *string                `json:"class,omitempty" yaml:"class,omitempty"`
+	Opts             map[string]interface{} `json:"opts,omitempty" yaml:"opts,omitempty"`
 }
 
 // VolumeCopyRequest is the JSON body for copying a volume.
 type VolumeCopyRequest struct {
-	VolumeName string                 `json:"volumeName"`
-	Opts       map[string]interface{} `json:"opts,omitempty"`
+	VolumeName string                 `json:"volumeName" yaml:"volumeName"`
+	Opts       map[string]interface{} `json:"opts,omitempty" yaml:"opts,omitempty"`
 }
 
 // VolumeSnapshotRequest is the JSON body for snapshotting a volume.
 type VolumeSnapshotRequest struct {
-	SnapshotName string                 `json:"snapshotName"`
-	Opts         map[string]interface{} `json:"opts,omitempty"`
+	SnapshotName string                 `json:"snapshotName" yaml:"snapshotName"`
+	Opts         map[string]interface{} `json:"opts,omitempty" yaml:"opts,omitempty"`
 }
 
 // VolumeAttachRequest is the JSON body for attaching a volume to an instance.
 type VolumeAttachRequest struct {
-	Force          bool                   `json:"force,omitempty"`
-	NextDeviceName *string                `json:"nextDeviceName,omitempty"`
-	Opts           map[string]interface{} `json:"opts,omitempty"`
+	Force          bool                   `json:"force,omitempty" yaml:"force,omitempty"`
+	NextDeviceName *string                `json:"nextDeviceName,omitempty" yaml:"nextDeviceName,omitempty"`
+	Opts           map[string]interface{} `json:"opts,omitempty" yaml:"opts,omitempty"`
 }
 
 // VolumeDetachRequest is the JSON body for detaching a volume from an instance.
 type VolumeDetachRequest struct {
-	Force bool                   `json:"force,omitempty"`
-	Opts  map[string]interface{} `json:"opts,omitempty"`
+	Force bool                   `json:"force,omitempty" yaml:"force,omitempty"`
+	Opts  map[string]interface{} `json:"opts,omitempty" yaml:"opts,omitempty"`
 }
 
 // SnapshotCopyRequest is the JSON body for copying a snapshot.
 type SnapshotCopyRequest struct {
-	SnapshotName  string                 `json:"snapshotName"`
-	DestinationID string                 `json:"destinationID"`
-	Opts          map[string]interface{} `json:"opts,omitempty"`
+	SnapshotName  string                 `json:"snapshotName" yaml:"snapshotName"`
+	DestinationID string                 `json:"destinationID" yaml:"destinationID"`
+	Opts          map[string]interface{} `json:"opts,omitempty" yaml:"opts,omitempty"`
 }
 
 // SnapshotRemoveRequest is the JSON body for removing a snapshot.
 type SnapshotRemoveRequest struct {
-	Opts map[string]interface{} `json:"opts,omitempty"`
+	Opts map[string]interface{} `json:"opts,omitempty" yaml:"opts,omitempty"`
 }
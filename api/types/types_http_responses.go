@@ -1,8 +1,129 @@
 package types
 
+import (
+	"encoding/json"
+)
+
 // VolumeAttachResponse is the JSON response for attaching a volume to an
 // instance.
 type VolumeAttachResponse struct {
-	Volume      *Volume `json:"volume"`
-	AttachToken string  `json:"attachToken"`
+	Volume      *Volume `json:"volume" yaml:"volume"`
+	AttachToken string  `json:"attachToken" yaml:"attachToken"`
+}
+
+// DryRunResponse is the JSON response for a mutating request that is dry-run
+// with the "dryRun" query parameter.
+type DryRunResponse struct {
+
+	// Operation is the name of the route that would perform the operation.
+	Operation string `json:"operation" yaml:"operation"`
+
+	// Service is the name of the service that would perform the operation.
+	Service string `json:"service" yaml:"service"`
+
+	// Volume is the volume the operation targets.
+	Volume *Volume `json:"volume,omitempty" yaml:"volume,omitempty"`
+
+	// Snapshot is the snapshot the operation targets.
+	Snapshot *Snapshot `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+
+	// Request is the request's query parameters and payload arguments.
+	Request map[string]interface{} `json:"request,omitempty" yaml:"request,omitempty"`
+
+	// Errors are the reasons the operation would fail. The operation would
+	// succeed if there are no errors.
+	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// VolumeWatchResponse is the JSON response for watching a service's volumes
+// for changes.
+type VolumeWatchResponse struct {
+
+	// Revision is the revision of the service's volume set. It is the value
+	// of the "since" query parameter for the next watch.
+	Revision int64 `json:"revision" yaml:"revision"`
+
+	// Reset is a flag that indicates the changes since the requested
+	// revision are no longer known. Added contains the entire volume set,
+	// and volumes not in it should be considered removed.
+	Reset bool `json:"reset,omitempty" yaml:"reset,omitempty"`
+
+	// Added are the volumes created since the requested revision.
+	Added VolumeMap `json:"added,omitempty" yaml:"added,omitempty"`
+
+	// Modified are the volumes changed since the requested revision.
+	Modified VolumeMap `json:"modified,omitempty" yaml:"modified,omitempty"`
+
+	// Removed are the IDs of the volumes removed since the requested
+	// revision.
+	Removed []string `json:"removed,omitempty" yaml:"removed,omitempty"`
+}
+
+// ServiceError is the error a service encountered while processing its part
+// of a request for multiple services.
+type ServiceError struct {
+
+	// Message is the error message.
+	Message string `json:"message" yaml:"message"`
+
+	// Timeout is a flag that indicates the service did not complete its part
+	// of the request within the service's timeout.
+	Timeout bool `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// PartialServiceVolumeMap is the JSON response for listing or detaching the
+// volumes of multiple services when partial results are enabled. It is
+// encoded as a ServiceVolumeMap with an additional "errors" key that maps
+// the names of the services that failed to their errors.
+type PartialServiceVolumeMap struct {
+	Volumes ServiceVolumeMap
+	Errors  map[string]*ServiceError
+}
+
+// MarshalJSON marshals the object to JSON.
+func (m *PartialServiceVolumeMap) MarshalJSON() ([]byte, error) {
+	obj := map[string]interface{}{}
+	for k, v := range m.Volumes {
+		obj[k] = v
+	}
+	if len(m.Errors) > 0 {
+		obj["errors"] = m.Errors
+	}
+	return json.Marshal(obj)
+}
+
+// MarshalYAML returns the object to marshal to the YAML representation of the
+// map. It has the same keys as the JSON representation.
+func (m *PartialServiceVolumeMap) MarshalYAML() (interface{}, error) {
+	obj := map[string]interface{}{}
+	for k, v := range m.Volumes {
+		obj[k] = v
+	}
+	if len(m.Errors) > 0 {
+		obj["errors"] = m.Errors
+	}
+	return obj, nil
+}
+
+// UnmarshalJSON unmarshals the object from JSON.
+func (m *PartialServiceVolumeMap) UnmarshalJSON(data []byte) error {
+	obj := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	m.Volumes = ServiceVolumeMap{}
+	for k, v := range obj {
+		if k == "errors" {
+			if err := json.Unmarshal(v, &m.Errors); err != nil {
+				return err
+			}
+			continue
+		}
+		vm := VolumeMap{}
+		if err := json.Unmarshal(v, &vm); err != nil {
+			return err
+		}
+		m.Volumes[k] = vm
+	}
+	return nil
 }
@@ -24,14 +24,14 @@ type InstanceID struct {
 	// Driver is the name of the StorageExecutor that created the InstanceID
 	// as well as the name of the StorageDriver for which the InstanceID is
 	// valid.
-	Driver string `json:"driver"`
+	Driver string `json:"driver" yaml:"driver"`
 
 	// Service is the name of the libStorage service for which the InstanceID
 	// is valid.
-	Service string `json:"service"`
+	Service string `json:"service" yaml:"service"`
 
 	// Fields is additional, driver specific data about the Instance ID.
-	Fields map[string]string `json:"fields"`
+	Fields map[string]string `json:"fields" yaml:"fields"`
 
 	metadata json.RawMessage
 }
@@ -19,7 +19,7 @@ type LocalDevices struct {
 	// Driver is the name of the StorageExecutor that created the map
 	// as well as the name of the StorageDriver for which the map is
 	// valid.
-	Driver string `json:"driver"`
+	Driver string `json:"driver" yaml:"driver"`
 
 	// DeviceMap is voluem to device mappings.
 	DeviceMap map[string]string `json:"deviceMap,omitempty" yaml:"deviceMap,omitempty"`
@@ -1,6 +1,10 @@
 package types
 
-import "strconv"
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
 
 // StorageType is the type of storage a driver provides.
 type StorageType string
@@ -49,6 +53,9 @@ type Instance struct {
 	// The region from which the object originates.
 	Region string `json:"region,omitempty" yaml:",omitempty"`
 
+	// The zone in which the instance is located.
+	AvailabilityZone string `json:"availabilityZone,omitempty" yaml:"availabilityZone,omitempty"`
+
 	// Fields are additional properties that can be defined for this type.
 	Fields map[string]string `json:"fields,omitempty" yaml:",omitempty"`
 }
@@ -254,7 +261,7 @@ type VolumeAttachment struct {
 // device. This might be a block device, NAS device, object device, etc.
 type VolumeDevice struct {
 	// The name of the device.
-	Name string `json:"name"`
+	Name string `json:"name" yaml:"name"`
 
 	// The ID of the instance to which the device is connected.
 	InstanceID *InstanceID `json:"instanceID,omitempty" yaml:"instanceID,omitempty"`
@@ -269,7 +276,7 @@ type VolumeDevice struct {
 	Region string `json:"region,omitempty" yaml:",omitempty"`
 
 	// The device status.
-	Status string `json:"status"`
+	Status string `json:"status" yaml:"status"`
 
 	// The ID of the volume for which the device is mounted.
 	VolumeID string `json:"volumeID" yaml:"volumeID"`
@@ -279,18 +286,33 @@ type VolumeDevice struct {
 }
 
 // ExecutorInfo contains information about a client-side executor, such as
-// its name and MD5 checksum.
+// its name, platform, checksums, and signature.
 type ExecutorInfo struct {
 
 	// Name is the name of the executor.
-	Name string `json:"name"`
+	Name string `json:"name" yaml:"name"`
+
+	// OS is the operating system for which the executor was built, ex.
+	// linux.
+	OS string `json:"os,omitempty" yaml:",omitempty"`
+
+	// Arch is the architecture for which the executor was built, ex. amd64.
+	Arch string `json:"arch,omitempty" yaml:",omitempty"`
 
-	// MD5Checksum is the MD5 checksum of the executor. This can be used to
-	// determine if a local copy of the executor needs to be updated.
+	// MD5Checksum is the MD5 checksum of the executor. It is provided for
+	// clients that do not support SHA256Checksum.
 	MD5Checksum string `json:"md5checksum" yaml:"md5checksum"`
 
+	// SHA256Checksum is the SHA-256 checksum of the executor. This can be
+	// used to determine if a local copy of the executor needs to be updated.
+	SHA256Checksum string `json:"sha256checksum,omitempty" yaml:",omitempty"`
+
+	// Signature is the base64 encoded, detached Ed25519 signature of the
+	// executor. It is empty if the executor is not signed.
+	Signature string `json:"signature,omitempty" yaml:",omitempty"`
+
 	// Size is the size of the executor in bytes.
-	Size int64 `json:"size"`
+	Size int64 `json:"size" yaml:"size"`
 
 	// LastModified is the time the executor was last modified as an epoch.
 	LastModified int64 `json:"lastModified" yaml:"lastModified"`
@@ -299,22 +321,78 @@ type ExecutorInfo struct {
 // ServiceInfo is information about a service.
 type ServiceInfo struct {
 	// Name is the service's name.
-	Name string `json:"name"`
+	Name string `json:"name" yaml:"name"`
 
 	// Instance is the service's instance.
 	Instance *Instance `json:"instance,omitempty" yaml:",omitempty"`
 
 	// Driver is the name of the driver registered for the service.
-	Driver *DriverInfo `json:"driver"`
+	Driver *DriverInfo `json:"driver" yaml:"driver"`
+
+	// AvailabilityZones are the zones in which the service can provision
+	// volumes.
+	AvailabilityZones []string `json:"availabilityZones,omitempty" yaml:"availabilityZones,omitempty"`
+}
+
+// StorageClass is a named set of defaults for creating volumes.
+type StorageClass struct {
+	// Name is the name of the class.
+	Name string `json:"name" yaml:"name"`
+
+	// Description is a description of the class.
+	Description string `json:"description,omitempty" yaml:",omitempty"`
+
+	// Size is the default size of a volume, in GiB.
+	Size *int64 `json:"size,omitempty" yaml:",omitempty"`
+
+	// Type is the default type of a volume.
+	Type *string `json:"type,omitempty" yaml:",omitempty"`
+
+	// IOPS is the default IOPS of a volume.
+	IOPS *int64 `json:"iops,omitempty" yaml:",omitempty"`
+
+	// Encrypted is a flag that indicates whether or not volumes are
+	// encrypted by default.
+	Encrypted *bool `json:"encrypted,omitempty" yaml:",omitempty"`
+
+	// FsType is the file system type with which clients format volumes.
+	FsType string `json:"fsType,omitempty" yaml:"fsType,omitempty"`
+
+	// Opts are the default driver options.
+	Opts map[string]interface{} `json:"opts,omitempty" yaml:",omitempty"`
+}
+
+// StorageClassMap is a map of storage classes keyed by the names of the
+// classes.
+type StorageClassMap map[string]*StorageClass
+
+// VolumeFieldClass is the key of the Fields entry that records the name of
+// the storage class with which a volume was created.
+const VolumeFieldClass = "class"
+
+// VolumeFieldOwner is the key of the Fields entry that records the auth
+// subject that created a volume or snapshot.
+const VolumeFieldOwner = "owner"
+
+// VolumeFieldNamespace is the key of the Fields entry that records the
+// namespace of a volume or snapshot.
+const VolumeFieldNamespace = "namespace"
+
+// VolumeLabels are the keys of the Fields entries the server records on the
+// volumes and snapshots it creates.
+var VolumeLabels = []string{
+	VolumeFieldClass,
+	VolumeFieldOwner,
+	VolumeFieldNamespace,
 }
 
 // DriverInfo is information about a driver.
 type DriverInfo struct {
 	// Name is the driver's name.
-	Name string `json:"name"`
+	Name string `json:"name" yaml:"name"`
 
 	// Type is the type of storage the driver provides: block, nas, object.
-	Type StorageType `json:"type"`
+	Type StorageType `json:"type" yaml:"type"`
 
 	// NextDevice is the next available device information for the service.
 	NextDevice *NextDeviceInfo `json:"nextDevice,omitempty" yaml:"nextDevice,omitempty"`
@@ -336,14 +414,14 @@ type NextDeviceInfo struct {
 	// Ignore is a flag that indicates whether the client logic should invoke
 	// the GetNextAvailableDeviceName function prior to submitting an
 	// AttachVolume request to the server.
-	Ignore bool `json:"ignore"`
+	Ignore bool `json:"ignore" yaml:"ignore"`
 
 	// Prefix is the first part of a device path's value after the "/dev/"
 	// porition. For example, the prefix in "/dev/xvda" is "xvd".
-	Prefix string `json:"prefix"`
+	Prefix string `json:"prefix" yaml:"prefix"`
 
 	// Pattern is the regex to match the part of a device path after the prefix.
-	Pattern string `json:"pattern"`
+	Pattern string `json:"pattern" yaml:"pattern"`
 }
 
 // TaskState is the possible state of a task.
@@ -383,11 +461,73 @@ type Task struct {
 	StartTime int64 `json:"startTime,omitempty" yaml:"startTime,omitempty"`
 
 	// State is the current state of the task.
-	State TaskState `json:"state"`
+	State TaskState `json:"state" yaml:"state"`
 
 	// Result holds the result of the task.
 	Result interface{} `json:"result,omitempty" yaml:",omitempty"`
 
 	// Error contains the error if the task was unsuccessful.
 	Error error `json:"error,omitempty" yaml:",omitempty"`
+
+	// Fields are additional information about the task, such as the policy
+	// decision for the task's operation.
+	Fields map[string]string `json:"fields,omitempty" yaml:",omitempty"`
+}
+
+// taskYAML has the fields of a Task without its MarshalYAML function.
+type taskYAML Task
+
+// MarshalYAML returns the object to marshal to the YAML representation of the
+// task. The task's error is represented as it is in the JSON representation,
+// since errors do not define their YAML representation.
+func (t *Task) MarshalYAML() (interface{}, error) {
+	obj := taskYAML(*t)
+	if obj.Error != nil {
+		obj.Error = &yamlError{obj.Error}
+	}
+	return &obj, nil
+}
+
+// yamlError marshals an error to YAML with the keys and values of its JSON
+// representation.
+type yamlError struct {
+	error
+}
+
+// MarshalYAML returns the object to marshal to the YAML representation of the
+// error.
+func (e *yamlError) MarshalYAML() (interface{}, error) {
+	buf, err := json.Marshal(e.error)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return yamlNumbers(v), nil
+}
+
+// yamlNumbers replaces the numbers of a value decoded from JSON with int64
+// or float64 values so they are written to YAML as numbers.
+func yamlNumbers(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case json.Number:
+		if i, err := tv.Int64(); err == nil {
+			return i
+		}
+		f, _ := tv.Float64()
+		return f
+	case map[string]interface{}:
+		for k, v := range tv {
+			tv[k] = yamlNumbers(v)
+		}
+	case []interface{}:
+		for i, v := range tv {
+			tv[i] = yamlNumbers(v)
+		}
+	}
+	return v
 }
@@ -0,0 +1,122 @@
+package types
+
+// PolicyAction is the action a policy rule takes when it matches an
+// operation.
+type PolicyAction string
+
+const (
+	// PolicyActionAllow allows the operation without evaluating any further
+	// rules.
+	PolicyActionAllow PolicyAction = "allow"
+
+	// PolicyActionDeny denies the operation.
+	PolicyActionDeny PolicyAction = "deny"
+
+	// PolicyActionMutate modifies the operation's request and continues to
+	// evaluate the remaining rules.
+	PolicyActionMutate PolicyAction = "mutate"
+)
+
+// PolicyRules is the content of a policy rules file.
+type PolicyRules struct {
+
+	// Default is the action taken when no allow or deny rule matches an
+	// operation. Only PolicyActionAllow and PolicyActionDeny are valid, and
+	// PolicyActionAllow is used if no action is specified.
+	Default PolicyAction `json:"default,omitempty" yaml:"default,omitempty"`
+
+	// Rules are the policy's rules, which are evaluated in order.
+	Rules []*PolicyRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+// PolicyRule is a single policy rule.
+type PolicyRule struct {
+
+	// Name is the rule's name.
+	Name string `json:"name" yaml:"name"`
+
+	// Operations are the names of the operations to which the rule applies.
+	// A rule with no operations applies to all operations.
+	Operations []string `json:"operations,omitempty" yaml:"operations,omitempty"`
+
+	// When is the expression that must be true for the rule to match. A rule
+	// without an expression matches all operations to which it applies.
+	When string `json:"when,omitempty" yaml:"when,omitempty"`
+
+	// Action is the action the rule takes when it matches.
+	Action PolicyAction `json:"action" yaml:"action"`
+
+	// Reason is the reason recorded when the rule matches.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+
+	// Set are the request fields set by a mutating rule.
+	Set map[string]interface{} `json:"set,omitempty" yaml:"set,omitempty"`
+
+	// Max are the maximum values of numeric request fields, such as size and
+	// iops, to which a mutating rule clamps the request.
+	Max map[string]int64 `json:"max,omitempty" yaml:"max,omitempty"`
+
+	// Fields are the custom fields, or tags, a mutating rule adds to a
+	// request if the request does not already specify them.
+	Fields map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// PolicyInput is the information about an operation against which a policy
+// is evaluated. It is also the body of the requests sent to a policy
+// decision endpoint.
+type PolicyInput struct {
+
+	// Operation is the name of the operation, ex. volumeCreate.
+	Operation string `json:"operation" yaml:"operation"`
+
+	// Service is the name of the storage service.
+	Service string `json:"service" yaml:"service"`
+
+	// Driver is the name of the storage service's driver.
+	Driver string `json:"driver" yaml:"driver"`
+
+	// Subject is the subject of the request's auth token.
+	Subject string `json:"subject,omitempty" yaml:"subject,omitempty"`
+
+	// Roles are the roles of the request's auth token.
+	Roles []string `json:"roles,omitempty" yaml:"roles,omitempty"`
+
+	// Namespace is the namespace to which the request is scoped.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// Instance is the ID of the instance that made the request.
+	Instance *InstanceID `json:"instance,omitempty" yaml:"instance,omitempty"`
+
+	// Request are the request's fields.
+	Request map[string]interface{} `json:"request" yaml:"request"`
+
+	// Volume is the volume on which the operation is performed.
+	Volume *Volume `json:"volume,omitempty" yaml:"volume,omitempty"`
+
+	// Snapshot is the snapshot on which the operation is performed.
+	Snapshot *Snapshot `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+}
+
+// PolicyDecision is the result of evaluating a policy. It is also the body
+// of the responses returned by a policy decision endpoint.
+type PolicyDecision struct {
+
+	// Allow is a flag indicating whether or not the operation is allowed.
+	Allow bool `json:"allow" yaml:"allow"`
+
+	// Rule is the name of the rule that decided the operation.
+	Rule string `json:"rule,omitempty" yaml:"rule,omitempty"`
+
+	// Reason is the reason for the decision.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+
+	// Set are the request fields set by the policy.
+	Set map[string]interface{} `json:"set,omitempty" yaml:"set,omitempty"`
+
+	// Fields are the custom fields, or tags, the policy adds to the request
+	// if the request does not already specify them.
+	Fields map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"`
+
+	// Mutators are the names of the rules that mutated the request.
+	Mutators []string `json:"mutators,omitempty" yaml:"mutators,omitempty"`
+}
@@ -0,0 +1,105 @@
+package types
+
+// QuotaScopeSubject is the scope of a quota that limits the usage of an
+// auth subject.
+const QuotaScopeSubject = "subject"
+
+// QuotaScopeRole is the scope of a quota that limits the usage of each
+// auth subject that has a role.
+const QuotaScopeRole = "role"
+
+// QuotaScopeService is the scope of a quota that limits the usage of a
+// service.
+const QuotaScopeService = "service"
+
+// QuotaConfig is the server's quota configuration.
+type QuotaConfig struct {
+	// Subjects are the limits of auth subjects, keyed by subject. A subject's
+	// usage is the sum of its usage across all services.
+	Subjects map[string]*QuotaLimits `json:"subjects,omitempty" yaml:"subjects,omitempty"`
+
+	// Roles are the limits of the auth subjects that have a role, keyed by
+	// role. The limits apply to each subject that has the role.
+	Roles map[string]*QuotaLimits `json:"roles,omitempty" yaml:"roles,omitempty"`
+
+	// Services are the limits of services, keyed by service. A service's
+	// usage is the sum of the usage of all of its volumes and snapshots.
+	Services map[string]*QuotaLimits `json:"services,omitempty" yaml:"services,omitempty"`
+}
+
+// QuotaLimits are the limits of a quota. A limit that is not set is not
+// enforced.
+type QuotaLimits struct {
+	// Volumes is the maximum number of volumes.
+	Volumes *int64 `json:"volumes,omitempty" yaml:",omitempty"`
+
+	// Size is the maximum total size of all volumes, in GiB.
+	Size *int64 `json:"size,omitempty" yaml:",omitempty"`
+
+	// IOPS is the maximum total IOPS of all volumes.
+	IOPS *int64 `json:"iops,omitempty" yaml:",omitempty"`
+
+	// Snapshots is the maximum number of snapshots.
+	Snapshots *int64 `json:"snapshots,omitempty" yaml:",omitempty"`
+}
+
+// QuotaUsage is the usage counted against a quota.
+type QuotaUsage struct {
+	// Volumes is the number of volumes.
+	Volumes int64 `json:"volumes" yaml:"volumes"`
+
+	// Size is the total size of all volumes, in GiB.
+	Size int64 `json:"size" yaml:"size"`
+
+	// IOPS is the total IOPS of all volumes.
+	IOPS int64 `json:"iops" yaml:"iops"`
+
+	// Snapshots is the number of snapshots.
+	Snapshots int64 `json:"snapshots" yaml:"snapshots"`
+}
+
+// Add adds the provided usage to this usage.
+func (u *QuotaUsage) Add(o *QuotaUsage) {
+	u.Volumes += o.Volumes
+	u.Size += o.Size
+	u.IOPS += o.IOPS
+	u.Snapshots += o.Snapshots
+}
+
+// SubjectQuota is the usage and limits of an auth subject.
+type SubjectQuota struct {
+	// Subject is the auth subject.
+	Subject string `json:"subject" yaml:"subject"`
+
+	// Usage is the subject's usage across all services.
+	Usage *QuotaUsage `json:"usage" yaml:"usage"`
+
+	// Services is the subject's usage of each service, keyed by service.
+	Services map[string]*QuotaUsage `json:"services,omitempty" yaml:",omitempty"`
+
+	// Limits are the subject's limits.
+	Limits *QuotaLimits `json:"limits,omitempty" yaml:",omitempty"`
+
+	// Roles are the limits of the subject's roles, keyed by role. A
+	// subject's roles are only known for the subject of the request's token.
+	Roles map[string]*QuotaLimits `json:"roles,omitempty" yaml:",omitempty"`
+}
+
+// ServiceQuota is the usage and limits of a service.
+type ServiceQuota struct {
+	// Usage is the service's usage.
+	Usage *QuotaUsage `json:"usage" yaml:"usage"`
+
+	// Limits are the service's limits.
+	Limits *QuotaLimits `json:"limits,omitempty" yaml:",omitempty"`
+}
+
+// QuotaReport is the usage and limits of all auth subjects and services.
+type QuotaReport struct {
+	// Subjects are the quotas of the subjects that own volumes or snapshots
+	// or that have limits, keyed by subject.
+	Subjects map[string]*SubjectQuota `json:"subjects" yaml:"subjects"`
+
+	// Services are the quotas of the services, keyed by service.
+	Services map[string]*ServiceQuota `json:"services" yaml:"services"`
+}
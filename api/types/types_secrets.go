@@ -0,0 +1,26 @@
+package types
+
+const (
+	// SecretRefPrefix is the prefix that identifies a configuration value
+	// as a reference to a secret that should be resolved by a registered
+	// SecretProvider. The full format of a secret reference is
+	// "secret:<provider>:<ref>", for example "secret:env:SCALEIO_PASSWORD".
+	SecretRefPrefix = "secret:"
+
+	// SecretMask is the value used in place of a resolved secret whenever
+	// configuration data is printed or logged.
+	SecretMask = "******"
+)
+
+// NewSecretProvider is a function that constructs a new SecretProvider.
+type NewSecretProvider func() SecretProvider
+
+// SecretProvider is a type that resolves secret references in configuration
+// values to the actual secret.
+type SecretProvider interface {
+	Driver
+
+	// Resolve returns the secret for the provided reference. The reference
+	// is the part of a secret reference that follows the provider name.
+	Resolve(ctx Context, ref string) (string, error)
+}
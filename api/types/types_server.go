@@ -17,6 +17,9 @@ const (
 
 	// TCPEndpoint is a TCP endpoint.
 	TCPEndpoint
+
+	// GRPCEndpoint is a TCP endpoint that serves the gRPC API.
+	GRPCEndpoint
 )
 
 // String returns the endpoint type's string representation.
@@ -26,6 +29,8 @@ func (t EndpointType) String() string {
 		return "unix"
 	case TCPEndpoint:
 		return "tcp"
+	case GRPCEndpoint:
+		return "grpc"
 	default:
 		return ""
 	}
@@ -39,10 +44,47 @@ func ParseEndpointType(str string) EndpointType {
 		return UnixEndpoint
 	case "tcp":
 		return TCPEndpoint
+	case "grpc":
+		return GRPCEndpoint
 	}
 	return UnknownEndpointType
 }
 
+// Endpoint is the configuration of a server endpoint.
+type Endpoint struct {
+
+	// Name is the endpoint's name.
+	Name string
+
+	// Services are the names of the storage services the endpoint exposes.
+	// An endpoint without services exposes all of the server's services.
+	Services []string
+
+	// Auth is the endpoint's auth configuration. A nil value indicates no
+	// auth is required by the endpoint.
+	Auth *AuthConfig
+}
+
+// HasService returns a flag indicating whether or not the endpoint exposes
+// the storage service with the provided name.
+func (e *Endpoint) HasService(name string) bool {
+	if len(e.Services) == 0 {
+		return true
+	}
+	for _, s := range e.Services {
+		if strings.EqualFold(s, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthDisabled returns a flag indicating whether or not the endpoint's auth
+// configuration disables auth, including the auth of the storage services.
+func (e *Endpoint) AuthDisabled() bool {
+	return e.Auth != nil && e.Auth.Disabled
+}
+
 // Server is the interface for a libStorage server.
 type Server interface {
 	io.Closer
@@ -52,4 +94,35 @@ type Server interface {
 
 	// Addrs returns the server's configured endpoint addresses.
 	Addrs() []string
+
+	// API returns an API client that invokes the routes of the server's
+	// first endpoint in process. The client's requests are not encoded as
+	// HTTP messages, and the objects it returns may be shared with the
+	// server's caches, so callers must not modify them.
+	API() APIClient
+}
+
+// EmbeddedRequest is a request of the API client of an embedded server. The
+// request is handled in process, so the values that an HTTP request provides
+// with its headers and payload are provided by the request's fields.
+type EmbeddedRequest struct {
+
+	// Transaction is the request's transaction.
+	Transaction *Transaction
+
+	// InstanceIDs are the instance IDs provided with the request.
+	InstanceIDs []*InstanceID
+
+	// LocalDevices are the local devices provided with the request.
+	LocalDevices []*LocalDevices
+
+	// AuthToken is the encoded security token provided with the request.
+	AuthToken string
+
+	// Debug is a flag indicating whether or not debug logging is requested
+	// for the scope of the request.
+	Debug bool
+
+	// Body is the request's payload, such as a *VolumeCreateRequest.
+	Body interface{}
 }
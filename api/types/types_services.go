@@ -39,6 +39,9 @@ type StorageService interface {
 
 	// AuthConfig returns the storage service's authentication configuration.
 	AuthConfig() *AuthConfig
+
+	// StorageClasses returns the storage service's storage classes.
+	StorageClasses() StorageClassMap
 }
 
 // TaskTrackingService a service for tracking tasks.
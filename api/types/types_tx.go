@@ -9,6 +9,10 @@ import (
 	"github.com/akutz/goof"
 )
 
+// TransactionEnvVar is the name of the environment variable used to provide
+// an executor with the transaction of the operation that invoked it.
+const TransactionEnvVar = "LIBSTORAGE_TX"
+
 // TxTimestamp is a transaction's timestamp.
 type TxTimestamp time.Time
 
@@ -19,7 +23,7 @@ type Transaction struct {
 	ID *UUID `json:"id" yaml:"id"`
 
 	// Created is the UTC timestampe at which the transaction was created.
-	Created TxTimestamp `json:"created"`
+	Created TxTimestamp `json:"created" yaml:"created"`
 }
 
 // NewTransaction returns a new transaction.
@@ -13,6 +13,7 @@ import (
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
+	"github.com/codedellemc/libstorage/api/utils/secrets"
 )
 
 // NewConfig returns a new configuration instance.
@@ -44,6 +45,10 @@ func NewConfig(ctx types.Context) (gofig.Config, error) {
 
 	types.BackCompat(config)
 
+	if err := secrets.Resolve(ctx, config); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
@@ -0,0 +1,238 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// Policy is a compiled set of policy rules.
+type Policy struct {
+	defaultAction types.PolicyAction
+	rules         []*rule
+}
+
+type rule struct {
+	*types.PolicyRule
+	ops  map[string]bool
+	when *Expr
+}
+
+// ReadFile reads and compiles the policy rules in a YAML or JSON file.
+func ReadFile(path string) (*Policy, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"path", path, "error reading policy rules", err)
+	}
+	p, err := Parse(buf)
+	if err != nil {
+		return nil, goof.WithFieldE(
+			"path", path, "error parsing policy rules", err)
+	}
+	return p, nil
+}
+
+// Parse compiles the policy rules in the provided YAML or JSON.
+func Parse(buf []byte) (*Policy, error) {
+
+	buf, err := utils.YAMLToJSON(buf)
+	if err != nil {
+		return nil, err
+	}
+	rules := &types.PolicyRules{}
+	if err := json.Unmarshal(buf, rules); err != nil {
+		return nil, err
+	}
+	return New(rules)
+}
+
+// New compiles the provided policy rules.
+func New(rules *types.PolicyRules) (*Policy, error) {
+
+	p := &Policy{defaultAction: rules.Default}
+	switch p.defaultAction {
+	case "":
+		p.defaultAction = types.PolicyActionAllow
+	case types.PolicyActionAllow, types.PolicyActionDeny:
+	default:
+		return nil, goof.WithField(
+			"default", rules.Default, "invalid default policy action")
+	}
+
+	for i, pr := range rules.Rules {
+		if pr == nil {
+			continue
+		}
+		r := &rule{PolicyRule: pr, ops: map[string]bool{}}
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rules[%d]", i)
+		}
+		switch r.Action {
+		case types.PolicyActionAllow,
+			types.PolicyActionDeny,
+			types.PolicyActionMutate:
+		default:
+			return nil, goof.WithFields(goof.Fields{
+				"rule":   r.Name,
+				"action": r.Action,
+			}, "invalid policy action")
+		}
+		for _, op := range r.Operations {
+			r.ops[strings.ToLower(op)] = true
+		}
+		if r.When != "" {
+			when, err := Compile(r.When)
+			if err != nil {
+				return nil, goof.WithFieldE(
+					"rule", r.Name, "invalid policy expression", err)
+			}
+			r.when = when
+		}
+		p.rules = append(p.rules, r)
+	}
+
+	return p, nil
+}
+
+// Len returns the number of rules in the policy.
+func (p *Policy) Len() int {
+	return len(p.rules)
+}
+
+// Evaluate evaluates the policy's rules, in order, against the provided
+// input. The first allow or deny rule that matches decides the operation,
+// while matching mutate rules accumulate their changes to the request, which
+// are visible to the rules that follow them. The policy's default action
+// decides an operation that no allow or deny rule matches.
+func (p *Policy) Evaluate(
+	in *types.PolicyInput) (*types.PolicyDecision, error) {
+
+	env, err := NewEnv(in)
+	if err != nil {
+		return nil, err
+	}
+	req, ok := env["request"].(map[string]interface{})
+	if !ok {
+		req = map[string]interface{}{}
+		env["request"] = req
+	}
+
+	d := &types.PolicyDecision{}
+	op := strings.ToLower(in.Operation)
+
+	for _, r := range p.rules {
+
+		if len(r.ops) > 0 && !r.ops[op] {
+			continue
+		}
+		if r.when != nil {
+			ok, err := r.when.EvalBool(env)
+			if err != nil {
+				return nil, goof.WithFieldE(
+					"rule", r.Name, "error evaluating policy rule", err)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		if r.Action != types.PolicyActionMutate {
+			d.Allow = r.Action == types.PolicyActionAllow
+			d.Rule = r.Name
+			d.Reason = r.Reason
+			return d, nil
+		}
+
+		for k, v := range r.Set {
+			setRequest(d, req, k, v)
+		}
+		for k, limit := range r.Max {
+			if v, ok := number(getValue(req, k)); ok && v > float64(limit) {
+				setRequest(d, req, k, limit)
+			}
+		}
+		for k, v := range r.Fields {
+			if d.Fields == nil {
+				d.Fields = map[string]string{}
+			}
+			if _, ok := d.Fields[k]; !ok {
+				d.Fields[k] = v
+			}
+		}
+		d.Mutators = append(d.Mutators, r.Name)
+	}
+
+	d.Allow = p.defaultAction == types.PolicyActionAllow
+	if !d.Allow {
+		d.Reason = "no policy rule allows the operation"
+	}
+	return d, nil
+}
+
+// NewEnv returns the environment against which expressions are evaluated
+// for the provided input.
+func NewEnv(in *types.PolicyInput) (map[string]interface{}, error) {
+	buf, err := json.Marshal(in)
+	if err != nil {
+		return nil, goof.WithError("error encoding policy input", err)
+	}
+	env := map[string]interface{}{}
+	if err := json.Unmarshal(buf, &env); err != nil {
+		return nil, goof.WithError("error encoding policy input", err)
+	}
+	return env, nil
+}
+
+// setRequest sets a field of the request both in the decision and in the
+// environment's request so that the rules that follow observe the change
+func setRequest(
+	d *types.PolicyDecision,
+	req map[string]interface{},
+	k string, v interface{}) {
+
+	if d.Set == nil {
+		d.Set = map[string]interface{}{}
+	}
+	d.Set[k] = v
+
+	// request fields are case-insensitive
+	for rk := range req {
+		if strings.EqualFold(rk, k) {
+			delete(req, rk)
+		}
+	}
+	req[k] = v
+}
+
+// Int64 returns the integer value of a numeric value that a policy sets. The
+// values set by a policy endpoint are decoded from JSON as float64 values,
+// which may be in exponent form, ex. 1e+06, when formatted as strings, so
+// they are converted explicitly.
+func Int64(v interface{}) (int64, bool) {
+	switch tv := v.(type) {
+	case int64:
+		return tv, true
+	case int:
+		return int64(tv), true
+	case json.Number:
+		if i, err := tv.Int64(); err == nil {
+			return i, true
+		}
+		f, err := tv.Float64()
+		return int64(f), err == nil
+	}
+	f, ok := number(v)
+	return int64(f), ok
+}
+
+func getValue(m map[string]interface{}, k string) interface{} {
+	v, _ := pathNode{k}.eval(m)
+	return v
+}
@@ -0,0 +1,624 @@
+/*
+Package policy provides the rules and the expression language used to decide
+whether or not a mutating operation is allowed.
+
+An expression is evaluated against a policy input, the fields of which are
+referenced by their JSON names, ex. "service", "subject", "request.size",
+"volume.fields.owner", or "instance.id". Names are matched case-insensitively
+and missing names evaluate to null.
+
+Expressions support string, number, boolean, null, and list literals, the
+operators "==", "!=", "<", "<=", ">", ">=", "in", "&&", "||", and "!", and
+parentheses. The functions "hasRole(role)", "contains(listOrString, value)",
+and "lower(string)" are also available. Any value may be used as a boolean;
+null, false, zero, and empty strings, lists, and maps are false.
+*/
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/akutz/goof"
+)
+
+// Expr is a compiled policy expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// String returns the expression's source.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression against the provided environment.
+func (e *Expr) Eval(env map[string]interface{}) (interface{}, error) {
+	return e.root.eval(env)
+}
+
+// EvalBool evaluates the expression against the provided environment and
+// returns the truthiness of the result.
+func (e *Expr) EvalBool(env map[string]interface{}) (bool, error) {
+	v, err := e.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+// Compile compiles a policy expression.
+func Compile(s string) (*Expr, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: s, toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return &Expr{src: s, root: root}, nil
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+var twoCharOps = []string{"==", "!=", "<=", ">=", "&&", "||"}
+
+func lex(s string) ([]*token, error) {
+	var toks []*token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c):
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || s[j] == '-' ||
+				unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			toks = append(toks, &token{tokIdent, s[i:j], i})
+			i = j
+		case unicode.IsDigit(c):
+			j := i + 1
+			for j < len(s) && (s[j] == '.' || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			toks = append(toks, &token{tokNumber, s[i:j], i})
+			i = j
+		case c == '\'' || c == '"':
+			j := strings.IndexRune(s[i+1:], c)
+			if j < 0 {
+				return nil, goof.WithFields(goof.Fields{
+					"expr": s,
+					"pos":  i,
+				}, "unterminated string")
+			}
+			toks = append(toks, &token{tokString, s[i+1 : i+1+j], i})
+			i += j + 2
+		default:
+			op := s[i : i+1]
+			if i+1 < len(s) {
+				for _, o := range twoCharOps {
+					if s[i:i+2] == o {
+						op = o
+						break
+					}
+				}
+			}
+			if !strings.Contains("=!<>&|()[],.", op[:1]) ||
+				op == "=" || op == "&" || op == "|" {
+				return nil, goof.WithFields(goof.Fields{
+					"expr": s,
+					"pos":  i,
+				}, fmt.Sprintf("unexpected %q", op))
+			}
+			toks = append(toks, &token{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	return append(toks, &token{tokEOF, "", len(s)}), nil
+}
+
+type parser struct {
+	src  string
+	toks []*token
+	pos  int
+}
+
+func (p *parser) peek() *token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() *token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOp(ops ...string) bool {
+	t := p.peek()
+	if t.kind != tokOp && t.kind != tokIdent {
+		return false
+	}
+	for _, o := range ops {
+		if t.text == o {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if t := p.next(); t.kind != tokOp || t.text != op {
+		return p.errorf(t, "expected %q", op)
+	}
+	return nil
+}
+
+func (p *parser) errorf(t *token, format string, args ...interface{}) error {
+	return goof.WithFields(goof.Fields{
+		"expr": p.src,
+		"pos":  t.pos,
+	}, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseOr() (node, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.next()
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = &orNode{l, r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	l, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.next()
+		r, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l = &andNode{l, r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.peek().kind == tokOp && p.isOp("!") {
+		p.next()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{x}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (node, error) {
+	l, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp("==", "!=", "<", "<=", ">", ">=", "in") {
+		return l, nil
+	}
+	op := p.next().text
+	r, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return &compareNode{op, l, r}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(t, "invalid number %q", t.text)
+		}
+		return &literalNode{f}, nil
+	case tokString:
+		return &literalNode{t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{true}, nil
+		case "false":
+			return &literalNode{false}, nil
+		case "null":
+			return &literalNode{nil}, nil
+		}
+		if p.isOp("(") {
+			return p.parseCall(t)
+		}
+		path := pathNode{t.text}
+		for p.isOp(".") {
+			p.next()
+			n := p.next()
+			if n.kind != tokIdent {
+				return nil, p.errorf(n, "expected name")
+			}
+			path = append(path, n.text)
+		}
+		return path, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			x, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return x, nil
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return listNode(items), nil
+		}
+	case tokEOF:
+		return nil, p.errorf(t, "unexpected end of expression")
+	}
+	return nil, p.errorf(t, "unexpected %q", t.text)
+}
+
+func (p *parser) parseCall(t *token) (node, error) {
+	fn, ok := funcs[t.text]
+	if !ok {
+		return nil, p.errorf(t, "unknown function %q", t.text)
+	}
+	p.next()
+	args, err := p.parseList(")")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != fn.argc {
+		return nil, p.errorf(
+			t, "%s expects %d argument(s)", t.text, fn.argc)
+	}
+	return &callNode{fn, args}, nil
+}
+
+func (p *parser) parseList(end string) ([]node, error) {
+	var items []node
+	if p.isOp(end) {
+		p.next()
+		return items, nil
+	}
+	for {
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, x)
+		if p.isOp(",") {
+			p.next()
+			continue
+		}
+		if err := p.expect(end); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+}
+
+type node interface {
+	eval(env map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	v interface{}
+}
+
+func (n *literalNode) eval(env map[string]interface{}) (interface{}, error) {
+	return n.v, nil
+}
+
+type pathNode []string
+
+func (n pathNode) eval(env map[string]interface{}) (interface{}, error) {
+	var v interface{} = env
+	for _, k := range n {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		if v, ok = m[k]; ok {
+			continue
+		}
+		v = nil
+		for mk, mv := range m {
+			if strings.EqualFold(mk, k) {
+				v = mv
+				break
+			}
+		}
+	}
+	return v, nil
+}
+
+type listNode []node
+
+func (n listNode) eval(env map[string]interface{}) (interface{}, error) {
+	l := make([]interface{}, len(n))
+	for i, x := range n {
+		v, err := x.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		l[i] = v
+	}
+	return l, nil
+}
+
+type notNode struct {
+	x node
+}
+
+func (n *notNode) eval(env map[string]interface{}) (interface{}, error) {
+	v, err := n.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+type andNode struct {
+	l, r node
+}
+
+func (n *andNode) eval(env map[string]interface{}) (interface{}, error) {
+	l, err := n.l.eval(env)
+	if err != nil || !truthy(l) {
+		return false, err
+	}
+	r, err := n.r.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(r), nil
+}
+
+type orNode struct {
+	l, r node
+}
+
+func (n *orNode) eval(env map[string]interface{}) (interface{}, error) {
+	l, err := n.l.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(l) {
+		return true, nil
+	}
+	r, err := n.r.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(r), nil
+}
+
+type compareNode struct {
+	op   string
+	l, r node
+}
+
+func (n *compareNode) eval(env map[string]interface{}) (interface{}, error) {
+	l, err := n.l.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.r.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(l, r), nil
+	case "!=":
+		return !equal(l, r), nil
+	case "in":
+		return contains(r, l), nil
+	}
+
+	// ordering comparisons with null are always false
+	if l == nil || r == nil {
+		return false, nil
+	}
+
+	var c int
+	lf, lok := number(l)
+	rf, rok := number(r)
+	ls, lsok := l.(string)
+	rs, rsok := r.(string)
+	switch {
+	case lok && rok:
+		switch {
+		case lf < rf:
+			c = -1
+		case lf > rf:
+			c = 1
+		}
+	case lsok && rsok:
+		c = strings.Compare(ls, rs)
+	default:
+		return nil, goof.WithFields(goof.Fields{
+			"left":  l,
+			"right": r,
+		}, fmt.Sprintf("cannot compare values with %q", n.op))
+	}
+
+	switch n.op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
+
+type callNode struct {
+	fn   *function
+	args []node
+}
+
+func (n *callNode) eval(env map[string]interface{}) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, x := range n.args {
+		v, err := x.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return n.fn.call(env, args), nil
+}
+
+type function struct {
+	argc int
+	call func(env map[string]interface{}, args []interface{}) interface{}
+}
+
+var funcs = map[string]*function{
+	"hasRole":  {1, hasRole},
+	"contains": {2, containsFunc},
+	"lower":    {1, lower},
+}
+
+func hasRole(env map[string]interface{}, args []interface{}) interface{} {
+	role, ok := args[0].(string)
+	if !ok {
+		return false
+	}
+	roles, _ := pathNode{"roles"}.eval(env)
+	l, _ := roles.([]interface{})
+	for _, r := range l {
+		if s, ok := r.(string); ok && strings.EqualFold(s, role) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFunc(env map[string]interface{}, args []interface{}) interface{} {
+	return contains(args[0], args[1])
+}
+
+func lower(env map[string]interface{}, args []interface{}) interface{} {
+	if s, ok := args[0].(string); ok {
+		return strings.ToLower(s)
+	}
+	return args[0]
+}
+
+func truthy(v interface{}) bool {
+	switch tv := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return tv
+	case string:
+		return tv != ""
+	case []interface{}:
+		return len(tv) > 0
+	case map[string]interface{}:
+		return len(tv) > 0
+	}
+	if f, ok := number(v); ok {
+		return f != 0
+	}
+	return true
+}
+
+func number(v interface{}) (float64, bool) {
+	switch tv := v.(type) {
+	case float64:
+		return tv, true
+	case float32:
+		return float64(tv), true
+	case int:
+		return float64(tv), true
+	case int64:
+		return float64(tv), true
+	case int32:
+		return float64(tv), true
+	}
+	return 0, false
+}
+
+func equal(l, r interface{}) bool {
+	if lf, ok := number(l); ok {
+		rf, ok := number(r)
+		return ok && lf == rf
+	}
+	switch l.(type) {
+	case nil, bool, string:
+		return l == r
+	}
+	return false
+}
+
+func contains(container, v interface{}) bool {
+	switch tc := container.(type) {
+	case []interface{}:
+		for _, x := range tc {
+			if equal(x, v) {
+				return true
+			}
+		}
+	case string:
+		if s, ok := v.(string); ok {
+			return strings.Contains(tc, s)
+		}
+	case map[string]interface{}:
+		if s, ok := v.(string); ok {
+			_, ok := tc[s]
+			return ok
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const testRules = `
+rules:
+- name: prod-encrypted
+  operations: [volumeCreate]
+  when: service == "prod" && !request.encrypted
+  action: deny
+  reason: volumes in service prod must be encrypted
+- name: max-size
+  operations: [volumeCreate]
+  when: "!hasRole('storage-admin')"
+  action: mutate
+  max:
+    size: 1024
+- name: default-tags
+  action: mutate
+  fields:
+    costCenter: unknown
+- name: oversized
+  when: request.size > 1024
+  action: deny
+  reason: too big
+`
+
+func testInput(
+	service string, size float64, roles ...string) *types.PolicyInput {
+
+	return &types.PolicyInput{
+		Operation: "volumeCreate",
+		Service:   service,
+		Driver:    "vfs",
+		Subject:   "akutz",
+		Roles:     roles,
+		Request:   map[string]interface{}{"size": size},
+	}
+}
+
+func TestCompile(t *testing.T) {
+	env := map[string]interface{}{
+		"service": "prod",
+		"roles":   []interface{}{"Storage-Admin"},
+		"request": map[string]interface{}{
+			"size":             float64(2048),
+			"availabilityzone": "zone-a",
+		},
+	}
+
+	tests := map[string]bool{
+		`service == "prod"`:                                 true,
+		`service != 'prod'`:                                 false,
+		`request.size > 1024 && request.size <= 2048`:       true,
+		`request.size < 1024 || service in ["dev", "prod"]`: true,
+		`!(request.size >= 2048)`:                           false,
+		`request.availabilityZone == "zone-a"`:              true,
+		`request.encrypted`:                                 false,
+		`request.encrypted == null`:                         true,
+		`volume.size > 0`:                                   false,
+		`hasRole("storage-admin")`:                          true,
+		`contains(lower(request.availabilityZone), "-a")`:   true,
+	}
+
+	for s, expected := range tests {
+		e, err := Compile(s)
+		if !assert.NoError(t, err, s) {
+			continue
+		}
+		v, err := e.EvalBool(env)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, v, s)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`service ==`,
+		`service = "prod"`,
+		`(service == "prod"`,
+		`"prod`,
+		`unknown(service)`,
+		`hasRole()`,
+		`service == "prod" service`,
+	} {
+		_, err := Compile(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := Parse([]byte(testRules))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 4, p.Len())
+
+	d, err := p.Evaluate(testInput("prod", 10))
+	assert.NoError(t, err)
+	assert.False(t, d.Allow)
+	assert.Equal(t, "prod-encrypted", d.Rule)
+	assert.Equal(t, "volumes in service prod must be encrypted", d.Reason)
+
+	d, err = p.Evaluate(testInput("dev", 4096))
+	assert.NoError(t, err)
+	assert.True(t, d.Allow)
+	assert.EqualValues(t, 1024, d.Set["size"])
+	assert.Equal(t, "unknown", d.Fields["costCenter"])
+	assert.Equal(t, []string{"max-size", "default-tags"}, d.Mutators)
+
+	d, err = p.Evaluate(testInput("dev", 4096, "storage-admin"))
+	assert.NoError(t, err)
+	assert.False(t, d.Allow)
+	assert.Equal(t, "oversized", d.Rule)
+	assert.Equal(t, []string{"default-tags"}, d.Mutators)
+}
+
+func TestInt64(t *testing.T) {
+	for _, v := range []interface{}{
+		int64(2000000), 2000000, float64(2000000), float32(2000000),
+		json.Number("2000000"), json.Number("2e+06"),
+	} {
+		i, ok := Int64(v)
+		assert.True(t, ok, "%T", v)
+		assert.EqualValues(t, 2000000, i, "%T", v)
+	}
+
+	// a large value decoded from JSON is a float64 that formats in exponent
+	// form, which is converted without going through its string form
+	var m map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"size":1000000}`), &m))
+	i, ok := Int64(m["size"])
+	assert.True(t, ok)
+	assert.EqualValues(t, 1000000, i)
+
+	_, ok = Int64("1024")
+	assert.False(t, ok)
+	_, ok = Int64(nil)
+	assert.False(t, ok)
+}
+
+func TestEvaluateDefaultDeny(t *testing.T) {
+	p, err := Parse([]byte(`
+default: deny
+rules:
+- name: allow-dev
+  when: service == "dev"
+  action: allow
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	d, err := p.Evaluate(testInput("dev", 1))
+	assert.NoError(t, err)
+	assert.True(t, d.Allow)
+	assert.Equal(t, "allow-dev", d.Rule)
+
+	d, err = p.Evaluate(testInput("prod", 1))
+	assert.NoError(t, err)
+	assert.False(t, d.Allow)
+	assert.Empty(t, d.Rule)
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse([]byte("default: maybe\n"))
+	assert.Error(t, err)
+	_, err = Parse([]byte("rules:\n- name: a\n  action: explode\n"))
+	assert.Error(t, err)
+	_, err = Parse([]byte("rules:\n- name: a\n  action: deny\n  when: a ==\n"))
+	assert.Error(t, err)
+}
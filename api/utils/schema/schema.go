@@ -49,6 +49,16 @@ var (
 	// ServiceInfoMapSchema is the JSON schemea for a map[string]*ServiceInfo.
 	ServiceInfoMapSchema = buildSchemaVar("serviceInfoMap")
 
+	// StorageClassMapSchema is the JSON schema for the StorageClassMap
+	// resource.
+	StorageClassMapSchema = buildSchemaVar("storageClassMap")
+
+	// QuotaReportSchema is the JSON schema for the QuotaReport resource.
+	QuotaReportSchema = buildSchemaVar("quotaReport")
+
+	// SubjectQuotaSchema is the JSON schema for the SubjectQuota resource.
+	SubjectQuotaSchema = buildSchemaVar("subjectQuota")
+
 	// DriverInfoSchema is the JSON schema for the DriverInfo resource.
 	DriverInfoSchema = buildSchemaVar("driverInfo")
 
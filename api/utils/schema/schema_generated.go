@@ -146,6 +146,10 @@ const (
                     "type": "string",
                     "description": "The region from which the object originates."
                 },
+                "availabilityZone": {
+                    "type": "string",
+                    "description": "The zone in which the instance is located."
+                },
                 "fields": { "$ref": "#/definitions/fields" }
             },
             "required": [ "id" ],
@@ -247,7 +251,12 @@ const (
                     "description": "Name is the service's name."
                 },
                 "instance": { "$ref": "#/definitions/instance" },
-                "driver": { "$ref": "#/definitions/driverInfo" }
+                "driver": { "$ref": "#/definitions/driverInfo" },
+                "availabilityZones": {
+                    "type": "array",
+                    "description": "The zones in which the service can provision volumes.",
+                    "items": { "type": "string" }
+                }
             },
             "required": [ "name", "driver" ],
             "additionalProperties": false
@@ -272,6 +281,173 @@ const (
         },
 
 
+        "storageClass": {
+            "type": "object",
+            "description": "StorageClass is a named set of defaults for creating volumes.",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "description": "The name of the class."
+                },
+                "description": {
+                    "type": "string",
+                    "description": "A description of the class."
+                },
+                "size": {
+                    "type": "number",
+                    "description": "The default size of a volume, in GiB."
+                },
+                "type": {
+                    "type": "string",
+                    "description": "The default type of a volume."
+                },
+                "iops": {
+                    "type": "number",
+                    "description": "The default IOPS of a volume."
+                },
+                "encrypted": {
+                    "type": "boolean",
+                    "description": "A flag that indicates whether or not volumes are encrypted by default."
+                },
+                "fsType": {
+                    "type": "string",
+                    "description": "The file system type with which clients format volumes."
+                },
+                "opts": { "$ref" : "#/definitions/opts" }
+            },
+            "required": [ "name" ],
+            "additionalProperties": false
+        },
+
+
+        "storageClassMap": {
+            "type": "object",
+            "patternProperties": {
+                "^.+$": { "$ref": "#/definitions/storageClass" }
+            },
+            "additionalProperties": false
+        },
+
+
+        "quotaLimits": {
+            "type": "object",
+            "description": "QuotaLimits are the limits of a quota. A limit that is not set is not enforced.",
+            "properties": {
+                "volumes": {
+                    "type": "number",
+                    "description": "The maximum number of volumes."
+                },
+                "size": {
+                    "type": "number",
+                    "description": "The maximum total size of all volumes, in GiB."
+                },
+                "iops": {
+                    "type": "number",
+                    "description": "The maximum total IOPS of all volumes."
+                },
+                "snapshots": {
+                    "type": "number",
+                    "description": "The maximum number of snapshots."
+                }
+            },
+            "additionalProperties": false
+        },
+
+
+        "quotaUsage": {
+            "type": "object",
+            "description": "QuotaUsage is the usage counted against a quota.",
+            "properties": {
+                "volumes": {
+                    "type": "number",
+                    "description": "The number of volumes."
+                },
+                "size": {
+                    "type": "number",
+                    "description": "The total size of all volumes, in GiB."
+                },
+                "iops": {
+                    "type": "number",
+                    "description": "The total IOPS of all volumes."
+                },
+                "snapshots": {
+                    "type": "number",
+                    "description": "The number of snapshots."
+                }
+            },
+            "required": [ "volumes", "size", "iops", "snapshots" ],
+            "additionalProperties": false
+        },
+
+
+        "subjectQuota": {
+            "type": "object",
+            "description": "SubjectQuota is the usage and limits of an auth subject.",
+            "properties": {
+                "subject": {
+                    "type": "string",
+                    "description": "The auth subject."
+                },
+                "usage": { "$ref": "#/definitions/quotaUsage" },
+                "services": {
+                    "type": "object",
+                    "description": "The subject's usage of each service, keyed by service.",
+                    "patternProperties": {
+                        "^.+$": { "$ref": "#/definitions/quotaUsage" }
+                    },
+                    "additionalProperties": false
+                },
+                "limits": { "$ref": "#/definitions/quotaLimits" },
+                "roles": {
+                    "type": "object",
+                    "description": "The limits of the subject's roles, keyed by role.",
+                    "patternProperties": {
+                        "^.+$": { "$ref": "#/definitions/quotaLimits" }
+                    },
+                    "additionalProperties": false
+                }
+            },
+            "required": [ "subject", "usage" ],
+            "additionalProperties": false
+        },
+
+
+        "serviceQuota": {
+            "type": "object",
+            "description": "ServiceQuota is the usage and limits of a service.",
+            "properties": {
+                "usage": { "$ref": "#/definitions/quotaUsage" },
+                "limits": { "$ref": "#/definitions/quotaLimits" }
+            },
+            "required": [ "usage" ],
+            "additionalProperties": false
+        },
+
+
+        "quotaReport": {
+            "type": "object",
+            "description": "QuotaReport is the usage and limits of all auth subjects and services.",
+            "properties": {
+                "subjects": {
+                    "type": "object",
+                    "patternProperties": {
+                        "^.+$": { "$ref": "#/definitions/subjectQuota" }
+                    },
+                    "additionalProperties": false
+                },
+                "services": {
+                    "type": "object",
+                    "patternProperties": {
+                        "^.+$": { "$ref": "#/definitions/serviceQuota" }
+                    },
+                    "additionalProperties": false
+                }
+            },
+            "required": [ "subjects", "services" ],
+            "additionalProperties": false
+        },
+
+
         "executorInfo": {
             "type": "object",
             "properties": {
@@ -279,9 +455,25 @@ const (
                     "type": "string",
                     "description": "The name of the executor."
                 },
+                "os": {
+                    "type": "string",
+                    "description": "The operating system for which the executor was built."
+                },
+                "arch": {
+                    "type": "string",
+                    "description": "The architecture for which the executor was built."
+                },
                 "md5checksum": {
                     "type": "string",
-                    "description": "The file's MD5 checksum. This can be used to determine if a local copy of the executor needs to be updated."
+                    "description": "The file's MD5 checksum. This is provided for clients that do not support the SHA-256 checksum."
+                },
+                "sha256checksum": {
+                    "type": "string",
+                    "description": "The file's SHA-256 checksum. This can be used to determine if a local copy of the executor needs to be updated."
+                },
+                "signature": {
+                    "type": "string",
+                    "description": "The base64 encoded, detached Ed25519 signature of the file. This is omitted if the executor is not signed."
                 },
                 "size": {
                     "type": "number",
@@ -355,9 +547,41 @@ const (
 
 
         "serviceVolumeMap": {
+            "type": "object",
+            "description": "A map of volume maps keyed by service name. The errors key is reserved for the errors of the services that failed when partial results are enabled.",
+            "properties": {
+                "errors": { "$ref": "#/definitions/serviceErrorMap" }
+            },
+            "patternProperties": {
+                "^(.{1,5}|[^e].*|e[^r].*|er[^r].*|err[^o].*|erro[^r].*|error[^s].*|errors.+)$": { "$ref": "#/definitions/volumeMap" }
+            },
+            "additionalProperties": false
+        },
+
+
+        "serviceError": {
+            "title": "ServiceError",
+            "description": "The error a service encountered while processing its part of a request for multiple services.",
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "description": "The error message."
+                },
+                "timeout": {
+                    "type": "boolean",
+                    "description": "A flag that indicates the service did not complete its part of the request within the service's timeout."
+                }
+            },
+            "required": [ "message" ],
+            "additionalProperties": false
+        },
+
+
+        "serviceErrorMap": {
             "type": "object",
             "patternProperties": {
-                "^.+$": { "$ref": "#/definitions/volumeMap" }
+                "^.+$": { "$ref": "#/definitions/serviceError" }
             },
             "additionalProperties": false
         },
@@ -452,6 +676,9 @@ const (
                 "type": {
                     "type": "string"
                 },
+                "class": {
+                    "type": "string"
+                },
                 "opts": { "$ref" : "#/definitions/opts" }
             },
             "required": [ "name" ],
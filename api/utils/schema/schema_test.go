@@ -282,3 +282,56 @@ func TestVolumeSnapshotRequestObject(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestPartialServiceVolumeMapObject(t *testing.T) {
+
+	m := &types.PartialServiceVolumeMap{
+		Volumes: types.ServiceVolumeMap{
+			"vfs": types.VolumeMap{
+				"vol-000": &types.Volume{ID: "vol-000", Name: "Volume 000"},
+			},
+			"errorsvc": types.VolumeMap{},
+		},
+		Errors: map[string]*types.ServiceError{
+			"ebs": &types.ServiceError{
+				Message: "service timed out",
+				Timeout: true,
+			},
+		},
+	}
+
+	d, err := validateObject(ServiceVolumeMapSchema, m)
+	if d == nil {
+		assert.NoError(t, err, string(d))
+	} else {
+		assert.NoError(t, err)
+	}
+
+	_, err = validateObject(
+		ServiceVolumeMapSchema,
+		map[string]interface{}{
+			"errors": map[string]interface{}{
+				"ebs": map[string]interface{}{"timeout": true},
+			},
+		})
+	assert.Error(t, err)
+}
+
+func TestServiceInfoObject(t *testing.T) {
+
+	si := &types.ServiceInfo{
+		Name: "ebs",
+		Driver: &types.DriverInfo{
+			Name: "ebs",
+			Type: types.Block,
+		},
+		AvailabilityZones: []string{"us-east-1a", "us-east-1b"},
+	}
+
+	d, err := validateObject(ServiceInfoSchema, si)
+	if d == nil {
+		assert.NoError(t, err, string(d))
+	} else {
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,207 @@
+// Package secrets resolves secret references in configuration values and
+// ensures resolved secrets are masked when configuration data is printed or
+// logged.
+package secrets
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// minMaskLen is the minimum length of a secret that is masked when it
+// appears within a longer string. Shorter secrets, such as "true" or "1",
+// are only masked when they are the entire value, since replacing them
+// everywhere would corrupt unrelated text and hint at the secret's value.
+const minMaskLen = 8
+
+var (
+	resolved    = map[string]bool{}
+	resolvedRWL = &sync.RWMutex{}
+
+	addHookOnce sync.Once
+)
+
+// IsRef returns a flag indicating whether or not the provided value is a
+// secret reference.
+func IsRef(val string) bool {
+	return strings.HasPrefix(val, types.SecretRefPrefix)
+}
+
+// ParseRef parses a secret reference into its provider name and the
+// provider-specific reference.
+func ParseRef(val string) (string, string, error) {
+	if !IsRef(val) {
+		return "", "", goof.WithField("value", val, "invalid secret ref")
+	}
+	parts := strings.SplitN(
+		strings.TrimPrefix(val, types.SecretRefPrefix), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", goof.WithField("value", val, "invalid secret ref")
+	}
+	return strings.ToLower(parts[0]), parts[1], nil
+}
+
+// Resolve walks all of the keys in the provided configuration and replaces
+// any secret references with the secret returned by the reference's provider.
+func Resolve(ctx types.Context, config gofig.Config) error {
+
+	providers := map[string]types.SecretProvider{}
+
+	for _, k := range config.AllKeys() {
+
+		v, ok := config.Get(k).(string)
+		if !ok || !IsRef(v) {
+			continue
+		}
+
+		providerName, ref, err := ParseRef(v)
+		if err != nil {
+			return goof.WithFieldE("key", k, "error parsing secret ref", err)
+		}
+
+		p, ok := providers[providerName]
+		if !ok {
+			if p, err = registry.NewSecretProvider(providerName); err != nil {
+				return err
+			}
+			if err := p.Init(ctx, config); err != nil {
+				return err
+			}
+			providers[providerName] = p
+		}
+
+		secret, err := p.Resolve(ctx, ref)
+		if err != nil {
+			return goof.WithFieldsE(goof.Fields{
+				"key":      k,
+				"provider": providerName,
+			}, "error resolving secret", err)
+		}
+
+		config.Set(k, secret)
+		track(secret)
+
+		ctx.WithFields(log.Fields{
+			"key":      k,
+			"provider": providerName,
+		}).Debug("resolved secret")
+	}
+
+	return nil
+}
+
+// Track records a value as a secret so that it is masked when printed or
+// logged. This is useful for secrets that are not resolved from config refs.
+func Track(secret string) {
+	track(secret)
+}
+
+func track(secret string) {
+	if secret == "" {
+		return
+	}
+	resolvedRWL.Lock()
+	resolved[secret] = true
+	resolvedRWL.Unlock()
+	addHookOnce.Do(func() { log.AddHook(&maskHook{}) })
+}
+
+// IsSecret returns a flag indicating whether or not the provided value is a
+// resolved secret.
+func IsSecret(val string) bool {
+	resolvedRWL.RLock()
+	defer resolvedRWL.RUnlock()
+	return resolved[val]
+}
+
+// MaskString replaces all resolved secrets in the provided string with
+// types.SecretMask. Secrets shorter than minMaskLen are only masked if they
+// are the entire string.
+func MaskString(val string) string {
+	resolvedRWL.RLock()
+	defer resolvedRWL.RUnlock()
+	if resolved[val] {
+		return types.SecretMask
+	}
+	for s := range resolved {
+		if len(s) >= minMaskLen && strings.Contains(val, s) {
+			val = strings.Replace(val, s, types.SecretMask, -1)
+		}
+	}
+	return val
+}
+
+// Mask returns a copy of the provided object with all resolved secrets
+// masked. Maps and slices are copied recursively.
+func Mask(obj interface{}) interface{} {
+	switch to := obj.(type) {
+	case string:
+		return MaskString(to)
+	case []string:
+		m := make([]string, len(to))
+		for i, v := range to {
+			m[i] = MaskString(v)
+		}
+		return m
+	case []interface{}:
+		m := make([]interface{}, len(to))
+		for i, v := range to {
+			m[i] = Mask(v)
+		}
+		return m
+	case map[string]interface{}:
+		return MaskSettings(to)
+	case map[interface{}]interface{}:
+		m := map[interface{}]interface{}{}
+		for k, v := range to {
+			m[k] = Mask(v)
+		}
+		return m
+	default:
+		return obj
+	}
+}
+
+// MaskSettings returns a copy of the provided settings map with all resolved
+// secrets masked.
+func MaskSettings(settings map[string]interface{}) map[string]interface{} {
+	m := map[string]interface{}{}
+	for k, v := range settings {
+		m[k] = Mask(v)
+	}
+	return m
+}
+
+// ToJSON returns the provided configuration as a JSON string with all
+// resolved secrets masked.
+func ToJSON(config gofig.Config) (string, error) {
+	buf, err := json.MarshalIndent(
+		MaskSettings(config.AllSettings()), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// maskHook is a logrus hook that masks resolved secrets in log entries.
+type maskHook struct{}
+
+func (h *maskHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *maskHook) Fire(entry *log.Entry) error {
+	entry.Message = MaskString(entry.Message)
+	for k, v := range entry.Data {
+		entry.Data[k] = Mask(v)
+	}
+	return nil
+}
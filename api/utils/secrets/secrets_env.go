@@ -0,0 +1,40 @@
+package secrets
+
+import (
+	"os"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const envProviderName = "env"
+
+func init() {
+	registry.RegisterSecretProvider(envProviderName, newEnvProvider)
+}
+
+// envProvider resolves secrets from environment variables.
+type envProvider struct{}
+
+func newEnvProvider() types.SecretProvider {
+	return &envProvider{}
+}
+
+func (p *envProvider) Name() string {
+	return envProviderName
+}
+
+func (p *envProvider) Init(ctx types.Context, config gofig.Config) error {
+	return nil
+}
+
+func (p *envProvider) Resolve(ctx types.Context, ref string) (string, error) {
+	v, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", goof.WithField("name", ref, "missing secret env var")
+	}
+	return v, nil
+}
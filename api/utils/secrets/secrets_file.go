@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"io/ioutil"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const fileProviderName = "file"
+
+func init() {
+	registry.RegisterSecretProvider(fileProviderName, newFileProvider)
+}
+
+// fileProvider resolves secrets from the contents of files, such as those
+// mounted by container orchestrators under /run/secrets. Trailing newlines
+// are trimmed from the secret.
+type fileProvider struct{}
+
+func newFileProvider() types.SecretProvider {
+	return &fileProvider{}
+}
+
+func (p *fileProvider) Name() string {
+	return fileProviderName
+}
+
+func (p *fileProvider) Init(ctx types.Context, config gofig.Config) error {
+	return nil
+}
+
+func (p *fileProvider) Resolve(ctx types.Context, ref string) (string, error) {
+	buf, err := ioutil.ReadFile(ref)
+	if err != nil {
+		return "", goof.WithFieldE(
+			"path", ref, "error reading secret file", err)
+	}
+	return strings.TrimRight(string(buf), "\r\n"), nil
+}
@@ -0,0 +1,61 @@
+// +build linux
+
+package secrets
+
+import (
+	"bytes"
+	"os/exec"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+const keyringProviderName = "keyring"
+
+func init() {
+	registry.RegisterSecretProvider(keyringProviderName, newKeyringProvider)
+}
+
+// keyringProvider resolves secrets from the Linux kernel keyring by way of
+// the keyctl utility. The reference is the description of a key of type
+// "user" that is searchable from the process's keyrings.
+type keyringProvider struct {
+	keyctl string
+}
+
+func newKeyringProvider() types.SecretProvider {
+	return &keyringProvider{}
+}
+
+func (p *keyringProvider) Name() string {
+	return keyringProviderName
+}
+
+func (p *keyringProvider) Init(ctx types.Context, config gofig.Config) error {
+	keyctl, err := exec.LookPath("keyctl")
+	if err != nil {
+		return goof.WithError("keyctl not found", err)
+	}
+	p.keyctl = keyctl
+	return nil
+}
+
+func (p *keyringProvider) Resolve(
+	ctx types.Context, ref string) (string, error) {
+
+	keyID, err := exec.Command(p.keyctl, "request", "user", ref).Output()
+	if err != nil {
+		return "", goof.WithFieldE("name", ref, "error finding key", err)
+	}
+
+	secret, err := exec.Command(
+		p.keyctl, "pipe", string(bytes.TrimSpace(keyID))).Output()
+	if err != nil {
+		return "", goof.WithFieldE("name", ref, "error reading key", err)
+	}
+
+	return string(secret), nil
+}
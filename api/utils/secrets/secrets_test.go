@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestParseRef(t *testing.T) {
+	p, r, err := ParseRef("secret:file:/run/secrets/x")
+	assert.NoError(t, err)
+	assert.Equal(t, "file", p)
+	assert.Equal(t, "/run/secrets/x", r)
+
+	p, r, err = ParseRef("secret:ENV:NAME")
+	assert.NoError(t, err)
+	assert.Equal(t, "env", p)
+	assert.Equal(t, "NAME", r)
+
+	_, _, err = ParseRef("secret:env")
+	assert.Error(t, err)
+
+	_, _, err = ParseRef("password")
+	assert.Error(t, err)
+
+	assert.True(t, IsRef("secret:env:NAME"))
+	assert.False(t, IsRef("secretive"))
+}
+
+func TestEnvProvider(t *testing.T) {
+	os.Setenv("LIBSTORAGE_TEST_SECRET", "envsecret")
+	defer os.Unsetenv("LIBSTORAGE_TEST_SECRET")
+
+	p, err := registry.NewSecretProvider("env")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	ctx := context.Background()
+	s, err := p.Resolve(ctx, "LIBSTORAGE_TEST_SECRET")
+	assert.NoError(t, err)
+	assert.Equal(t, "envsecret", s)
+
+	_, err = p.Resolve(ctx, "LIBSTORAGE_TEST_SECRET_MISSING")
+	assert.Error(t, err)
+}
+
+func TestFileProvider(t *testing.T) {
+	f, err := ioutil.TempFile("", "libstorage-secret")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(f.Name())
+	f.WriteString("filesecret\n")
+	f.Close()
+
+	p, err := registry.NewSecretProvider("file")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	s, err := p.Resolve(context.Background(), f.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, "filesecret", s)
+}
+
+func TestMask(t *testing.T) {
+	Track("maskedsecret")
+
+	assert.True(t, IsSecret("maskedsecret"))
+	assert.False(t, IsSecret("notasecret"))
+	assert.Equal(t, types.SecretMask, MaskString("maskedsecret"))
+	assert.Equal(t,
+		"PASSWORD="+types.SecretMask, MaskString("PASSWORD=maskedsecret"))
+
+	// short secrets are only masked when they are the entire value
+	Track("true")
+	assert.Equal(t, types.SecretMask, MaskString("true"))
+	assert.Equal(t, "enabled=true", MaskString("enabled=true"))
+
+	settings := MaskSettings(map[string]interface{}{
+		"scaleio": map[string]interface{}{
+			"username": "admin",
+			"password": "maskedsecret",
+		},
+		"list": []interface{}{"a", "maskedsecret"},
+	})
+
+	scaleio := settings["scaleio"].(map[string]interface{})
+	assert.Equal(t, "admin", scaleio["username"])
+	assert.Equal(t, types.SecretMask, scaleio["password"])
+	assert.Equal(t, types.SecretMask, settings["list"].([]interface{})[1])
+}
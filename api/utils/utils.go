@@ -5,10 +5,12 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"strings"
 	"time"
 
 	// load the golf package
 	_ "github.com/akutz/golf"
+	"github.com/akutz/gotil"
 
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/types"
@@ -49,3 +51,16 @@ func DeviceAttachTimeout(val string) time.Duration {
 	}
 	return dur
 }
+
+const grpcScheme = "grpc://"
+
+// ParseAddress parses a libStorage endpoint address, ex. unix:///tmp/ls.sock,
+// tcp://127.0.0.1:7979 or grpc://127.0.0.1:7980, into its protocol and
+// address.
+func ParseAddress(addr string) (proto string, laddr string, err error) {
+	if len(addr) > len(grpcScheme) &&
+		strings.EqualFold(addr[:len(grpcScheme)], grpcScheme) {
+		return types.GRPCEndpoint.String(), addr[len(grpcScheme):], nil
+	}
+	return gotil.ParseAddress(addr)
+}
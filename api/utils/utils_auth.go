@@ -75,3 +75,69 @@ func ParseAuthConfig(
 
 	return authConfig, nil
 }
+
+// ParseAdminAuthConfig returns a new AuthTokenConfig instance used to grant
+// access to the server's admin API. The allow list is read from
+// libstorage.server.admin.allow while the signing key and algorithm are
+// shared with the global auth configuration. A nil value is returned if no
+// admin subjects are configured.
+func ParseAdminAuthConfig(
+	ctx types.Context,
+	config gofig.Config,
+	fields log.Fields) (*types.AuthConfig, error) {
+
+	return parseSubjectsAuthConfig(
+		ctx, config, fields, types.ConfigServerAdminAllow)
+}
+
+// ParseDebugAuthConfig returns a new AuthTokenConfig instance used to
+// authorize the subjects that may enable debug logging for a request with
+// the debug header. The allow list is read from
+// libstorage.server.debug.allow while the signing key and algorithm are
+// shared with the global auth configuration. A nil value is returned if no
+// debug subjects are configured.
+func ParseDebugAuthConfig(
+	ctx types.Context,
+	config gofig.Config,
+	fields log.Fields) (*types.AuthConfig, error) {
+
+	return parseSubjectsAuthConfig(
+		ctx, config, fields, types.ConfigServerDebugAllow)
+}
+
+func parseSubjectsAuthConfig(
+	ctx types.Context,
+	config gofig.Config,
+	fields log.Fields,
+	allowKey string) (*types.AuthConfig, error) {
+
+	allow := config.GetStringSlice(allowKey)
+	if len(allow) == 0 {
+		ctx.WithField("configKey", allowKey).Debug(
+			"server subjects auth config not defined")
+		return nil, nil
+	}
+
+	authConfig := &types.AuthConfig{Alg: "HS256", Allow: allow}
+	if fields != nil {
+		fields[allowKey] = allow
+	}
+
+	if szKey := config.GetString(types.ConfigServerAuthKey); szKey != "" {
+		if gotil.FileExists(szKey) {
+			buf, err := ioutil.ReadFile(szKey)
+			if err != nil {
+				return nil, err
+			}
+			authConfig.Key = buf
+		} else {
+			authConfig.Key = []byte(szKey)
+		}
+	}
+
+	if alg := config.GetString(types.ConfigServerAuthAlg); alg != "" {
+		authConfig.Alg = alg
+	}
+
+	return authConfig, nil
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// ParseStorageClasses parses the storage classes defined by a service's
+// configuration.
+func ParseStorageClasses(config gofig.Config) (types.StorageClassMap, error) {
+
+	v := config.Get(types.ConfigServiceClasses)
+	if v == nil {
+		return types.StorageClassMap{}, nil
+	}
+
+	// the classes are transcoded to JSON since the configuration's maps may
+	// be keyed by interface{} values
+	buf, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, goof.WithError("error encoding storage classes", err)
+	}
+	if buf, err = YAMLToJSON(buf); err != nil {
+		return nil, goof.WithError("error encoding storage classes", err)
+	}
+	parsed := types.StorageClassMap{}
+	if err := json.Unmarshal(buf, &parsed); err != nil {
+		return nil, goof.WithError("invalid storage classes", err)
+	}
+
+	// class names are case-insensitive
+	classes := types.StorageClassMap{}
+	for name, c := range parsed {
+		if c == nil {
+			c = &types.StorageClass{}
+		}
+		c.Name = strings.ToLower(name)
+		classes[c.Name] = c
+	}
+
+	return classes, nil
+}
+
+// ApplyStorageClass sets the options for creating a volume that are not set
+// to the defaults of the storage class the options specify. An error is
+// returned if the storage class is not defined for the service.
+func ApplyStorageClass(
+	svc types.StorageService,
+	opts *types.VolumeCreateOpts) error {
+
+	if opts.Class == nil || *opts.Class == "" {
+		return nil
+	}
+
+	name := strings.ToLower(*opts.Class)
+	c, ok := svc.StorageClasses()[name]
+	if !ok {
+		return NewBadStorageClassErr(svc.Name(), *opts.Class)
+	}
+	opts.Class = &name
+
+	// the class is also set as a label so that drivers that persist labels
+	// record it with the volume
+	if opts.Opts != nil {
+		SetRequestField(opts.Opts, types.VolumeFieldClass, name)
+	}
+
+	// the class's values are copied so that drivers that modify the options
+	// do not modify the class
+	if opts.Size == nil && c.Size != nil {
+		v := *c.Size
+		opts.Size = &v
+	}
+	if opts.Type == nil && c.Type != nil {
+		v := *c.Type
+		opts.Type = &v
+	}
+	if opts.IOPS == nil && c.IOPS != nil {
+		v := *c.IOPS
+		opts.IOPS = &v
+	}
+	if opts.Encrypted == nil && c.Encrypted != nil {
+		v := *c.Encrypted
+		opts.Encrypted = &v
+	}
+	if len(c.Opts) > 0 {
+		if opts.Opts == nil {
+			opts.Opts = NewStore()
+		}
+		// a request's driver options are available in its "opts" store as
+		// well as at the top level of the request's store
+		dopts := opts.Opts.GetStore("opts")
+		if dopts == nil {
+			dopts = NewStore()
+			opts.Opts.Set("opts", dopts)
+		}
+		for k, v := range c.Opts {
+			if !dopts.IsSet(k) {
+				dopts.Set(k, v)
+			}
+			if !opts.Opts.IsSet(k) {
+				opts.Opts.Set(k, v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RecordStorageClass records the storage class with which a volume was
+// created in the fields of the volume returned by the driver.
+func RecordStorageClass(v *types.Volume, opts *types.VolumeCreateOpts) {
+	if v == nil || opts.Class == nil || *opts.Class == "" {
+		return
+	}
+	if v.Fields == nil {
+		v.Fields = map[string]string{}
+	}
+	v.Fields[types.VolumeFieldClass] = *opts.Class
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"time"
+
 	"github.com/akutz/goof"
 
 	"github.com/codedellemc/libstorage/api/types"
@@ -66,6 +68,72 @@ func NewBatchProcessErr(completed interface{}, err error) error {
 		"completed", completed, "batch processing error", err)}
 }
 
+// NewAvailabilityZoneMismatchErr returns a new ErrAvailabilityZoneMismatch
+// error.
+func NewAvailabilityZoneMismatchErr(
+	volumeID, volumeZone, instanceZone string) error {
+
+	return &types.ErrAvailabilityZoneMismatch{Goof: goof.WithFields(
+		goof.Fields{
+			"volumeID":     volumeID,
+			"volumeZone":   volumeZone,
+			"instanceZone": instanceZone,
+		}, "volume and instance are in different availability zones")}
+}
+
+// NewBadStorageClassErr returns a new ErrBadStorageClass error.
+func NewBadStorageClassErr(service, class string) error {
+	return &types.ErrBadStorageClass{Goof: goof.WithFields(goof.Fields{
+		"service": service,
+		"class":   class,
+	}, "invalid storage class")}
+}
+
+// NewQuotaExceededErr returns a new ErrQuotaExceeded error.
+func NewQuotaExceededErr(
+	scope, name, resource string, limit, usage, requested int64) error {
+
+	return &types.ErrQuotaExceeded{Goof: goof.WithFields(goof.Fields{
+		"scope":     scope,
+		"name":      name,
+		"resource":  resource,
+		"limit":     limit,
+		"usage":     usage,
+		"requested": requested,
+	}, "quota exceeded")}
+}
+
+// NewQuotaSizeRequiredErr returns a new ErrQuotaSizeRequired error.
+func NewQuotaSizeRequiredErr(scope, name string) error {
+	return &types.ErrQuotaSizeRequired{Goof: goof.WithFields(goof.Fields{
+		"scope": scope,
+		"name":  name,
+	}, "size required by quota")}
+}
+
+// NewNamespaceDeniedErr returns a new ErrNamespaceDenied error.
+func NewNamespaceDeniedErr(namespace string) error {
+	return &types.ErrNamespaceDenied{Goof: goof.WithField(
+		"namespace", namespace, "namespace access denied")}
+}
+
+// NewPolicyDeniedErr returns a new ErrPolicyDenied error.
+func NewPolicyDeniedErr(operation, rule, reason string) error {
+	return &types.ErrPolicyDenied{Goof: goof.WithFields(goof.Fields{
+		"operation": operation,
+		"rule":      rule,
+		"reason":    reason,
+	}, "denied by policy")}
+}
+
+// NewServiceTimeoutErr returns a new ErrServiceTimeout error.
+func NewServiceTimeoutErr(service string, timeout time.Duration) error {
+	return &types.ErrServiceTimeout{Goof: goof.WithFields(goof.Fields{
+		"service": service,
+		"timeout": timeout.String(),
+	}, "service timed out")}
+}
+
 // NewBadFilterErr returns a new ErrBadFilter error.
 func NewBadFilterErr(filter string, err error) error {
 	return &types.ErrBadFilter{Goof: goof.WithFieldE(
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// executorNameRX matches the names of executors, ex. lsx-linux-arm64 or
+// lsx-windows-amd64.exe. The names of executors built before executors were
+// built for multiple architectures do not include the architecture, ex.
+// lsx-linux.
+var executorNameRX = regexp.MustCompile(
+	`^lsx-([[:alnum:]]+)(?:-([[:alnum:]]+))?(?:\.exe)?$`)
+
+// ExecutorDefaultArch is the architecture of an executor with a name that
+// does not include the architecture.
+const ExecutorDefaultArch = "amd64"
+
+// ExecutorName returns the name of the executor for the provided operating
+// system and architecture.
+func ExecutorName(goos, goarch string) string {
+	name := fmt.Sprintf("lsx-%s-%s", goos, goarch)
+	if goos == "windows" {
+		name = fmt.Sprintf("%s.exe", name)
+	}
+	return name
+}
+
+// ParseExecutorName returns the operating system and architecture of the
+// executor with the provided name. The returned flag is false if the name
+// is not the name of an executor.
+func ParseExecutorName(name string) (string, string, bool) {
+	m := executorNameRX.FindStringSubmatch(name)
+	if len(m) == 0 {
+		return "", "", false
+	}
+	if m[2] == "" {
+		return m[1], ExecutorDefaultArch, true
+	}
+	return m[1], m[2], true
+}
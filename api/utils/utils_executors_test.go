@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutorName(t *testing.T) {
+	assert.Equal(t, "lsx-linux-arm64", ExecutorName("linux", "arm64"))
+	assert.Equal(t, "lsx-windows-amd64.exe", ExecutorName("windows", "amd64"))
+}
+
+func TestParseExecutorName(t *testing.T) {
+	tests := []struct {
+		name, goos, goarch string
+		ok                 bool
+	}{
+		{"lsx-linux-arm64", "linux", "arm64", true},
+		{"lsx-linux-amd64", "linux", "amd64", true},
+		{"lsx-windows-386.exe", "windows", "386", true},
+		{"lsx-linux", "linux", "amd64", true},
+		{"lsx-windows.exe", "windows", "amd64", true},
+		{"lsx-linux-arm64.sig", "", "", false},
+		{"lsx-linux-arm64-1", "", "", false},
+		{"lss-linux", "", "", false},
+	}
+	for _, tt := range tests {
+		goos, goarch, ok := ParseExecutorName(tt.name)
+		assert.Equal(t, tt.ok, ok, tt.name)
+		assert.Equal(t, tt.goos, goos, tt.name)
+		assert.Equal(t, tt.goarch, goarch, tt.name)
+	}
+}
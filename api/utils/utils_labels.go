@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"encoding/json"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// PersistsLabels returns a flag indicating whether or not the driver
+// persists the labels the server records on the volumes and snapshots it
+// creates.
+func PersistsLabels(ctx types.Context, d types.StorageDriver) bool {
+	sd, ok := d.(types.StorageDriverWithLabels)
+	return ok && sd.PersistsLabels(ctx)
+}
+
+// Labels returns the labels set in the "opts" store of a request that
+// creates a volume or snapshot. A nil map is returned if no labels are set.
+func Labels(store types.Store) map[string]string {
+	if store == nil {
+		return nil
+	}
+	opts := store.GetStore("opts")
+	if opts == nil {
+		return nil
+	}
+	var labels map[string]string
+	for _, k := range types.VolumeLabels {
+		if !opts.IsSet(k) {
+			continue
+		}
+		v := opts.GetString(k)
+		if v == "" {
+			continue
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[k] = v
+	}
+	return labels
+}
+
+// RecordLabels records the labels in the fields of a volume or snapshot. The
+// fields, which are created if nil and there are labels, are returned.
+func RecordLabels(
+	fields map[string]string, labels map[string]string) map[string]string {
+
+	if len(labels) == 0 {
+		return fields
+	}
+	if fields == nil {
+		fields = map[string]string{}
+	}
+	for k, v := range labels {
+		fields[k] = v
+	}
+	return fields
+}
+
+// EncodeLabels encodes labels as a string for backends that can only
+// persist a volume's labels in a free-form text field, such as a
+// description. An empty string is returned if there are no labels.
+func EncodeLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	buf, err := json.Marshal(labels)
+	if err != nil {
+		return ""
+	}
+	return string(buf)
+}
+
+// DecodeLabels decodes the labels encoded with EncodeLabels. Only the keys
+// in types.VolumeLabels are returned, and a nil map is returned if the
+// string does not contain encoded labels.
+func DecodeLabels(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	m := map[string]string{}
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		return nil
+	}
+	var labels map[string]string
+	for _, k := range types.VolumeLabels {
+		if v, ok := m[k]; ok && v != "" {
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels[k] = v
+		}
+	}
+	return labels
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+func TestLabels(t *testing.T) {
+	store := NewStore()
+	assert.Nil(t, Labels(store))
+
+	SetRequestField(store, types.VolumeFieldOwner, "akutz")
+	SetRequestField(store, types.VolumeFieldNamespace, "team-a")
+	SetRequestField(store, "priority", "2")
+
+	labels := Labels(store)
+	assert.Len(t, labels, 2)
+	assert.Equal(t, "akutz", labels[types.VolumeFieldOwner])
+	assert.Equal(t, "team-a", labels[types.VolumeFieldNamespace])
+
+	fields := RecordLabels(nil, labels)
+	assert.Equal(t, labels, fields)
+}
+
+func TestEncodeLabels(t *testing.T) {
+	assert.Equal(t, "", EncodeLabels(nil))
+	assert.Nil(t, DecodeLabels(""))
+	assert.Nil(t, DecodeLabels("a disk created by hand"))
+
+	labels := map[string]string{
+		types.VolumeFieldOwner: "akutz@example.com",
+		types.VolumeFieldClass: "fast",
+	}
+	assert.Equal(t, labels, DecodeLabels(EncodeLabels(labels)))
+	assert.Nil(t, DecodeLabels(`{"priority":"2"}`))
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// InNamespace returns a flag indicating whether or not the volume or
+// snapshot with the provided fields is in the namespace to which the request
+// is scoped. Objects are always in scope for requests that are not scoped to
+// a namespace.
+func InNamespace(ctx types.Context, fields map[string]string) bool {
+	ns, ok := context.Namespace(ctx)
+	if !ok {
+		return true
+	}
+	return strings.EqualFold(fields[types.VolumeFieldNamespace], ns)
+}
+
+// SetNamespace sets the namespace label of the volume or snapshot a request
+// creates to the namespace to which the request is scoped. A namespace
+// provided by the client is always replaced.
+func SetNamespace(ctx types.Context, store types.Store) {
+	if ns, ok := context.Namespace(ctx); ok {
+		SetRequestField(store, types.VolumeFieldNamespace, ns)
+	}
+}
+
+// RecordNamespace records the namespace to which the request is scoped in
+// the fields of a volume or snapshot. The fields, which are created if nil,
+// are returned.
+func RecordNamespace(
+	ctx types.Context, fields map[string]string) map[string]string {
+
+	ns, ok := context.Namespace(ctx)
+	if !ok || ns == "" {
+		return fields
+	}
+	if fields == nil {
+		fields = map[string]string{}
+	}
+	fields[types.VolumeFieldNamespace] = ns
+	return fields
+}
+
+// CheckVolumeNamespace returns an *types.ErrNotFound error if the volume is
+// not in the namespace to which the request is scoped.
+func CheckVolumeNamespace(
+	ctx types.Context,
+	svc types.StorageService,
+	volumeID string,
+	store types.Store) error {
+
+	if _, ok := context.Namespace(ctx); !ok {
+		return nil
+	}
+	v, err := svc.Driver().VolumeInspect(
+		ctx, volumeID, &types.VolumeInspectOpts{Opts: store})
+	if err != nil {
+		return err
+	}
+	if v == nil || !InNamespace(ctx, v.Fields) {
+		return NewNotFoundError(volumeID)
+	}
+	return nil
+}
+
+// CheckSnapshotNamespace returns an *types.ErrNotFound error if the snapshot
+// is not in the namespace to which the request is scoped.
+func CheckSnapshotNamespace(
+	ctx types.Context,
+	svc types.StorageService,
+	snapshotID string,
+	store types.Store) error {
+
+	if _, ok := context.Namespace(ctx); !ok {
+		return nil
+	}
+	s, err := svc.Driver().SnapshotInspect(ctx, snapshotID, store)
+	if err != nil {
+		return err
+	}
+	if s == nil || !InNamespace(ctx, s.Fields) {
+		return NewNotFoundError(snapshotID)
+	}
+	return nil
+}
+
+// SetRequestField sets a custom field of a request. The field is set in the
+// request's "opts" store, from which drivers persist a request's custom
+// fields and labels, as well as at the top level of the request's store.
+func SetRequestField(store types.Store, k string, v interface{}) {
+	opts := store.GetStore("opts")
+	if opts == nil {
+		opts = NewStore()
+		store.Set("opts", opts)
+	}
+	opts.Set(k, v)
+	store.Set(k, v)
+}
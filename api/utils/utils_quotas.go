@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// ParseQuotaConfig parses the server's quota configuration. A nil value is
+// returned if no quotas are defined.
+func ParseQuotaConfig(config gofig.Config) (*types.QuotaConfig, error) {
+
+	v := config.Get(types.ConfigServerQuotas)
+	if v == nil {
+		return nil, nil
+	}
+
+	// the quotas are transcoded to JSON since the configuration's maps may
+	// be keyed by interface{} values
+	buf, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, goof.WithError("error encoding quotas", err)
+	}
+	if buf, err = YAMLToJSON(buf); err != nil {
+		return nil, goof.WithError("error encoding quotas", err)
+	}
+	parsed := &types.QuotaConfig{}
+	if err := json.Unmarshal(buf, parsed); err != nil {
+		return nil, goof.WithError("invalid quotas", err)
+	}
+
+	// subjects, roles, and service names are case-insensitive
+	return &types.QuotaConfig{
+		Subjects: lowerQuotaKeys(parsed.Subjects),
+		Roles:    lowerQuotaKeys(parsed.Roles),
+		Services: lowerQuotaKeys(parsed.Services),
+	}, nil
+}
+
+func lowerQuotaKeys(
+	m map[string]*types.QuotaLimits) map[string]*types.QuotaLimits {
+
+	lm := map[string]*types.QuotaLimits{}
+	for k, v := range m {
+		if v != nil {
+			lm[strings.ToLower(k)] = v
+		}
+	}
+	return lm
+}
+
+// SetOwner sets the owner label of the volume or snapshot a request creates
+// to the subject of the request's auth token. An owner provided by the
+// client is always replaced.
+func SetOwner(ctx types.Context, store types.Store) {
+	if tok, ok := context.AuthToken(ctx); ok {
+		SetRequestField(store, types.VolumeFieldOwner, tok.Subject)
+	}
+}
+
+// RecordOwner records the subject of the request's auth token as the owner
+// in the fields of a volume or snapshot. The fields, which are created if
+// nil, are returned.
+func RecordOwner(
+	ctx types.Context, fields map[string]string) map[string]string {
+
+	tok, ok := context.AuthToken(ctx)
+	if !ok {
+		return fields
+	}
+	if fields == nil {
+		fields = map[string]string{}
+	}
+	fields[types.VolumeFieldOwner] = tok.Subject
+	return fields
+}
+
+// Owner returns the lower-cased owner recorded in the fields of a volume or
+// snapshot; otherwise an empty string is returned.
+func Owner(fields map[string]string) string {
+	return strings.ToLower(fields[types.VolumeFieldOwner])
+}
+
+// NewVolumeQuotaUsage returns the usage requested by creating a volume with
+// the provided size and IOPS, either of which may be nil. A nil size is
+// counted as zero GiB, which the quota check rejects while a size limit
+// applies to the request since the driver's default size is not known.
+func NewVolumeQuotaUsage(size, iops *int64) *types.QuotaUsage {
+	u := &types.QuotaUsage{Volumes: 1}
+	if size != nil {
+		u.Size = *size
+	}
+	if iops != nil {
+		u.IOPS = *iops
+	}
+	return u
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/akutz/goof"
+	"golang.org/x/crypto/ed25519"
+)
+
+// SignatureFileExt is the file name extension of a detached signature.
+const SignatureFileExt = ".sig"
+
+// ReadSigningKey reads a base64 encoded Ed25519 private key from a file.
+func ReadSigningKey(path string) (ed25519.PrivateKey, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(
+		string(bytes.TrimSpace(buf)))
+	if err != nil {
+		return nil, goof.WithFieldE("path", path, "invalid signing key", err)
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, goof.WithField("path", path, "invalid signing key size")
+	}
+	return ed25519.PrivateKey(key), nil
+}
+
+// ExecutorManifest is what an executor's signature signs. The manifest binds
+// the executor's data to its name and platform, so a signed executor cannot
+// be served in place of an executor with another name or for another
+// platform.
+type ExecutorManifest struct {
+	Name           string
+	OS             string
+	Arch           string
+	SHA256Checksum string
+}
+
+// NewExecutorManifest returns the manifest of the executor with the provided
+// name, platform, and data.
+func NewExecutorManifest(
+	name, goos, goarch string, data []byte) *ExecutorManifest {
+
+	return &ExecutorManifest{
+		Name:           name,
+		OS:             goos,
+		Arch:           goarch,
+		SHA256Checksum: fmt.Sprintf("%x", sha256.Sum256(data)),
+	}
+}
+
+// Bytes returns the signed representation of the manifest.
+func (m *ExecutorManifest) Bytes() []byte {
+	return []byte(fmt.Sprintf(
+		"name: %s\nos: %s\narch: %s\nsha256: %s\n",
+		m.Name, m.OS, m.Arch, m.SHA256Checksum))
+}
+
+// Sign returns the base64 encoded, detached Ed25519 signature of the
+// provided executor manifest.
+func Sign(key ed25519.PrivateKey, manifest *ExecutorManifest) string {
+	return base64.StdEncoding.EncodeToString(
+		ed25519.Sign(key, manifest.Bytes()))
+}
+
+// ParseTrustedKeys parses a comma-separated list of trusted public keys.
+// Each element of the list is either a base64 encoded Ed25519 public key or
+// the path to a file that contains such keys, one per line. Empty lines and
+// lines that begin with a '#' are ignored.
+func ParseTrustedKeys(val string) ([]ed25519.PublicKey, error) {
+
+	var keys []ed25519.PublicKey
+
+	for _, v := range strings.Split(val, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if key, err := parsePublicKey(v); err == nil {
+			keys = append(keys, key)
+			continue
+		}
+
+		f, err := os.Open(v)
+		if err != nil {
+			return nil, goof.WithFieldE(
+				"key", v, "invalid trusted key or key file", err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key, err := parsePublicKey(line)
+			if err != nil {
+				f.Close()
+				return nil, goof.WithFieldE(
+					"path", v, "invalid trusted key", err)
+			}
+			keys = append(keys, key)
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+func parsePublicKey(text string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, goof.WithField("size", len(key), "invalid key size")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// VerifySignature verifies the base64 encoded, detached Ed25519 signature
+// of the provided executor manifest was created with the private key of one
+// of the trusted public keys.
+func VerifySignature(
+	keys []ed25519.PublicKey,
+	manifest *ExecutorManifest,
+	signature string) error {
+
+	if signature == "" {
+		return goof.New("missing signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return goof.WithError("invalid signature", err)
+	}
+	data := manifest.Bytes()
+	for _, key := range keys {
+		if ed25519.Verify(key, data, sig) {
+			return nil
+		}
+	}
+	return goof.New("signature not trusted")
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestVerifySignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	data := []byte("lsx")
+	manifest := NewExecutorManifest("lsx-linux-amd64", "linux", "amd64", data)
+	sig := Sign(priv, manifest)
+
+	assert.NoError(t, VerifySignature(
+		[]ed25519.PublicKey{otherPub, pub}, manifest, sig))
+	assert.EqualError(t, VerifySignature(
+		[]ed25519.PublicKey{otherPub}, manifest, sig),
+		"signature not trusted")
+	assert.EqualError(t, VerifySignature(
+		[]ed25519.PublicKey{pub}, NewExecutorManifest(
+			"lsx-linux-amd64", "linux", "amd64", []byte("lsy")), sig),
+		"signature not trusted")
+	assert.EqualError(t, VerifySignature(
+		[]ed25519.PublicKey{pub}, NewExecutorManifest(
+			"lsx-darwin-amd64", "darwin", "amd64", data), sig),
+		"signature not trusted")
+	assert.EqualError(t, VerifySignature(
+		[]ed25519.PublicKey{pub}, NewExecutorManifest(
+			"lsx-linux-amd64", "linux", "arm64", data), sig),
+		"signature not trusted")
+	assert.EqualError(t, VerifySignature(
+		[]ed25519.PublicKey{pub}, manifest, ""), "missing signature")
+	assert.Error(t, VerifySignature(
+		[]ed25519.PublicKey{pub}, manifest, "invalid"))
+}
+
+func TestParseTrustedKeys(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(tmpDir)
+
+	pub1, _, _ := ed25519.GenerateKey(rand.Reader)
+	pub2, _, _ := ed25519.GenerateKey(rand.Reader)
+	pub3, _, _ := ed25519.GenerateKey(rand.Reader)
+	b64 := base64.StdEncoding.EncodeToString
+
+	keysPath := path.Join(tmpDir, "lsx.pub")
+	if !assert.NoError(t, ioutil.WriteFile(keysPath, []byte(fmt.Sprintf(
+		"# trusted keys\n%s\n\n%s\n", b64(pub2), b64(pub3))), 0644)) {
+		t.FailNow()
+	}
+
+	keys, err := ParseTrustedKeys(
+		fmt.Sprintf(" %s , %s,", b64(pub1), keysPath))
+	assert.NoError(t, err)
+	assert.Equal(t, []ed25519.PublicKey{pub1, pub2, pub3}, keys)
+
+	keys, err = ParseTrustedKeys("")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 0)
+
+	_, err = ParseTrustedKeys(path.Join(tmpDir, "missing.pub"))
+	assert.Error(t, err)
+
+	_, err = ParseTrustedKeys(b64([]byte("short")))
+	assert.Error(t, err)
+}
+
+func TestReadSigningKey(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(tmpDir)
+
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	keyPath := path.Join(tmpDir, "lsx.key")
+	if !assert.NoError(t, ioutil.WriteFile(keyPath, []byte(
+		base64.StdEncoding.EncodeToString(priv)+"\n"), 0600)) {
+		t.FailNow()
+	}
+
+	key, err := ReadSigningKey(keyPath)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	manifest := NewExecutorManifest(
+		"lsx-linux-amd64", "linux", "amd64", []byte("lsx"))
+	assert.NoError(t, VerifySignature(
+		[]ed25519.PublicKey{pub}, manifest, Sign(key, manifest)))
+
+	if !assert.NoError(t, ioutil.WriteFile(keyPath, []byte(
+		base64.StdEncoding.EncodeToString(pub)), 0600)) {
+		t.FailNow()
+	}
+	_, err = ReadSigningKey(keyPath)
+	assert.Error(t, err)
+}
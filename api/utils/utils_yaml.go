@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// JSONToYAML transcodes a JSON document into a YAML document. The keys of
+// the YAML document are the same as those of the JSON document, so the
+// transcoding honors the `json` tags of the types that produced the JSON.
+func JSONToYAML(buf []byte) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(jsonToYAMLValue(v))
+}
+
+// YAMLToJSON transcodes a YAML document into a JSON document.
+func YAMLToJSON(buf []byte) ([]byte, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(buf, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(yamlToJSONValue(v))
+}
+
+// jsonToYAMLValue converts integral numbers decoded from JSON as float64
+// values to int64 values so they are not written to YAML in exponential
+// notation.
+func jsonToYAMLValue(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case float64:
+		if tv == math.Trunc(tv) && math.Abs(tv) < 1<<53 {
+			return int64(tv)
+		}
+		return tv
+	case []interface{}:
+		for i := range tv {
+			tv[i] = jsonToYAMLValue(tv[i])
+		}
+		return tv
+	case map[string]interface{}:
+		for k := range tv {
+			tv[k] = jsonToYAMLValue(tv[k])
+		}
+		return tv
+	default:
+		return v
+	}
+}
+
+// yamlToJSONValue converts the map[interface{}]interface{} values decoded
+// from YAML to map[string]interface{} values that may be encoded as JSON.
+func yamlToJSONValue(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case []interface{}:
+		for i := range tv {
+			tv[i] = yamlToJSONValue(tv[i])
+		}
+		return tv
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for k, kv := range tv {
+			m[fmt.Sprintf("%v", k)] = yamlToJSONValue(kv)
+		}
+		return m
+	default:
+		return v
+	}
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONToYAML(t *testing.T) {
+	buf, err := JSONToYAML([]byte(`{
+		"id": "vol-000",
+		"size": 10240,
+		"iops": 1.5,
+		"attachments": [{"instanceID": {"id": "i-000"}}]}`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	s := string(buf)
+	assert.Contains(t, s, "id: vol-000")
+	assert.Contains(t, s, "size: 10240")
+	assert.Contains(t, s, "iops: 1.5")
+	assert.Contains(t, s, "instanceID:")
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	buf, err := YAMLToJSON([]byte(
+		"name: vol-000\nsize: 10240\nopts:\n  encrypted: true\n"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.JSONEq(t,
+		`{"name":"vol-000","size":10240,"opts":{"encrypted":true}}`,
+		string(buf))
+}
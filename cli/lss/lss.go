@@ -23,6 +23,7 @@ import (
 	apitypes "github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
 	apiconfig "github.com/codedellemc/libstorage/api/utils/config"
+	"github.com/codedellemc/libstorage/api/utils/secrets"
 
 	// load the drivers
 	_ "github.com/codedellemc/libstorage/imports/config"
@@ -87,8 +88,13 @@ func Run() {
 			os.Exit(1)
 		}
 
+		if err := secrets.Resolve(context.Background(), config); err != nil {
+			fmt.Fprintf(apitypes.Stderr, "%s: error: %v\n", os.Args[0], err)
+			os.Exit(1)
+		}
+
 		if flagPrintConfig != nil && *flagPrintConfig {
-			jstr, err := config.ToJSON()
+			jstr, err := secrets.ToJSON(config)
 			if err != nil {
 				fmt.Fprintf(apitypes.Stderr, "%s: error: %v\n", os.Args[0], err)
 				os.Exit(1)
@@ -150,7 +156,7 @@ func Run() {
 	}
 
 	if flagPrintConfig != nil && *flagPrintConfig {
-		jstr, err := config.ToJSON()
+		jstr, err := secrets.ToJSON(config)
 		if err != nil {
 			fmt.Fprintf(apitypes.Stderr, "%s: error: %v\n", os.Args[0], err)
 			os.Exit(1)
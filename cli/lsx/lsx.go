@@ -34,6 +34,17 @@ func Run() {
 	ctx = ctx.WithValue(context.PathConfigKey, utils.NewPathConfig(ctx, "", ""))
 	registry.ProcessRegisteredConfigs(ctx)
 
+	// tag the executor's logs with the transaction of the operation that
+	// invoked the executor
+	if v := os.Getenv(apitypes.TransactionEnvVar); v != "" {
+		tx := &apitypes.Transaction{}
+		if err := tx.UnmarshalText([]byte(v)); err != nil {
+			fmt.Fprintf(apitypes.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		ctx = ctx.WithValue(context.TransactionKey, tx)
+	}
+
 	args := os.Args
 	if len(args) < 3 {
 		printUsageAndExit()
@@ -45,8 +56,6 @@ func Run() {
 		os.Exit(1)
 	}
 
-	driverName := strings.ToLower(d.Name())
-
 	config, err := apiconfig.NewConfig(ctx)
 	if err != nil {
 		fmt.Fprintf(apitypes.Stderr, "error: %v\n", err)
@@ -60,13 +69,49 @@ func Run() {
 		os.Exit(1)
 	}
 
-	cmd := cmdRx.FindString(args[2])
-	if cmd == "" {
+	if strings.EqualFold(args[2], apitypes.LSXCmdServe) {
+		if err := serve(ctx, d, args[3:]); err != nil {
+			fmt.Fprintf(apitypes.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	res := execute(ctx, d, args[2:])
+	if res == nil {
 		printUsageAndExit()
 	}
+
+	if res.Stderr != "" {
+		fmt.Fprint(apitypes.Stderr, res.Stderr)
+	}
+	apitypes.Stdout.Write(res.Stdout)
+	os.Exit(res.ExitCode)
+}
+
+// execute executes a command and returns the command's output and the code
+// with which the executor exits. The first of the arguments is the command.
+// A nil result is returned if the arguments are invalid.
+func execute(
+	ctx apitypes.Context,
+	d apitypes.StorageExecutor,
+	args []string) *apitypes.LSXResponse {
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	cmd := cmdRx.FindString(args[0])
+	if cmd == "" {
+		return nil
+	}
+
+	driverName := strings.ToLower(d.Name())
+	ctx.WithField("cmd", cmd).Debug("executing command")
 	store := utils.NewStore()
 
 	var (
+		err      error
 		result   interface{}
 		op       string
 		exitCode int
@@ -122,9 +167,9 @@ func Run() {
 				mountPath  string
 				mountOpts  = &apitypes.DeviceMountOpts{Opts: store}
 			)
-			mountArgs := args[3:]
+			mountArgs := args[1:]
 			if len(mountArgs) == 0 {
-				printUsageAndExit()
+				return nil
 			}
 
 			remArgs := []string{}
@@ -147,7 +192,7 @@ func Run() {
 			}
 
 			if len(remArgs) != 2 {
-				printUsageAndExit()
+				return nil
 			}
 
 			deviceName = remArgs[0]
@@ -167,10 +212,10 @@ func Run() {
 		if !ok {
 			err = apitypes.ErrNotImplemented
 		} else {
-			if len(args) < 4 {
-				printUsageAndExit()
+			if len(args) < 2 {
+				return nil
 			}
-			mountPath := args[3]
+			mountPath := args[1]
 			opErr := dd.Unmount(ctx, mountPath, store)
 			if opErr != nil {
 				err = opErr
@@ -196,12 +241,12 @@ func Run() {
 			result = opResult
 		}
 	} else if strings.EqualFold(cmd, apitypes.LSXCmdLocalDevices) {
-		if len(args) < 4 {
-			printUsageAndExit()
+		if len(args) < 2 {
+			return nil
 		}
 		op = apitypes.LSXCmdLocalDevices
 		opResult, opErr := d.LocalDevices(ctx, &apitypes.LocalDevicesOpts{
-			ScanType: apitypes.ParseDeviceScanType(args[3]),
+			ScanType: apitypes.ParseDeviceScanType(args[1]),
 			Opts:     store,
 		})
 		if opErr != nil {
@@ -211,17 +256,17 @@ func Run() {
 			result = opResult
 		}
 	} else if strings.EqualFold(cmd, apitypes.LSXCmdWaitForDevice) {
-		if len(args) < 6 {
-			printUsageAndExit()
+		if len(args) < 4 {
+			return nil
 		}
 		op = apitypes.LSXCmdWaitForDevice
 		opts := &apitypes.WaitForDeviceOpts{
 			LocalDevicesOpts: apitypes.LocalDevicesOpts{
-				ScanType: apitypes.ParseDeviceScanType(args[3]),
+				ScanType: apitypes.ParseDeviceScanType(args[1]),
 				Opts:     store,
 			},
-			Token:   strings.ToLower(args[4]),
-			Timeout: utils.DeviceAttachTimeout(args[5]),
+			Token:   strings.ToLower(args[2]),
+			Timeout: utils.DeviceAttachTimeout(args[3]),
 		}
 
 		ldl := func() (bool, *apitypes.LocalDevices, error) {
@@ -237,12 +282,15 @@ func Run() {
 			return false, ldm, nil
 		}
 
+		// the ticker is stopped since a daemon executes many commands
+		tick := time.NewTicker(500 * time.Millisecond)
+		defer tick.Stop()
+
 		var (
 			found    bool
 			opErr    error
 			opResult *apitypes.LocalDevices
 			timeoutC = time.After(opts.Timeout)
-			tick     = time.Tick(500 * time.Millisecond)
 		)
 
 	TimeoutLoop:
@@ -252,7 +300,7 @@ func Run() {
 			case <-timeoutC:
 				exitCode = apitypes.LSXExitCodeTimedOut
 				break TimeoutLoop
-			case <-tick:
+			case <-tick.C:
 				if found, opResult, opErr = ldl(); found || opErr != nil {
 					break TimeoutLoop
 				}
@@ -283,37 +331,46 @@ func Run() {
 		default:
 			errStr = e.Error()
 		}
-		fmt.Fprintf(apitypes.Stderr,
-			"error: error getting %s: %v\n", op, errStr)
-		os.Exit(exitCode)
+		return &apitypes.LSXResponse{
+			ExitCode: exitCode,
+			Stderr: fmt.Sprintf(
+				"error: error getting %s: %v\n", op, errStr),
+		}
 	}
 
+	buf := &bytes.Buffer{}
+
 	switch tr := result.(type) {
 	case bool:
-		fmt.Fprintf(apitypes.Stdout, "%v", result)
+		fmt.Fprintf(buf, "%v", result)
 	case string:
-		fmt.Fprintln(apitypes.Stdout, result)
+		fmt.Fprintln(buf, result)
 	case encoding.TextMarshaler:
-		buf, err := tr.MarshalText()
+		text, err := tr.MarshalText()
 		if err != nil {
-			fmt.Fprintf(apitypes.Stderr, "error: error encoding %s: %v\n", op, err)
-			os.Exit(1)
+			return encodeErrorResponse(op, err)
 		}
-		apitypes.Stdout.Write(buf)
+		buf.Write(text)
 	default:
-		buf, err := json.Marshal(result)
+		text, err := json.Marshal(result)
 		if err != nil {
-			fmt.Fprintf(apitypes.Stderr, "error: error encoding %s: %v\n", op, err)
-			os.Exit(1)
+			return encodeErrorResponse(op, err)
 		}
-		if isNullBuf(buf) {
-			apitypes.Stdout.Write(emptyJSONBuff)
+		if isNullBuf(text) {
+			buf.Write(emptyJSONBuff)
 		} else {
-			apitypes.Stdout.Write(buf)
+			buf.Write(text)
 		}
 	}
 
-	os.Exit(exitCode)
+	return &apitypes.LSXResponse{ExitCode: exitCode, Stdout: buf.Bytes()}
+}
+
+func encodeErrorResponse(op string, err error) *apitypes.LSXResponse {
+	return &apitypes.LSXResponse{
+		ExitCode: 1,
+		Stderr:   fmt.Sprintf("error: error encoding %s: %v\n", op, err),
+	}
 }
 
 const (
@@ -358,6 +415,7 @@ func printUsage() {
 	printUsageLeftPadded(w, lpad2, "mounts\n")
 	printUsageLeftPadded(w, lpad2, "mount [-l label] [-o options] device path\n")
 	printUsageLeftPadded(w, lpad2, "umount path\n")
+	printUsageLeftPadded(w, lpad2, "serve [socket]\n")
 	fmt.Fprintln(w)
 	executorVar := "executor:    "
 	printUsageLeftPadded(w, lpad1, executorVar)
@@ -0,0 +1,212 @@
+package lsx
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/context"
+	apitypes "github.com/codedellemc/libstorage/api/types"
+)
+
+// maxRequestSize is the maximum size of a request received by the daemon.
+const maxRequestSize = 1024 * 1024
+
+// daemon executes the commands it receives from clients of the executor.
+// The commands are executed one at a time.
+type daemon struct {
+	sync.Mutex
+	ctx      apitypes.Context
+	d        apitypes.StorageExecutor
+	checksum string
+}
+
+// serve runs the executor as a daemon. The daemon reads requests from stdin
+// and writes the responses to stdout until stdin is closed, or, if the path
+// of a socket is provided, accepts connections on the UNIX socket until the
+// daemon is interrupted or terminated.
+func serve(
+	ctx apitypes.Context,
+	d apitypes.StorageExecutor,
+	args []string) error {
+
+	s := &daemon{ctx: ctx, d: d}
+
+	// clients compare the checksum with that of their executor so that a
+	// daemon is not used after the executor is updated
+	sum, err := executableChecksum()
+	if err != nil {
+		ctx.WithError(err).Warn("error getting executor checksum")
+	}
+	s.checksum = sum
+
+	if len(args) == 0 {
+		ctx.Debug("serving executor on stdio")
+		return s.serveConn(os.Stdin, apitypes.Stdout)
+	}
+
+	sockPath := args[0]
+	ctx = ctx.WithField("socket", sockPath)
+
+	// remove the socket of a daemon that did not exit cleanly
+	if fi, err := os.Stat(sockPath); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return goof.WithField("path", sockPath, "file is not a socket")
+		}
+		if err := os.Remove(sockPath); err != nil {
+			return err
+		}
+	}
+
+	l, err := net.Listen("unix", sockPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(sockPath)
+
+	if err := os.Chmod(sockPath, 0600); err != nil {
+		l.Close()
+		return err
+	}
+
+	done := make(chan struct{})
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigc
+		ctx.WithField("signal", sig).Debug("stopping executor daemon")
+		close(done)
+		l.Close()
+	}()
+
+	ctx.Debug("serving executor on socket")
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			select {
+			case <-done:
+				return nil
+			default:
+				return err
+			}
+		}
+		go func() {
+			defer conn.Close()
+			if err := s.serveConn(conn, conn); err != nil {
+				ctx.WithError(err).Warn("error serving executor client")
+			}
+		}()
+	}
+}
+
+// serveConn executes the requests read from r and writes the responses to
+// w. The first response indicates the daemon is ready.
+func (s *daemon) serveConn(r io.Reader, w io.Writer) error {
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(&apitypes.LSXResponse{
+		Ready:    true,
+		Checksum: s.checksum,
+	}); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxRequestSize)
+
+	for scanner.Scan() {
+
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var res *apitypes.LSXResponse
+		req := &apitypes.LSXRequest{}
+		if err := json.Unmarshal(line, req); err != nil {
+			res = &apitypes.LSXResponse{
+				ExitCode: 1,
+				Stderr:   fmt.Sprintf("error: invalid request: %v\n", err),
+			}
+		} else {
+			res = s.execute(req)
+		}
+
+		res.ID = req.ID
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// execute executes a request's command. A command that panics fails
+// instead of stopping the daemon.
+func (s *daemon) execute(
+	req *apitypes.LSXRequest) (res *apitypes.LSXResponse) {
+
+	s.Lock()
+	defer s.Unlock()
+
+	ctx := s.ctx
+	if req.Transaction != nil {
+		ctx = ctx.WithValue(context.TransactionKey, req.Transaction)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			ctx.WithField("args", req.Args).Errorf(
+				"executor command panicked: %v", r)
+			res = &apitypes.LSXResponse{
+				ExitCode: 1,
+				Stderr:   fmt.Sprintf("error: %v\n", r),
+			}
+		}
+	}()
+
+	if res = execute(ctx, s.d, req.Args); res == nil {
+		res = &apitypes.LSXResponse{
+			ExitCode: 1,
+			Stderr: fmt.Sprintf(
+				"error: invalid command: %s\n", strings.Join(req.Args, " ")),
+		}
+	}
+	return res
+}
+
+// executableChecksum returns the SHA-256 checksum of the running executor.
+func executableChecksum() (string, error) {
+
+	exePath := "/proc/self/exe"
+	if _, err := os.Stat(exePath); err != nil {
+		if exePath, err = exec.LookPath(os.Args[0]); err != nil {
+			return "", err
+		}
+	}
+
+	f, err := os.Open(exePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// lsxsign creates the keys used to sign executors and the executors'
+// detached signatures.
+func main() {
+	if len(os.Args) < 2 {
+		printUsageAndExit()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "genkey":
+		if len(os.Args) != 4 {
+			printUsageAndExit()
+		}
+		err = genKey(os.Args[2], os.Args[3])
+	case "sign":
+		if len(os.Args) < 4 {
+			printUsageAndExit()
+		}
+		err = sign(os.Args[2], os.Args[3:])
+	default:
+		printUsageAndExit()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// genKey writes a new, base64 encoded Ed25519 key pair to the provided
+// files.
+func genKey(privKeyPath, pubKeyPath string) error {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	if err := writeKey(privKeyPath, priv, 0600); err != nil {
+		return err
+	}
+	return writeKey(pubKeyPath, pub, 0644)
+}
+
+func writeKey(filePath string, key []byte, perm os.FileMode) error {
+	return ioutil.WriteFile(filePath, []byte(fmt.Sprintf(
+		"%s\n", base64.StdEncoding.EncodeToString(key))), perm)
+}
+
+// sign writes the detached signature of each of the provided files to a
+// file with the same name and the signature file name extension. The name of
+// each file must be the name of an executor, ex. lsx-linux-amd64, since the
+// signature covers the executor's name and platform.
+func sign(privKeyPath string, filePaths []string) error {
+	key, err := utils.ReadSigningKey(privKeyPath)
+	if err != nil {
+		return err
+	}
+	for _, filePath := range filePaths {
+		name := path.Base(filePath)
+		goos, goarch, ok := utils.ParseExecutorName(name)
+		if !ok {
+			return fmt.Errorf("%s is not the name of an executor", name)
+		}
+		buf, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		manifest := utils.NewExecutorManifest(name, goos, goarch, buf)
+		if err := ioutil.WriteFile(
+			filePath+utils.SignatureFileExt,
+			[]byte(utils.Sign(key, manifest)+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printUsageAndExit() {
+	fmt.Fprintf(os.Stderr, "usage: %s genkey PRIVATE_KEY PUBLIC_KEY\n",
+		path.Base(os.Args[0]))
+	fmt.Fprintf(os.Stderr, "       %s sign PRIVATE_KEY FILE...\n",
+		path.Base(os.Args[0]))
+	os.Exit(1)
+}
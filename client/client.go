@@ -10,6 +10,7 @@ import (
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
 	apicnfg "github.com/codedellemc/libstorage/api/utils/config"
+	"github.com/codedellemc/libstorage/api/utils/secrets"
 
 	// load the local imports
 	_ "github.com/codedellemc/libstorage/imports/local"
@@ -45,6 +46,8 @@ func New(goCtx gocontext.Context, config gofig.Config) (types.Client, error) {
 		if config, err = apicnfg.NewConfig(ctx); err != nil {
 			return nil, err
 		}
+	} else if err := secrets.Resolve(ctx, config); err != nil {
+		return nil, err
 	}
 
 	config = config.Scope(types.ConfigClient)
@@ -63,6 +63,7 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 		types.ConfigIgVolOpsCreateDefaultSize:   d.size(),
 		types.ConfigIgVolOpsCreateDefaultAZ:     d.availabilityZone(),
 		types.ConfigIgVolOpsCreateDefaultFsType: d.fsType(),
+		types.ConfigIgVolOpsCreateDefaultClass:  d.class(),
 		types.ConfigIgVolOpsMountPath:           d.mountDirPath(),
 		types.ConfigIgVolOpsCreateImplicit:      d.volumeCreateImplicit(),
 	}).Info("linux integration driver successfully initialized")
@@ -271,7 +272,7 @@ func (d *driver) Mount(
 	}
 
 	if opts.NewFSType == "" {
-		opts.NewFSType = d.fsType()
+		opts.NewFSType = d.volumeFsType(ctx, vol)
 	}
 	if err := client.OS().Format(
 		ctx,
@@ -450,41 +451,61 @@ func (d *driver) Create(
 	}
 
 	optsNew := &types.VolumeCreateOpts{}
-	az := d.availabilityZone()
-	optsNew.AvailabilityZone = &az
-	size := d.size()
-	optsNew.Size = &size
-	volumeType := d.volumeType()
-	optsNew.Type = &volumeType
-	iops := d.iops()
-	optsNew.IOPS = &iops
 	optsNew.Encrypted = opts.Encrypted
 	optsNew.EncryptionKey = opts.EncryptionKey
 
+	// when a volume is created with a storage class the server applies the
+	// class's defaults, so the driver's defaults are not used
+	class := d.class()
+	if opts.Class != nil {
+		class = *opts.Class
+	}
+	if opts.Opts.IsSet("class") {
+		class = opts.Opts.GetString("class")
+	}
+	if class != "" {
+		optsNew.Class = &class
+	} else {
+		az := d.availabilityZone()
+		optsNew.AvailabilityZone = &az
+		size := d.size()
+		optsNew.Size = &size
+		volumeType := d.volumeType()
+		optsNew.Type = &volumeType
+		iops := d.iops()
+		optsNew.IOPS = &iops
+	}
+
 	if opts.Opts.IsSet("availabilityZone") {
-		az = opts.Opts.GetString("availabilityZone")
+		az := opts.Opts.GetString("availabilityZone")
+		optsNew.AvailabilityZone = &az
 	}
 	if opts.Opts.IsSet("size") {
-		size = opts.Opts.GetInt64("size")
+		size := opts.Opts.GetInt64("size")
+		optsNew.Size = &size
 	}
 	if opts.Opts.IsSet("volumeType") {
-		volumeType = opts.Opts.GetString("volumeType")
+		volumeType := opts.Opts.GetString("volumeType")
+		optsNew.Type = &volumeType
 	}
 	if opts.Opts.IsSet("type") {
-		volumeType = opts.Opts.GetString("type")
+		volumeType := opts.Opts.GetString("type")
+		optsNew.Type = &volumeType
 	}
 	if opts.Opts.IsSet("iops") {
-		iops = opts.Opts.GetInt64("iops")
+		iops := opts.Opts.GetInt64("iops")
+		optsNew.IOPS = &iops
 	}
 
 	optsNew.Opts = opts.Opts
 
 	ctx.WithFields(log.Fields{
 		"volumeName":       volumeName,
-		"availabilityZone": az,
-		"size":             size,
-		"volumeType":       volumeType,
-		"IOPS":             iops,
+		"availabilityZone": optsNew.AvailabilityZone,
+		"size":             optsNew.Size,
+		"volumeType":       optsNew.Type,
+		"IOPS":             optsNew.IOPS,
+		"class":            class,
 		"encrypted":        optsNew.Encrypted,
 		"encryptionKey":    optsNew.EncryptionKey,
 		"opts":             opts}).Info("creating volume")
@@ -579,6 +600,38 @@ func (d *driver) fsType() string {
 	return d.config.GetString(types.ConfigIgVolOpsCreateDefaultFsType)
 }
 
+func (d *driver) class() string {
+	return d.config.GetString(types.ConfigIgVolOpsCreateDefaultClass)
+}
+
+// volumeFsType returns the file system type of the volume's storage class,
+// or of the default storage class if the volume does not have one. The
+// default file system type is returned if neither class defines one.
+func (d *driver) volumeFsType(ctx types.Context, vol *types.Volume) string {
+	class := vol.Fields[types.VolumeFieldClass]
+	if class == "" {
+		class = d.class()
+	}
+	if class == "" {
+		return d.fsType()
+	}
+
+	serviceName, ok := context.ServiceName(ctx)
+	if !ok {
+		return d.fsType()
+	}
+	classes, err := context.MustClient(ctx).API().StorageClasses(
+		ctx, serviceName)
+	if err != nil {
+		ctx.WithError(err).Warn("error getting storage classes")
+		return d.fsType()
+	}
+	if c, ok := classes[strings.ToLower(class)]; ok && c.FsType != "" {
+		return c.FsType
+	}
+	return d.fsType()
+}
+
 func (d *driver) mountDirPath() string {
 	return d.config.GetString(types.ConfigIgVolOpsMountPath)
 }
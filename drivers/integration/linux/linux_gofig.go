@@ -41,6 +41,11 @@ func init() {
 				"", "", "",
 				types.ConfigIgVolOpsCreateDefaultAZ)
 
+			r.Key(
+				gofig.String,
+				"", "", "",
+				types.ConfigIgVolOpsCreateDefaultClass)
+
 			r.Key(
 				gofig.String,
 				"",
@@ -0,0 +1,34 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_ebs libstorage_storage_driver_efs libstorage_storage_driver_fittedcloud
+
+package utils
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// TxUserAgent returns the user agent token that tags the API calls made on
+// behalf of the context's transaction.
+func TxUserAgent(txID string) string {
+	return fmt.Sprintf("libstorage-tx/%s", txID)
+}
+
+// WithTxUserAgent returns a copy of the cached client that appends the ID of
+// the context's transaction to the user agent of its API calls. The client is
+// returned as-is if the context has no transaction.
+func WithTxUserAgent(ctx types.Context, c *client.Client) *client.Client {
+	txID, ok := context.TransactionID(ctx)
+	if !ok {
+		return c
+	}
+	txc := *c
+	txc.Handlers = c.Handlers.Copy()
+	txc.Handlers.Build.PushBack(
+		request.MakeAddToUserAgentFreeFormHandler(TxUserAgent(txID)))
+	return &txc
+}
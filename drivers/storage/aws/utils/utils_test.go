@@ -0,0 +1,49 @@
+// +build !libstorage_storage_driver libstorage_storage_driver_ebs libstorage_storage_driver_efs libstorage_storage_driver_fittedcloud
+
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codedellemc/libstorage/api/context"
+)
+
+func newTestClient() *client.Client {
+	return client.New(
+		aws.Config{Endpoint: aws.String("http://127.0.0.1")},
+		metadata.ClientInfo{ServiceName: "test"},
+		request.Handlers{})
+}
+
+func userAgent(c *client.Client) string {
+	req := c.NewRequest(&request.Operation{Name: "Test"}, nil, nil)
+	req.Handlers.Build.Run(req)
+	return req.HTTPRequest.Header.Get("User-Agent")
+}
+
+func TestWithTxUserAgent(t *testing.T) {
+	ctx := context.RequireTX(context.Background())
+	txID, ok := context.TransactionID(ctx)
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+
+	c := newTestClient()
+	txc := WithTxUserAgent(ctx, c)
+	assert.True(t, strings.Contains(userAgent(txc), TxUserAgent(txID)))
+
+	// the cached client must not be modified
+	assert.False(t, strings.Contains(userAgent(c), "libstorage-tx/"))
+}
+
+func TestWithTxUserAgentNoTx(t *testing.T) {
+	c := newTestClient()
+	assert.True(t, c == WithTxUserAgent(context.Background(), c))
+}
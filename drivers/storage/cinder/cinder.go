@@ -11,6 +11,10 @@ const (
 	// Name is the provider's name.
 	Name string = "cinder"
 
+	// InstanceIDFieldAvailabilityZone is the key to retrieve the
+	// availability zone value from the InstanceID Field map.
+	InstanceIDFieldAvailabilityZone = "availabilityZone"
+
 	// ConfigAuthURL is the config key for the Identity Auth URL
 	ConfigAuthURL = Name + ".authURL"
 
@@ -45,6 +49,11 @@ const (
 	// zone name
 	ConfigAvailabilityZoneName = Name + ".availabilityZoneName"
 
+	// ConfigAvailabilityZoneMap is the config key for the comma-separated
+	// list of computeZone=volumeZone pairs that map the Nova availability
+	// zones of instances to the Cinder availability zones of their volumes
+	ConfigAvailabilityZoneMap = Name + ".availabilityZoneMap"
+
 	// ConfigTrustID is the config key for the trust ID
 	ConfigTrustID = Name + ".trustID"
 
@@ -74,6 +83,7 @@ func init() {
 	r.Key(gofig.String, "", "", "", ConfigDomainName)
 	r.Key(gofig.String, "", "", "", ConfigRegionName)
 	r.Key(gofig.String, "", "", "", ConfigAvailabilityZoneName)
+	r.Key(gofig.String, "", "", "", ConfigAvailabilityZoneMap)
 	r.Key(gofig.String, "", "", "", ConfigTrustID)
 	r.Key(gofig.String, "", "1m", "", ConfigAttachTimeout)
 	r.Key(gofig.String, "", "10m", "", ConfigDeleteTimeout)
@@ -58,13 +58,13 @@ func (d *driver) Name() string {
 
 func (d *driver) InstanceID(ctx types.Context, opts types.Store) (*types.InstanceID, error) {
 	fields := map[string]interface{}{}
-	uuid, err := getInstanceIDFromMetadataServer()
+	md, err := getInstanceIDFromMetadataServer()
 	if err != nil {
 		fields["metadataServer"] = err
-		uuid, err = getInstanceIDFromConfigDrive(ctx, d)
+		md, err = getInstanceIDFromConfigDrive(ctx, d)
 		if err != nil {
 			fields["configDrive"] = err
-			uuid, err = getInstanceIDWithDMIDecode()
+			md, err = getInstanceIDWithDMIDecode()
 			if err != nil {
 				fields["dmidecode"] = err
 				return nil, goof.WithFields(fields, "unable to get InstanceID from any sources")
@@ -72,27 +72,42 @@ func (d *driver) InstanceID(ctx types.Context, opts types.Store) (*types.Instanc
 		}
 	}
 
-	iid := &types.InstanceID{Driver: cinder.Name, ID: strings.ToLower(uuid)}
+	iid := &types.InstanceID{Driver: cinder.Name, ID: strings.ToLower(md.uuid)}
+	if md.availabilityZone != "" {
+		iid.Fields = map[string]string{
+			cinder.InstanceIDFieldAvailabilityZone: md.availabilityZone,
+		}
+	}
 
 	return iid, nil
 }
 
-func parseUUID(metadata []byte) (string, error) {
+// instanceMetadata is the information about an instance that is read from
+// the metadata server, the config drive, or dmidecode.
+type instanceMetadata struct {
+	uuid             string
+	availabilityZone string
+}
+
+func parseMetadata(metadata []byte) (*instanceMetadata, error) {
 	var decodedJSON interface{}
 	err := json.Unmarshal(metadata, &decodedJSON)
 	if err != nil {
-		return "", goof.WithError("error unmarshalling metadata", err)
+		return nil, goof.WithError("error unmarshalling metadata", err)
 	}
 	decodedJSONMap, ok := decodedJSON.(map[string]interface{})
 	if !ok {
-		return "", goof.New("error casting metadata decoded JSON")
+		return nil, goof.New("error casting metadata decoded JSON")
 	}
 	uuid, ok := decodedJSONMap["uuid"].(string)
 	if !ok {
-		return "", goof.New("error casting metadata uuid field")
+		return nil, goof.New("error casting metadata uuid field")
 	}
 
-	return uuid, nil
+	// the availability zone is optional
+	az, _ := decodedJSONMap["availability_zone"].(string)
+
+	return &instanceMetadata{uuid: uuid, availabilityZone: az}, nil
 }
 
 func execCommand(cmd string, args ...string) (string, error) {
@@ -117,7 +132,7 @@ func execCommand(cmd string, args ...string) (string, error) {
 const configDriveLabel = "config-2"
 const configDrivePath = "openstack/latest/meta_data.json"
 
-func getInstanceIDFromConfigDrive(ctx types.Context, d *driver) (string, error) {
+func getInstanceIDFromConfigDrive(ctx types.Context, d *driver) (*instanceMetadata, error) {
 	// Try to read instance UUID from config drive.
 	dev := "/dev/disk/by-label/" + configDriveLabel
 	if _, err := os.Stat(dev); os.IsNotExist(err) {
@@ -128,14 +143,14 @@ func getInstanceIDFromConfigDrive(ctx types.Context, d *driver) (string, error)
 		)
 
 		if err != nil {
-			return "", goof.WithError("Unable to run blkid", err)
+			return nil, goof.WithError("Unable to run blkid", err)
 		}
 		dev = strings.TrimSpace(string(cmdOut))
 	}
 
 	mntdir, err := ioutil.TempDir("", "configdrive")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer os.Remove(mntdir)
 
@@ -149,19 +164,19 @@ func getInstanceIDFromConfigDrive(ctx types.Context, d *driver) (string, error)
 		err = d.osDriver.Mount(ctx, dev, mntdir, &mountOpts)
 	}
 	if err != nil {
-		return "", goof.WithFieldE("device", dev, "error mounting configdrive", err)
+		return nil, goof.WithFieldE("device", dev, "error mounting configdrive", err)
 	}
 	defer d.osDriver.Unmount(ctx, mntdir, utils.NewStore())
 
 	metadataBytes, err := ioutil.ReadFile(filepath.Join(mntdir, configDrivePath))
 	if err != nil {
-		return "", goof.WithError("error reading metadata file on config drive", err)
+		return nil, goof.WithError("error reading metadata file on config drive", err)
 	}
 
-	return parseUUID(metadataBytes)
+	return parseMetadata(metadataBytes)
 }
 
-func getInstanceIDFromMetadataServer() (string, error) {
+func getInstanceIDFromMetadataServer() (*instanceMetadata, error) {
 	const metadataURL = "http://169.254.169.254/openstack/latest/meta_data.json"
 	httpClient := http.Client{
 		Timeout: time.Duration(5 * time.Second),
@@ -169,28 +184,28 @@ func getInstanceIDFromMetadataServer() (string, error) {
 
 	resp, err := httpClient.Get(metadataURL)
 	if err != nil {
-		return "", goof.WithFieldE("url", metadataURL, "error getting metadata from server", err)
+		return nil, goof.WithFieldE("url", metadataURL, "error getting metadata from server", err)
 	}
 	defer resp.Body.Close()
 
 	metadataBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", goof.WithError("io error reading metadata", err)
+		return nil, goof.WithError("io error reading metadata", err)
 	}
 
-	return parseUUID(metadataBytes)
+	return parseMetadata(metadataBytes)
 }
 
-func getInstanceIDWithDMIDecode() (string, error) {
+func getInstanceIDWithDMIDecode() (*instanceMetadata, error) {
 	cmdOut, err := execCommand("dmidecode", "-t", "system")
 	if err != nil {
-		return "", goof.WithError("error calling dmidecode", err)
+		return nil, goof.WithError("error calling dmidecode", err)
 	}
 
 	rp := regexp.MustCompile("UUID:(.*)")
 	uuid := strings.Replace(rp.FindString(string(cmdOut)), "UUID: ", "", -1)
 
-	return strings.ToLower(uuid), nil
+	return &instanceMetadata{uuid: strings.ToLower(uuid)}, nil
 }
 
 func (d *driver) NextDevice(
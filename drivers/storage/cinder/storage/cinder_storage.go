@@ -3,6 +3,9 @@
 package storage
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	gofig "github.com/akutz/gofig/types"
@@ -11,6 +14,7 @@ import (
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
+	apiUtils "github.com/codedellemc/libstorage/api/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/cinder"
 
 	"github.com/gophercloud/gophercloud"
@@ -25,6 +29,12 @@ import (
 
 const (
 	minSizeGiB = 1
+
+	openStackRequestIDHeader = "X-Openstack-Request-Id"
+
+	// labelMetadataPrefix prefixes the metadata keys that persist the labels
+	// the server records on the volumes and snapshots it creates
+	labelMetadataPrefix = "libstorage:"
 )
 
 type driver struct {
@@ -33,6 +43,7 @@ type driver struct {
 	clientBlockStorage   *gophercloud.ServiceClient
 	clientBlockStoragev2 *gophercloud.ServiceClient
 	availabilityZone     string
+	zoneMap              map[string]string
 	config               gofig.Config
 }
 
@@ -83,6 +94,11 @@ func (d *driver) Init(context types.Context, config gofig.Config) error {
 	d.availabilityZone = d.availabilityZoneName()
 	fields["availabilityZone"] = d.availabilityZone
 
+	if d.zoneMap, err = d.availabilityZoneMap(); err != nil {
+		return goof.WithFieldsE(fields, "invalid availability zone map", err)
+	}
+	fields["availabilityZoneMap"] = d.zoneMap
+
 	authOpts := d.getAuthOptions()
 
 	fields["identityEndpoint"] = d.authURL()
@@ -148,16 +164,71 @@ func (d *driver) Init(context types.Context, config gofig.Config) error {
 	return nil
 }
 
+// PersistsLabels returns true since labels are persisted as volume and
+// snapshot metadata.
+func (d *driver) PersistsLabels(ctx types.Context) bool {
+	return true
+}
+
 // InstanceInspect returns an instance.
 func (d *driver) InstanceInspect(
 	ctx types.Context,
 	opts types.Store) (*types.Instance, error) {
 
+	iid := context.MustInstanceID(ctx)
+	zone := d.volumeZone(iid.Fields[cinder.InstanceIDFieldAvailabilityZone])
 	return &types.Instance{
-		InstanceID: context.MustInstanceID(ctx),
+		InstanceID:       iid,
+		Region:           d.regionName(),
+		AvailabilityZone: zone,
 	}, nil
 }
 
+// volumeZone returns the Cinder availability zone that serves volumes to
+// instances in the given Nova availability zone. Nova and Cinder zones are
+// separate namespaces, so a zone without an explicit mapping is assumed to
+// have the same name in both services. A zone mapped to an empty string has
+// no corresponding Cinder zone and does not restrict the volumes its
+// instances may attach.
+func (d *driver) volumeZone(computeZone string) string {
+	if volZone, ok := d.zoneMap[computeZone]; ok {
+		return volZone
+	}
+	return computeZone
+}
+
+type availabilityZoneInfo struct {
+	ZoneName  string `json:"zoneName"`
+	ZoneState struct {
+		Available bool `json:"available"`
+	} `json:"zoneState"`
+}
+
+// AvailabilityZones returns the available zones of the block storage
+// service.
+func (d *driver) AvailabilityZones(ctx types.Context) ([]string, error) {
+	client := d.clientBlockStoragev2
+	if client == nil {
+		client = d.clientBlockStorage
+	}
+
+	var res struct {
+		AvailabilityZoneInfo []*availabilityZoneInfo `json:"availabilityZoneInfo"`
+	}
+	url := client.ServiceURL("os-availability-zone")
+	if _, err := withTx(ctx, client).Get(url, &res, nil); err != nil {
+		return nil, goof.WithError("error listing availability zones", err)
+	}
+
+	zones := []string{}
+	for _, z := range res.AvailabilityZoneInfo {
+		if z.ZoneState.Available {
+			zones = append(zones, z.ZoneName)
+		}
+	}
+	return zones, nil
+}
+
 func (d *driver) getAuthOptions() gophercloud.AuthOptions {
 	return gophercloud.AuthOptions{
 		IdentityEndpoint: d.authURL(),
@@ -178,7 +249,7 @@ func (d *driver) Volumes(
 	opts *types.VolumesOpts) ([]*types.Volume, error) {
 
 	if d.clientBlockStoragev2 != nil {
-		allPages, err := volumes.List(d.clientBlockStoragev2, nil).AllPages()
+		allPages, err := volumes.List(withTx(ctx, d.clientBlockStoragev2), nil).AllPages()
 		if err != nil {
 			return nil,
 				goof.WithError("error listing volumes", err)
@@ -198,7 +269,7 @@ func (d *driver) Volumes(
 		return volumesRet, nil
 	}
 
-	allPages, err := volumesv1.List(d.clientBlockStorage, nil).AllPages()
+	allPages, err := volumesv1.List(withTx(ctx, d.clientBlockStorage), nil).AllPages()
 	if err != nil {
 		return nil,
 			goof.WithError("error listing volumes", err)
@@ -232,7 +303,7 @@ func (d *driver) VolumeInspect(
 	}
 
 	if d.clientBlockStoragev2 != nil {
-		volume, err := volumes.Get(d.clientBlockStoragev2, volumeID).Extract()
+		volume, err := volumes.Get(withTx(ctx, d.clientBlockStoragev2), volumeID).Extract()
 
 		if err != nil {
 			return nil,
@@ -242,7 +313,7 @@ func (d *driver) VolumeInspect(
 		return translateVolume(volume, opts.Attachments), nil
 	}
 
-	volume, err := volumesv1.Get(d.clientBlockStorage, volumeID).Extract()
+	volume, err := volumesv1.Get(withTx(ctx, d.clientBlockStorage), volumeID).Extract()
 
 	if err != nil {
 		return nil,
@@ -278,6 +349,7 @@ func translateVolumeV1(
 		IOPS:             0,
 		Size:             int64(volume.Size),
 		Attachments:      attachments,
+		Fields:           labelsFromMetadata(volume.Metadata),
 	}
 }
 
@@ -307,6 +379,7 @@ func translateVolume(
 		IOPS:             0,
 		Size:             int64(volume.Size),
 		Attachments:      attachments,
+		Fields:           labelsFromMetadata(volume.Metadata),
 	}
 }
 
@@ -319,7 +392,7 @@ func (d *driver) SnapshotInspect(
 		"snapshotId": snapshotID,
 	})
 
-	snapshot, err := snapshots.Get(d.clientBlockStorage, snapshotID).Extract()
+	snapshot, err := snapshots.Get(withTx(ctx, d.clientBlockStorage), snapshotID).Extract()
 	if err != nil {
 		return nil,
 			goof.WithFieldsE(fields, "error getting snapshot", err)
@@ -331,7 +404,7 @@ func (d *driver) SnapshotInspect(
 func (d *driver) Snapshots(
 	ctx types.Context,
 	opts types.Store) ([]*types.Snapshot, error) {
-	allPages, err := snapshots.List(d.clientBlockStorage, nil).AllPages()
+	allPages, err := snapshots.List(withTx(ctx, d.clientBlockStorage), nil).AllPages()
 	if err != nil {
 		return []*types.Snapshot{},
 			goof.WithError("error listing volume snapshots", err)
@@ -359,9 +432,49 @@ func translateSnapshot(snapshot *snapshots.Snapshot) *types.Snapshot {
 		StartTime:   time.Time(snapshot.CreatedAt).Unix(),
 		Description: snapshot.Description,
 		Status:      snapshot.Status,
+		Fields:      labelsFromSnapshotMetadata(snapshot.Metadata),
 	}
 }
 
+// labelsToMetadata returns the metadata that persists the labels.
+func labelsToMetadata(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	md := map[string]string{}
+	for k, v := range labels {
+		md[labelMetadataPrefix+k] = v
+	}
+	return md
+}
+
+// labelsFromMetadata returns the labels persisted as metadata.
+func labelsFromMetadata(md map[string]string) map[string]string {
+	var labels map[string]string
+	for k, v := range md {
+		if !strings.HasPrefix(k, labelMetadataPrefix) {
+			continue
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[strings.TrimPrefix(k, labelMetadataPrefix)] = v
+	}
+	return labels
+}
+
+// labelsFromSnapshotMetadata returns the labels persisted as the metadata of
+// a snapshot, the values of which are not typed as strings.
+func labelsFromSnapshotMetadata(md map[string]interface{}) map[string]string {
+	smd := map[string]string{}
+	for k, v := range md {
+		if s, ok := v.(string); ok {
+			smd[k] = s
+		}
+	}
+	return labelsFromMetadata(smd)
+}
+
 func (d *driver) VolumeSnapshot(
 	ctx types.Context,
 	volumeID, snapshotName string,
@@ -377,8 +490,14 @@ func (d *driver) VolumeSnapshot(
 		VolumeID: volumeID,
 		Force:    true,
 	}
+	if md := labelsToMetadata(apiUtils.Labels(opts)); md != nil {
+		createOpts.Metadata = map[string]interface{}{}
+		for k, v := range md {
+			createOpts.Metadata[k] = v
+		}
+	}
 
-	snapshot, err := snapshots.Create(d.clientBlockStorage, createOpts).Extract()
+	snapshot, err := snapshots.Create(withTx(ctx, d.clientBlockStorage), createOpts).Extract()
 	if err != nil {
 		return nil,
 			goof.WithFieldsE(fields, "error creating snapshot", err)
@@ -386,7 +505,7 @@ func (d *driver) VolumeSnapshot(
 
 	ctx.WithFields(fields).Info("waiting for snapshot creation to complete")
 
-	err = snapshots.WaitForStatus(d.clientBlockStorage, snapshot.ID, "available", int(d.snapshotTimeout().Seconds()))
+	err = snapshots.WaitForStatus(withTx(ctx, d.clientBlockStorage), snapshot.ID, "available", int(d.snapshotTimeout().Seconds()))
 	if err != nil {
 		return nil,
 			goof.WithFieldsE(fields,
@@ -416,12 +535,12 @@ func (d *driver) SnapshotRemove(
 	ctx types.Context,
 	snapshotID string,
 	opts types.Store) error {
-	resp := snapshots.Delete(d.clientBlockStorage, snapshotID)
+	resp := snapshots.Delete(withTx(ctx, d.clientBlockStorage), snapshotID)
 	if resp.Err != nil {
 		return goof.WithFieldE("snapshotId", snapshotID, "error removing snapshot", resp.Err)
 	}
 
-	err := waitFor404(d.clientBlockStorage, d.clientBlockStorage.ServiceURL("snapshots", snapshotID), int(d.deleteTimeout().Seconds()))
+	err := waitFor404(withTx(ctx, d.clientBlockStorage), d.clientBlockStorage.ServiceURL("snapshots", snapshotID), int(d.deleteTimeout().Seconds()))
 	if err != nil {
 		return goof.WithFieldE("snapshotId", snapshotID, "error waiting for snapshot removal", err)
 	}
@@ -455,6 +574,7 @@ func (d *driver) VolumeCopy(
 	volumeCreateOpts := &types.VolumeCreateOpts{
 		Type:             &volume.Type,
 		AvailabilityZone: &volume.AvailabilityZone,
+		Opts:             opts,
 	}
 
 	return d.createVolume(ctx, volumeName, volumeID, "", volumeCreateOpts)
@@ -471,6 +591,7 @@ func (d *driver) createVolume(
 		Name:          volumeName,
 		SnapshotID:    snapshotID,
 		SourceReplica: volumeSourceID,
+		Metadata:      labelsToMetadata(apiUtils.Labels(opts.Opts)),
 	}
 
 	fields := eff(map[string]interface{}{
@@ -506,7 +627,7 @@ func (d *driver) createVolume(
 	fields["size"] = *opts.Size
 
 	if d.clientBlockStoragev2 != nil {
-		volume, err := volumes.Create(d.clientBlockStoragev2, options).Extract()
+		volume, err := volumes.Create(withTx(ctx, d.clientBlockStoragev2), options).Extract()
 		if err != nil {
 			return nil,
 				goof.WithFieldsE(fields, "error creating volume", err)
@@ -515,7 +636,7 @@ func (d *driver) createVolume(
 		fields["volumeId"] = volume.ID
 
 		ctx.WithFields(fields).Info("waiting for volume creation to complete")
-		err = volumes.WaitForStatus(d.clientBlockStoragev2, volume.ID, "available", int(d.createTimeout().Seconds()))
+		err = volumes.WaitForStatus(withTx(ctx, d.clientBlockStoragev2), volume.ID, "available", int(d.createTimeout().Seconds()))
 		if err != nil {
 			return nil,
 				goof.WithFieldsE(fields,
@@ -525,7 +646,7 @@ func (d *driver) createVolume(
 		return translateVolume(volume, types.VolumeAttachmentsRequested), nil
 	}
 
-	volume, err := volumesv1.Create(d.clientBlockStorage, options).Extract()
+	volume, err := volumesv1.Create(withTx(ctx, d.clientBlockStorage), options).Extract()
 	if err != nil {
 		return nil,
 			goof.WithFieldsE(fields, "error creating volume", err)
@@ -534,7 +655,7 @@ func (d *driver) createVolume(
 	fields["volumeId"] = volume.ID
 
 	ctx.WithFields(fields).Info("waiting for volume creation to complete")
-	err = volumesv1.WaitForStatus(d.clientBlockStorage, volume.ID, "available", int(d.createTimeout().Seconds()))
+	err = volumesv1.WaitForStatus(withTx(ctx, d.clientBlockStorage), volume.ID, "available", int(d.createTimeout().Seconds()))
 	if err != nil {
 		return nil,
 			goof.WithFieldsE(fields,
@@ -555,13 +676,13 @@ func (d *driver) VolumeRemove(
 		return goof.WithFields(fields, "volumeId is required")
 	}
 
-	volumesClient := d.clientBlockStorage
+	volumesClient := withTx(ctx, d.clientBlockStorage)
 	var res gophercloud.ErrResult
 	if d.clientBlockStoragev2 != nil {
-		volumesClient = d.clientBlockStoragev2
-		res = volumes.Delete(d.clientBlockStoragev2, volumeID).ErrResult
+		volumesClient = withTx(ctx, d.clientBlockStoragev2)
+		res = volumes.Delete(volumesClient, volumeID).ErrResult
 	} else {
-		res = volumesv1.Delete(d.clientBlockStorage, volumeID).ErrResult
+		res = volumesv1.Delete(volumesClient, volumeID).ErrResult
 	}
 
 	if res.Err != nil {
@@ -592,6 +713,10 @@ func (d *driver) VolumeAttach(
 		"instanceId": iid.ID,
 	})
 
+	if err := d.checkAvailabilityZone(ctx, volumeID); err != nil {
+		return nil, "", err
+	}
+
 	if opts.Force {
 		// check if attached before trying a detach
 		if vol, err := d.VolumeInspect(ctx, volumeID, &types.VolumeInspectOpts{Attachments: types.VolAttReq}); err == nil {
@@ -610,7 +735,7 @@ func (d *driver) VolumeAttach(
 		options.Device = *opts.NextDevice
 	}
 
-	volumeAttach, err := volumeattach.Create(d.clientCompute, iid.ID, options).Extract()
+	volumeAttach, err := volumeattach.Create(withTx(ctx, d.clientCompute), iid.ID, options).Extract()
 	if err != nil {
 		return nil, "", goof.WithFieldsE(
 			fields, "error attaching volume", err)
@@ -626,6 +751,28 @@ func (d *driver) VolumeAttach(
 	return volume, volumeAttach.Device, nil
 }
 
+// checkAvailabilityZone returns an error if the volume is not in the
+// instance's availability zone.
+func (d *driver) checkAvailabilityZone(
+	ctx types.Context, volumeID string) error {
+
+	iid := context.MustInstanceID(ctx)
+	instZone := d.volumeZone(iid.Fields[cinder.InstanceIDFieldAvailabilityZone])
+	if instZone == "" {
+		return nil
+	}
+	vol, err := d.VolumeInspect(
+		ctx, volumeID, &types.VolumeInspectOpts{Attachments: types.VolAttNone})
+	if err != nil {
+		return err
+	}
+	if vol.AvailabilityZone != "" && vol.AvailabilityZone != instZone {
+		return apiUtils.NewAvailabilityZoneMismatchErr(
+			volumeID, vol.AvailabilityZone, instZone)
+	}
+	return nil
+}
+
 func (d *driver) VolumeDetach(
 	ctx types.Context,
 	volumeID string,
@@ -642,7 +789,7 @@ func (d *driver) VolumeDetach(
 		return nil, goof.WithFields(fields, "volumeId is required")
 	}
 
-	resp := volumeattach.Delete(d.clientCompute, iid.ID, volumeID)
+	resp := volumeattach.Delete(withTx(ctx, d.clientCompute), iid.ID, volumeID)
 	if resp.Err != nil {
 		return nil, goof.WithFieldsE(fields, "error detaching volume", resp.Err)
 	}
@@ -654,7 +801,7 @@ func (d *driver) VolumeDetach(
 	}
 
 	if opts.Force && d.clientBlockStoragev2 != nil {
-		resp := volumeactions.Detach(d.clientBlockStoragev2, volumeID, volumeactions.DetachOpts{})
+		resp := volumeactions.Detach(withTx(ctx, d.clientBlockStoragev2), volumeID, volumeactions.DetachOpts{})
 
 		if resp.Err != nil {
 			return nil, goof.WithFieldsE(fields, "error force detaching volume", resp.Err)
@@ -760,6 +907,25 @@ func (d *driver) availabilityZoneName() string {
 	return d.config.GetString(cinder.ConfigAvailabilityZoneName)
 }
 
+// availabilityZoneMap parses the computeZone=volumeZone pairs of the
+// availability zone map.
+func (d *driver) availabilityZoneMap() (map[string]string, error) {
+	zoneMap := map[string]string{}
+	for _, pair := range strings.Split(
+		d.config.GetString(cinder.ConfigAvailabilityZoneMap), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		computeZone := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || computeZone == "" {
+			return nil, goof.WithField("pair", pair, "invalid zone pair")
+		}
+		zoneMap[computeZone] = strings.TrimSpace(kv[1])
+	}
+	return zoneMap, nil
+}
+
 func (d *driver) trustID() string {
 	return d.config.GetString(cinder.ConfigTrustID)
 }
@@ -803,3 +969,60 @@ func (d *driver) snapshotTimeout() time.Duration {
 	}
 	return val
 }
+
+// withTx returns a copy of the provided service client that sends the
+// context's transaction ID with each request as the OpenStack request ID so
+// the API calls may be correlated with the libStorage logs.
+func withTx(
+	ctx types.Context,
+	c *gophercloud.ServiceClient) *gophercloud.ServiceClient {
+
+	txID, ok := context.TransactionID(ctx)
+	if !ok || c == nil || c.ProviderClient == nil {
+		return c
+	}
+
+	pc := *c.ProviderClient
+	pc.HTTPClient.Transport = &txTransport{
+		requestID: fmt.Sprintf("req-%s", txID),
+		base:      c.ProviderClient.HTTPClient.Transport,
+	}
+
+	// the copy must see the token obtained when the original client
+	// reauthenticates
+	if reauth := c.ProviderClient.ReauthFunc; reauth != nil {
+		orig := c.ProviderClient
+		pc.ReauthFunc = func() error {
+			if err := reauth(); err != nil {
+				return err
+			}
+			pc.TokenID = orig.TokenID
+			return nil
+		}
+	}
+
+	sc := *c
+	sc.ProviderClient = &pc
+	return &sc
+}
+
+// txTransport is an http.RoundTripper that sets the OpenStack request ID
+// header on each request.
+type txTransport struct {
+	requestID string
+	base      http.RoundTripper
+}
+
+func (t *txTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	r := *req
+	r.Header = http.Header{}
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	r.Header.Set(openStackRequestIDHeader, t.requestID)
+	return base.RoundTrip(&r)
+}
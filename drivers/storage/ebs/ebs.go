@@ -73,6 +73,10 @@ const (
 	// Encrypted flag set to true.
 	KmsKeyID = "kmsKeyID"
 
+	// VolumeType is the type of the volumes created without a type, such as
+	// gp2 or io1. EC2's default type is used if no type is configured.
+	VolumeType = "volumeType"
+
 	// ConfigStatusMaxAttempts is the key for the maximum number of times
 	// a volume status will be queried when waiting for an action to finish
 	ConfigStatusMaxAttempts = Name + ".statusMaxAttempts"
@@ -95,6 +99,7 @@ func init() {
 	r.Key(gofig.Int, "", DefaultMaxRetries, "", Name+"."+MaxRetries)
 	r.Key(gofig.String, "", "", "Tag prefix for EBS naming", Name+"."+Tag)
 	r.Key(gofig.String, "", "", "", Name+"."+KmsKeyID)
+	r.Key(gofig.String, "", "", "Default volume type", Name+"."+VolumeType)
 	r.Key(gofig.Int, "", defaultStatusMaxAttempts, "Max Status Attempts",
 		ConfigStatusMaxAttempts)
 	r.Key(gofig.String, "", defaultStatusInitDelay, "Status Initial Delay",
@@ -35,6 +35,9 @@ const (
 	// ConfigEBSKmsKeyID is a config key.
 	ConfigEBSKmsKeyID = ConfigEBS + "." + KmsKeyID
 
+	// ConfigEBSVolumeType is a config key.
+	ConfigEBSVolumeType = ConfigEBS + "." + VolumeType
+
 	// ConfigEC2 is a config key.
 	ConfigEC2 = "ec2"
 
@@ -26,6 +26,7 @@ import (
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
 	apiUtils "github.com/codedellemc/libstorage/api/utils"
+	awsUtils "github.com/codedellemc/libstorage/drivers/storage/aws/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/ebs"
 	ebsUtils "github.com/codedellemc/libstorage/drivers/storage/ebs/utils"
 )
@@ -39,6 +40,10 @@ const (
 	waitVolumeDetach = "detach"
 
 	minSizeGiB = 1
+
+	// labelTagPrefix prefixes the keys of the tags that persist the labels
+	// the server records on the volumes it creates
+	labelTagPrefix = "libstorage:"
 )
 
 type driver struct {
@@ -49,6 +54,7 @@ type driver struct {
 	maxRetries    *int
 	accessKey     string
 	kmsKeyID      string
+	volumeType    string
 	maxAttempts   int
 	statusDelay   int64
 	statusTimeout time.Duration
@@ -87,6 +93,7 @@ func (d *driver) Init(context types.Context, config gofig.Config) error {
 	maxRetries := d.getMaxRetries()
 	d.maxRetries = &maxRetries
 	d.kmsKeyID = d.getKmsKeyID()
+	d.volumeType = d.config.GetString(ebs.ConfigEBSVolumeType)
 
 	d.maxAttempts = d.config.GetInt(ebs.ConfigStatusMaxAttempts)
 
@@ -206,7 +213,8 @@ func (d *driver) Login(ctx types.Context) (interface{}, error) {
 }
 
 func mustSession(ctx types.Context) *awsec2.EC2 {
-	return context.MustSession(ctx).(*awsec2.EC2)
+	svc := context.MustSession(ctx).(*awsec2.EC2)
+	return &awsec2.EC2{Client: awsUtils.WithTxUserAgent(ctx, svc.Client)}
 }
 
 func mustInstanceIDID(ctx types.Context) *string {
@@ -253,13 +261,39 @@ func (d *driver) InstanceInspect(
 
 	iid := context.MustInstanceID(ctx)
 	return &types.Instance{
-		Name:         iid.ID,
-		Region:       iid.Fields[ebs.InstanceIDFieldRegion],
-		InstanceID:   iid,
-		ProviderName: iid.Driver,
+		Name:             iid.ID,
+		Region:           iid.Fields[ebs.InstanceIDFieldRegion],
+		AvailabilityZone: iid.Fields[ebs.InstanceIDFieldAvailabilityZone],
+		InstanceID:       iid,
+		ProviderName:     iid.Driver,
 	}, nil
 }
 
+// AvailabilityZones returns the available zones of the region to which the
+// driver is connected.
+func (d *driver) AvailabilityZones(ctx types.Context) ([]string, error) {
+	resp, err := mustSession(ctx).DescribeAvailabilityZones(
+		&awsec2.DescribeAvailabilityZonesInput{
+			Filters: []*awsec2.Filter{
+				{
+					Name:   aws.String("state"),
+					Values: []*string{aws.String("available")},
+				},
+			},
+		})
+	if err != nil {
+		return nil, goof.WithError(
+			"error describing availability zones", err)
+	}
+	zones := []string{}
+	for _, z := range resp.AvailabilityZones {
+		if z.ZoneName != nil {
+			zones = append(zones, *z.ZoneName)
+		}
+	}
+	return zones, nil
+}
+
 // Volumes returns all volumes or a filtered list of volumes.
 func (d *driver) Volumes(
 	ctx types.Context,
@@ -303,6 +337,70 @@ func (d *driver) VolumeInspect(
 	return vols[0], nil
 }
 
+const (
+	volumeTypeIO1    = "io1"
+	maxIOPSPerGiBIO1 = 50
+)
+
+// Validate checks the size, type, and IOPS of a volume that would be created
+// by a dry-run operation, as well as the uniqueness of its name.
+func (d *driver) Validate(
+	ctx types.Context,
+	opts *types.ValidateOpts) error {
+
+	createOpts := opts.CreateOpts
+	if createOpts == nil {
+		return nil
+	}
+
+	fields := map[string]interface{}{
+		"provider":   d.Name(),
+		"volumeName": opts.Name,
+	}
+
+	if createOpts.Size != nil && *createOpts.Size < minSizeGiB {
+		return goof.WithFields(fields, "volume size too small")
+	}
+
+	var iops int64
+	if createOpts.IOPS != nil {
+		iops = *createOpts.IOPS
+		fields["iops"] = iops
+	}
+
+	// a volume created without a type is created with the default type
+	volType := d.volumeType
+	if createOpts.Type != nil {
+		volType = *createOpts.Type
+	}
+	fields["type"] = volType
+
+	switch {
+	case volType == volumeTypeIO1 && iops <= 0:
+		return goof.WithFields(fields, "volume type requires iops")
+	case volType != volumeTypeIO1 && iops > 0:
+		return goof.WithFields(fields, "volume type does not support iops")
+	case volType == volumeTypeIO1 && createOpts.Size != nil &&
+		iops > *createOpts.Size*maxIOPSPerGiBIO1:
+		fields["size"] = *createOpts.Size
+		return goof.WithFields(fields, "iops exceed maximum for volume size")
+	}
+
+	if opts.Operation != "volumeCreate" || opts.Name == "" {
+		return nil
+	}
+
+	ec2vols, err := d.getVolume(ctx, "", opts.Name)
+	if err != nil {
+		return goof.WithFieldsE(fields, "error getting volume", err)
+	}
+	if len(ec2vols) > 0 {
+		return goof.WithFields(fields, "volume name already exists")
+	}
+
+	return nil
+}
+
 // VolumeCreate creates a new volume.
 func (d *driver) VolumeCreate(ctx types.Context, volumeName string,
 	opts *types.VolumeCreateOpts) (*types.Volume, error) {
@@ -596,6 +694,9 @@ func (d *driver) VolumeAttach(
 
 	// Check if there a volume to attach
 	if len(volumes) == 0 {
+		if err := d.checkAvailabilityZone(ctx, volumeID); err != nil {
+			return nil, "", err
+		}
 		return nil, "", goof.New("no volume found")
 	}
 	// Check if volume is already attached
@@ -873,10 +974,22 @@ func (d *driver) getVolume(
 	ctx types.Context,
 	volumeID, volumeName string) ([]*awsec2.Volume, error) {
 
+	return d.getVolumeInZone(
+		ctx, volumeID, volumeName, d.mustAvailabilityZone(ctx))
+}
+
+// getVolumeInZone searches for and returns volumes matching criteria in the
+// provided availability zone, or in all of the region's zones if the zone
+// is nil
+func (d *driver) getVolumeInZone(
+	ctx types.Context,
+	volumeID, volumeName string,
+	avaiZone *string) ([]*awsec2.Volume, error) {
+
 	// prepare filters
 	filters := []*awsec2.Filter{}
 
-	if avaiZone := d.mustAvailabilityZone(ctx); avaiZone != nil {
+	if avaiZone != nil {
 		filters = append(filters, &awsec2.Filter{
 			Name:   aws.String("availability-zone"),
 			Values: []*string{avaiZone},
@@ -921,6 +1034,29 @@ func (d *driver) getVolume(
 	return resp.Volumes, nil
 }
 
+// checkAvailabilityZone returns an error if a volume that is not found in
+// the instance's availability zone exists in another of the region's zones
+func (d *driver) checkAvailabilityZone(
+	ctx types.Context, volumeID string) error {
+
+	avaiZone := d.mustAvailabilityZone(ctx)
+	if avaiZone == nil {
+		return nil
+	}
+	ec2vols, err := d.getVolumeInZone(ctx, volumeID, "", nil)
+	if err != nil {
+		return goof.WithError("error getting volume", err)
+	}
+	if len(ec2vols) == 0 || ec2vols[0].AvailabilityZone == nil {
+		return nil
+	}
+	if volZone := *ec2vols[0].AvailabilityZone; volZone != *avaiZone {
+		return apiUtils.NewAvailabilityZoneMismatchErr(
+			volumeID, volZone, *avaiZone)
+	}
+	return nil
+}
+
 var errGetLocDevs = goof.New("error getting local devices from context")
 
 // Converts EC2 API volumes to libStorage types.Volume
@@ -983,6 +1119,7 @@ func (d *driver) toTypesVolume(
 			Type:             *volume.VolumeType,
 			Size:             *volume.Size,
 			Attachments:      attachmentsSD,
+			Fields:           d.getLabels(volume.Tags),
 		}
 
 		// Some volume types have no IOPS, so we get nil in volume.Iops
@@ -1127,6 +1264,9 @@ func (d *driver) createVolume(
 		Encrypted:        opts.Encrypted,
 		VolumeType:       opts.Type,
 	}
+	if opts.Type == nil && d.volumeType != "" {
+		options.VolumeType = aws.String(d.volumeType)
+	}
 	if snapshotID != "" {
 		options.SnapshotId = &snapshotID
 	}
@@ -1153,7 +1293,9 @@ func (d *driver) createVolume(
 	}
 
 	// Add tags to created volume
-	if err = d.createTags(ctx, *resp.VolumeId, volumeName); err != nil {
+	if err = d.createTags(
+		ctx, *resp.VolumeId, volumeName,
+		apiUtils.Labels(opts.Opts)); err != nil {
 		return &awsec2.Volume{}, goof.WithError(
 			"error creating tags", err)
 	}
@@ -1168,6 +1310,11 @@ func (d *driver) createVolume(
 	return resp, nil
 }
 
+// PersistsLabels returns true since labels are persisted as EC2 tags.
+func (d *driver) PersistsLabels(ctx types.Context) bool {
+	return true
+}
+
 // Make sure Availability Zone is non-empty and valid
 func (d *driver) createVolumeEnsureAvailabilityZone(
 	opts *types.VolumeCreateOpts, server *awsec2.Instance) {
@@ -1178,7 +1325,10 @@ func (d *driver) createVolumeEnsureAvailabilityZone(
 }
 
 // Fill in tags for volume or snapshot
-func (d *driver) createTags(ctx types.Context, id, name string) (err error) {
+func (d *driver) createTags(
+	ctx types.Context,
+	id, name string,
+	labels map[string]string) (err error) {
 	var (
 		ctInput   *awsec2.CreateTagsInput
 		inputName string
@@ -1202,6 +1352,14 @@ func (d *driver) createTags(ctx types.Context, id, name string) (err error) {
 			Key:   aws.String("Name"),
 			Value: &inputName,
 		})
+	for k, v := range labels {
+		ctInput.Tags = append(
+			ctInput.Tags,
+			&awsec2.Tag{
+				Key:   aws.String(labelTagPrefix + k),
+				Value: aws.String(v),
+			})
+	}
 
 	// TODO rexrayTag
 	/*	if d.ec2Tag != "" {
@@ -1328,6 +1486,22 @@ func (d *driver) getName(tags []*awsec2.Tag) string {
 	return ""
 }
 
+// Get the labels persisted as tags
+func (d *driver) getLabels(tags []*awsec2.Tag) map[string]string {
+	var labels map[string]string
+	for _, tag := range tags {
+		if tag.Key == nil || tag.Value == nil ||
+			!strings.HasPrefix(*tag.Key, labelTagPrefix) {
+			continue
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[strings.TrimPrefix(*tag.Key, labelTagPrefix)] = *tag.Value
+	}
+	return labels
+}
+
 // Retrieve current instance using EC2 API call
 func (d *driver) getInstance(ctx types.Context) (awsec2.Instance, error) {
 	diInput := &awsec2.DescribeInstancesInput{
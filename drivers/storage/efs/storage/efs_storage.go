@@ -26,6 +26,7 @@ import (
 	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
 	apiUtils "github.com/codedellemc/libstorage/api/utils"
+	awsUtils "github.com/codedellemc/libstorage/drivers/storage/aws/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/efs"
 )
 
@@ -250,7 +251,8 @@ func (a *awsLogger) Log(args ...interface{}) {
 }
 
 func mustSession(ctx types.Context) *awsefs.EFS {
-	return context.MustSession(ctx).(*awsefs.EFS)
+	svc := context.MustSession(ctx).(*awsefs.EFS)
+	return &awsefs.EFS{Client: awsUtils.WithTxUserAgent(ctx, svc.Client)}
 }
 
 func mustInstanceIDID(ctx types.Context) *string {
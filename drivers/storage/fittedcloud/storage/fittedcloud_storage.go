@@ -27,6 +27,7 @@ import (
 	"github.com/codedellemc/libstorage/api/types"
 	apiUtils "github.com/codedellemc/libstorage/api/utils"
 
+	awsUtils "github.com/codedellemc/libstorage/drivers/storage/aws/utils"
 	"github.com/codedellemc/libstorage/drivers/storage/fittedcloud"
 	"github.com/codedellemc/libstorage/drivers/storage/fittedcloud/fcagent"
 	fcUtils "github.com/codedellemc/libstorage/drivers/storage/fittedcloud/utils"
@@ -209,7 +210,8 @@ func (d *driver) Login(ctx types.Context) (interface{}, error) {
 }
 
 func mustSession(ctx types.Context) *awsec2.EC2 {
-	return context.MustSession(ctx).(*awsec2.EC2)
+	svc := context.MustSession(ctx).(*awsec2.EC2)
+	return &awsec2.EC2{Client: awsUtils.WithTxUserAgent(ctx, svc.Client)}
 }
 
 func mustInstanceIDID(ctx types.Context) *string {
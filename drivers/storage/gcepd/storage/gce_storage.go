@@ -232,6 +232,13 @@ func (d *driver) Login(ctx types.Context) (interface{}, error) {
 	return svc, nil
 }
 
+// PersistsLabels returns true since labels are persisted in the description
+// of a disk. GCE labels are not used because their values cannot contain
+// characters such as those in an auth subject.
+func (d *driver) PersistsLabels(ctx types.Context) bool {
+	return true
+}
+
 // NextDeviceInfo returns the information about the driver's next available
 // device workflow.
 func (d *driver) NextDeviceInfo(
@@ -251,10 +258,34 @@ func (d *driver) InstanceInspect(
 
 	iid := context.MustInstanceID(ctx)
 	return &types.Instance{
-		InstanceID: iid,
+		InstanceID:       iid,
+		AvailabilityZone: iid.Fields[gcepd.InstanceIDFieldZone],
 	}, nil
 }
 
+// AvailabilityZones returns the zones in which the driver can provision
+// volumes.
+func (d *driver) AvailabilityZones(ctx types.Context) ([]string, error) {
+
+	// access may be restricted to a single zone
+	if d.zone != "" {
+		return []string{d.zone}, nil
+	}
+
+	zoneList, err := mustSession(ctx).Zones.List(*d.projectID).Do()
+	if err != nil {
+		return nil, goof.WithError("Unable to get zones from GCE API", err)
+	}
+
+	zones := []string{}
+	for _, z := range zoneList.Items {
+		if z.Status == "UP" {
+			zones = append(zones, z.Name)
+		}
+	}
+	return zones, nil
+}
+
 // Volumes returns all volumes or a filtered list of volumes.
 func (d *driver) Volumes(
 	ctx types.Context,
@@ -504,6 +535,9 @@ func (d *driver) VolumeAttach(
 		return nil, "", err
 	}
 	if gceDisk == nil {
+		if err := d.checkZone(ctx, zone, volumeID); err != nil {
+			return nil, "", err
+		}
 		return nil, "", apiUtils.NewNotFoundError(volumeID)
 	}
 
@@ -794,6 +828,7 @@ func (d *driver) toTypeVolume(
 			Status:           disk.Status,
 			Type:             utils.GetIndex(disk.Type),
 			Size:             disk.SizeGb,
+			Fields:           apiUtils.DecodeLabels(disk.Description),
 		}
 
 		if attachments.Requested() {
@@ -830,6 +865,29 @@ func (d *driver) validZone(ctx types.Context) (*string, error) {
 	return nil, nil
 }
 
+// checkZone returns an error if a disk that is not found in the provided
+// zone exists in another zone
+func (d *driver) checkZone(
+	ctx types.Context,
+	zone *string,
+	name string) error {
+
+	disks, err := d.getAggregatedDisks(ctx)
+	if err != nil {
+		return goof.WithError("Unable to get disks from GCE API", err)
+	}
+	for _, disk := range disks {
+		if disk.Name != name {
+			continue
+		}
+		if diskZone := utils.GetIndex(disk.Zone); diskZone != *zone {
+			return apiUtils.NewAvailabilityZoneMismatchErr(
+				name, diskZone, *zone)
+		}
+	}
+	return nil
+}
+
 func getAttachment(
 	disk *compute.Disk,
 	attachments types.VolumeAttachmentsTypes,
@@ -874,9 +932,10 @@ func (d *driver) createVolume(
 		*opts.AvailabilityZone, diskType)
 
 	createDisk := &compute.Disk{
-		Name:   *volumeName,
-		SizeGb: *opts.Size,
-		Type:   diskTypeURI,
+		Name:        *volumeName,
+		SizeGb:      *opts.Size,
+		Type:        diskTypeURI,
+		Description: apiUtils.EncodeLabels(apiUtils.Labels(opts.Opts)),
 	}
 
 	asyncOp, err := mustSession(ctx).Disks.Insert(
@@ -0,0 +1,404 @@
+package libstorage
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/akutz/goof"
+
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+// diskCacheFileName is the name of the file in the libStorage run directory
+// in which the client persists its caches.
+const diskCacheFileName = "client.cache"
+
+var (
+	// diskCacheLockWait is the interval at which a client tries to acquire
+	// the lock of the disk cache.
+	diskCacheLockWait = time.Duration(50) * time.Millisecond
+
+	// diskCacheLockTimeout is how long a lock of the disk cache may be held
+	// before the lock is considered stale, ex. because the process that
+	// held it exited, and is removed.
+	diskCacheLockTimeout = time.Duration(10) * time.Second
+)
+
+// diskCacheEntry is an entry of the disk cache.
+type diskCacheEntry struct {
+
+	// Expires is the time at which the entry expires. An entry without an
+	// expiration time is valid until the cache is invalidated.
+	Expires time.Time `json:"expires"`
+
+	// Value is the JSON representation of the cached value.
+	Value json.RawMessage `json:"value"`
+}
+
+func (e *diskCacheEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// diskCacheEntries are the entries of one of the disk cache's sections.
+type diskCacheEntries map[string]*diskCacheEntry
+
+// diskCacheData is the contents of the disk cache. The entries are only
+// valid for the server and the executor with which they were cached.
+type diskCacheData struct {
+	ServerName  string           `json:"serverName"`
+	LSXChecksum string           `json:"lsxChecksum"`
+	Services    diskCacheEntries `json:"services,omitempty"`
+	Supported   diskCacheEntries `json:"supported,omitempty"`
+	InstanceIDs diskCacheEntries `json:"instanceIDs,omitempty"`
+}
+
+// diskCache persists the client's service info, supported flags and
+// instance IDs so that short-lived processes do not need to invoke the
+// executor every time they dial the server. The cache is a file that is
+// shared by the processes on a host, so the file is only read and written
+// while holding the cache's lock.
+type diskCache struct {
+	path          string
+	lockPath      string
+	supportedTTL  time.Duration
+	instanceIDTTL time.Duration
+
+	// loaded is the data read from the cache when the client dialed the
+	// server.
+	loaded *diskCacheData
+}
+
+func newDiskCache(
+	runDir string, supportedTTL, instanceIDTTL time.Duration) *diskCache {
+
+	filePath := path.Join(runDir, diskCacheFileName)
+	return &diskCache{
+		path:          filePath,
+		lockPath:      filePath + ".lock",
+		supportedTTL:  supportedTTL,
+		instanceIDTTL: instanceIDTTL,
+	}
+}
+
+// load seeds the client's caches with the entries of the disk cache. The
+// entries are discarded if they were cached for another server or another
+// executor, and the supported flags and instance ID of a service are
+// discarded if the service's driver changed.
+func (d *diskCache) load(
+	ctx types.Context,
+	c *client,
+	lsxChecksum string,
+	svcInfos map[string]*types.ServiceInfo) error {
+
+	data, err := d.readLocked(ctx)
+	if err != nil {
+		return err
+	}
+
+	serverName := c.ServerName()
+	if data == nil ||
+		data.ServerName != serverName ||
+		data.LSXChecksum != lsxChecksum {
+
+		if data != nil {
+			ctx.WithFields(log.Fields{
+				"cachedServerName":  data.ServerName,
+				"cachedLSXChecksum": data.LSXChecksum,
+				"lsxChecksum":       lsxChecksum,
+			}).Debug("invalidated disk cache")
+		}
+		d.loaded = nil
+		return nil
+	}
+
+	now := time.Now()
+	d.loaded = data
+
+	drivers := map[string]bool{}
+	for service, si := range svcInfos {
+		cached := &types.ServiceInfo{}
+		e, ok := data.Services[service]
+		if !ok || e.expired(now) || json.Unmarshal(e.Value, cached) != nil {
+			continue
+		}
+		if cached.Driver.Name != si.Driver.Name {
+			ctx.WithField("service", service).Debug(
+				"service driver changed; ignoring cached instance ID")
+			continue
+		}
+		drivers[strings.ToLower(si.Driver.Name)] = true
+
+		e, ok = data.InstanceIDs[service]
+		if !ok || e.expired(now) || d.instanceIDTTL == cacheTTLDisabled {
+			continue
+		}
+		iid := &types.InstanceID{}
+		if err := json.Unmarshal(e.Value, iid); err != nil {
+			continue
+		}
+		c.instanceIDCache.Set(service, iid)
+	}
+
+	for driverName := range drivers {
+		e, ok := data.Supported[driverName]
+		if !ok || e.expired(now) || d.supportedTTL == cacheTTLDisabled {
+			continue
+		}
+		var lsxSO types.LSXSupportedOp
+		if err := json.Unmarshal(e.Value, &lsxSO); err != nil {
+			continue
+		}
+		c.supportedCache.Set(driverName, lsxSO)
+	}
+
+	ctx.WithFields(log.Fields{
+		"path":        d.path,
+		"supported":   len(c.supportedCache.Keys()),
+		"instanceIDs": len(c.instanceIDCache.Keys()),
+	}).Debug("loaded disk cache")
+
+	return nil
+}
+
+// save persists the client's caches. The entries that other processes
+// cached for the same server and executor since the client loaded the
+// cache are retained.
+func (d *diskCache) save(
+	ctx types.Context,
+	c *client,
+	lsxChecksum string,
+	svcInfos map[string]*types.ServiceInfo) error {
+
+	if err := d.lock(); err != nil {
+		return err
+	}
+	defer d.unlock(ctx)
+
+	data, err := d.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	serverName := c.ServerName()
+	if data == nil ||
+		data.ServerName != serverName ||
+		data.LSXChecksum != lsxChecksum {
+
+		data = &diskCacheData{
+			ServerName:  serverName,
+			LSXChecksum: lsxChecksum,
+		}
+	}
+	data.Services = pruneDiskCacheEntries(data.Services, now)
+	data.Supported = pruneDiskCacheEntries(data.Supported, now)
+	data.InstanceIDs = pruneDiskCacheEntries(data.InstanceIDs, now)
+
+	var loaded diskCacheData
+	if d.loaded != nil {
+		loaded = *d.loaded
+	}
+
+	for service, si := range svcInfos {
+		if err := setDiskCacheEntry(
+			data.Services, nil, service, si, now, 0); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range c.supportedCache.Keys() {
+		if d.supportedTTL == cacheTTLDisabled {
+			break
+		}
+		if err := setDiskCacheEntry(
+			data.Supported, loaded.Supported, k,
+			c.supportedCache.GetLSXSupported(k),
+			now, d.supportedTTL); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range c.instanceIDCache.Keys() {
+		iid := c.instanceIDCache.GetInstanceID(k)
+		if iid == nil || d.instanceIDTTL == cacheTTLDisabled {
+			continue
+		}
+		if err := setDiskCacheEntry(
+			data.InstanceIDs, loaded.InstanceIDs, k, iid,
+			now, d.instanceIDTTL); err != nil {
+			return err
+		}
+	}
+
+	if err := d.write(data); err != nil {
+		return err
+	}
+
+	d.loaded = data
+	ctx.WithField("path", d.path).Debug("saved disk cache")
+	return nil
+}
+
+// clear removes the disk cache.
+func (d *diskCache) clear(ctx types.Context) error {
+	if err := d.lock(); err != nil {
+		return err
+	}
+	defer d.unlock(ctx)
+
+	d.loaded = nil
+	if err := os.Remove(d.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// readLocked reads the disk cache while holding the cache's lock.
+func (d *diskCache) readLocked(ctx types.Context) (*diskCacheData, error) {
+	if err := d.lock(); err != nil {
+		return nil, err
+	}
+	defer d.unlock(ctx)
+	return d.read(ctx)
+}
+
+// read reads the disk cache. A cache that does not exist or cannot be
+// decoded is treated as empty.
+func (d *diskCache) read(ctx types.Context) (*diskCacheData, error) {
+
+	buf, err := ioutil.ReadFile(d.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	data := &diskCacheData{}
+	if err := json.Unmarshal(buf, data); err != nil {
+		ctx.WithError(err).WithField("path", d.path).Warn(
+			"ignoring invalid disk cache")
+		return nil, nil
+	}
+	return data, nil
+}
+
+// write writes the disk cache to a temporary file that then replaces the
+// cache, so the cache is never read while partially written.
+func (d *diskCache) write(data *diskCacheData) error {
+
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(path.Dir(d.path), diskCacheFileName+".")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := os.Rename(f.Name(), d.path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// lock acquires the lock of the disk cache. A lock that was held longer
+// than the lock timeout is removed.
+func (d *diskCache) lock() error {
+
+	for start := time.Now(); ; {
+
+		f, err := os.OpenFile(d.lockPath, os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			return f.Close()
+		}
+		if !os.IsExist(err) {
+			return goof.WithFieldE(
+				"path", d.lockPath, "error locking disk cache", err)
+		}
+
+		if fi, err := os.Stat(d.lockPath); err == nil &&
+			time.Since(fi.ModTime()) > diskCacheLockTimeout {
+			os.Remove(d.lockPath)
+			continue
+		}
+
+		if time.Since(start) > diskCacheLockTimeout*2 {
+			return goof.WithField(
+				"path", d.lockPath, "timed out locking disk cache")
+		}
+
+		time.Sleep(diskCacheLockWait)
+	}
+}
+
+func (d *diskCache) unlock(ctx types.Context) {
+	if err := os.Remove(d.lockPath); err != nil {
+		ctx.WithError(err).WithField("path", d.lockPath).Warn(
+			"error unlocking disk cache")
+	}
+}
+
+// pruneDiskCacheEntries returns the entries that have not expired.
+func pruneDiskCacheEntries(
+	entries diskCacheEntries, now time.Time) diskCacheEntries {
+
+	pruned := diskCacheEntries{}
+	for k, e := range entries {
+		if e != nil && !e.expired(now) {
+			pruned[k] = e
+		}
+	}
+	return pruned
+}
+
+// setDiskCacheEntry caches a value. A value that is unchanged since it was
+// loaded from the disk cache keeps its expiration time so that reloading an
+// entry does not extend the entry's lifetime.
+func setDiskCacheEntry(
+	entries, loaded diskCacheEntries,
+	key string,
+	value interface{},
+	now time.Time,
+	ttl time.Duration) error {
+
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if e, ok := loaded[key]; ok && bytes.Equal(e.Value, buf) {
+		entries[key] = e
+		return nil
+	}
+
+	e := &diskCacheEntry{Value: buf}
+	if ttl > 0 {
+		e.Expires = now.Add(ttl)
+	}
+	entries[key] = e
+	return nil
+}
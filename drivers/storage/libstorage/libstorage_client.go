@@ -2,17 +2,19 @@ package libstorage
 
 import (
 	"crypto/md5"
+	"crypto/sha256"
 	"errors"
 	"fmt"
-	"io"
+	"io/ioutil"
 	"os"
 	"path"
-
-	log "github.com/Sirupsen/logrus"
+	"runtime"
+	"sort"
 
 	gofig "github.com/akutz/gofig/types"
 	"github.com/akutz/goof"
 	"github.com/akutz/gotil"
+	"golang.org/x/crypto/ed25519"
 
 	"github.com/codedellemc/libstorage/api/context"
 	"github.com/codedellemc/libstorage/api/types"
@@ -23,13 +25,14 @@ type client struct {
 	types.APIClient
 	ctx             types.Context
 	config          gofig.Config
-	tlsConfig       *types.TLSConfig
 	pathConfig      *types.PathConfig
 	clientType      types.ClientType
-	lsxCache        *lss
 	serviceCache    *lss
 	supportedCache  *lss
 	instanceIDCache types.Store
+	diskCache       *diskCache
+	lsxDaemons      *lsxDaemons
+	trustedKeys     []ed25519.PublicKey
 	lsxMutexPath    string
 }
 
@@ -56,16 +59,19 @@ func (c *client) dial(ctx types.Context) error {
 
 	if !c.config.GetBool(types.ConfigExecutorNoDownload) {
 
-		ctx.Info("initializing executors cache")
-		if _, err := c.Executors(ctx); err != nil {
+		ctx.Info("initializing executor")
+		lsxInfos, err := c.Executors(ctx)
+		if err != nil {
 			return err
 		}
 
-		if err := c.updateExecutor(ctx); err != nil {
+		if err := c.updateExecutor(ctx, lsxInfos); err != nil {
 			return err
 		}
 	}
 
+	lsxChecksum, useDisk := c.loadDiskCache(ctx, svcInfos)
+
 	for service := range svcInfos {
 		ctx := c.ctx.WithValue(context.ServiceKey, service)
 		ctx.Info("initializing supported cache")
@@ -89,9 +95,41 @@ func (c *client) dial(ctx types.Context) error {
 		}
 	}
 
+	if useDisk {
+		if err := c.diskCache.save(ctx, c, lsxChecksum, svcInfos); err != nil {
+			ctx.WithError(err).Warn("error saving disk cache")
+		}
+	}
+
 	return nil
 }
 
+// loadDiskCache seeds the client's caches from the disk cache, if enabled.
+// The entries of the disk cache are only valid for the executor with the
+// returned checksum. A disk cache that cannot be used is ignored.
+func (c *client) loadDiskCache(
+	ctx types.Context,
+	svcInfos map[string]*types.ServiceInfo) (string, bool) {
+
+	if c.diskCache == nil {
+		return "", false
+	}
+
+	ctx = ctx.WithField("path", c.diskCache.path)
+
+	lsxChecksum, err := c.getExecutorChecksum(ctx)
+	if err != nil {
+		ctx.WithError(err).Warn("not using disk cache")
+		return "", false
+	}
+
+	if err := c.diskCache.load(ctx, c, lsxChecksum, svcInfos); err != nil {
+		ctx.WithError(err).Warn("error loading disk cache")
+	}
+
+	return lsxChecksum, true
+}
+
 func getHost(
 	ctx types.Context,
 	proto, lAddr string, tlsConfig *types.TLSConfig) string {
@@ -119,7 +157,8 @@ func (c *client) getServiceInfo(service string) (*types.ServiceInfo, error) {
 	return nil, goof.WithField("name", service, "unknown service")
 }
 
-func (c *client) updateExecutor(ctx types.Context) error {
+func (c *client) updateExecutor(
+	ctx types.Context, lsxInfos map[string]*types.ExecutorInfo) error {
 
 	if c.isController() {
 		return utils.NewUnsupportedForClientTypeError(
@@ -128,9 +167,21 @@ func (c *client) updateExecutor(ctx types.Context) error {
 
 	ctx.Debug("updating executor")
 
-	lsxi := c.lsxCache.GetExecutorInfo(path.Base(c.pathConfig.LSX))
-	if lsxi == nil {
-		return goof.WithField("lsx", c.pathConfig.LSX, "unknown executor")
+	// servers may report different checksums for the same executor, so
+	// the executor's info is inspected and the executor is downloaded as
+	// part of one transaction, which pins the requests to the same server
+	ctx = context.RequireTX(ctx)
+
+	lsxName, err := executorName(lsxInfos, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	ctx = ctx.WithField("lsxName", lsxName)
+
+	lsxi, err := c.ExecutorHead(ctx, lsxName)
+	if err != nil {
+		return goof.WithFieldE(
+			"lsx", c.pathConfig.LSX, "error inspecting executor", err)
 	}
 
 	ctx.Debug("waiting on executor lock")
@@ -146,27 +197,64 @@ func (c *client) updateExecutor(ctx types.Context) error {
 
 	if !gotil.FileExists(c.pathConfig.LSX) {
 		ctx.Debug("executor does not exist, download executor")
-		return c.downloadExecutor(ctx)
+		return c.downloadExecutor(ctx, lsxi)
 	}
 
-	ctx.Debug("executor exists, getting local checksum")
+	ctx.Debug("executor exists, verifying local executor")
 
-	checksum, err := c.getExecutorChecksum(ctx)
+	buf, err := ioutil.ReadFile(c.pathConfig.LSX)
 	if err != nil {
 		return err
 	}
 
-	if lsxi.MD5Checksum != checksum {
-		ctx.WithFields(log.Fields{
-			"remoteChecksum": lsxi.MD5Checksum,
-			"localChecksum":  checksum,
-		}).Debug("executor checksums do not match, download executor")
-		return c.downloadExecutor(ctx)
+	if err := c.verifyExecutor(ctx, lsxi, buf); err != nil {
+		ctx.WithError(err).Debug(
+			"local executor not verified, download executor")
+		return c.downloadExecutor(ctx, lsxi)
 	}
 
 	return nil
 }
 
+// verifyExecutor verifies the provided executor data matches the executor
+// info returned by the server. The SHA-256 checksum is compared if the
+// server provides it, otherwise the MD5 checksum. The executor's signature
+// is verified if the client has trusted keys.
+func (c *client) verifyExecutor(
+	ctx types.Context, lsxi *types.ExecutorInfo, data []byte) error {
+
+	remoteChecksum := lsxi.SHA256Checksum
+	localChecksum := fmt.Sprintf("%x", sha256.Sum256(data))
+	if remoteChecksum == "" {
+		remoteChecksum = lsxi.MD5Checksum
+		localChecksum = fmt.Sprintf("%x", md5.Sum(data))
+	}
+
+	if remoteChecksum != localChecksum {
+		return goof.WithFields(goof.Fields{
+			"remoteChecksum": remoteChecksum,
+			"localChecksum":  localChecksum,
+		}, "executor checksums do not match")
+	}
+
+	if len(c.trustedKeys) == 0 {
+		return nil
+	}
+
+	// the signature is verified against the platform of the client rather
+	// than the platform the server reports
+	manifest := utils.NewExecutorManifest(
+		lsxi.Name, runtime.GOOS, runtime.GOARCH, data)
+	if err := utils.VerifySignature(
+		c.trustedKeys, manifest, lsxi.Signature); err != nil {
+		return goof.WithFieldE(
+			"lsx", lsxi.Name, "error verifying executor signature", err)
+	}
+
+	ctx.Debug("verified executor signature")
+	return nil
+}
+
 func (c *client) getExecutorChecksum(ctx types.Context) (string, error) {
 
 	if c.isController() {
@@ -176,33 +264,17 @@ func (c *client) getExecutorChecksum(ctx types.Context) (string, error) {
 
 	ctx.Debug("getting executor checksum")
 
-	f, err := os.Open(c.pathConfig.LSX)
+	sum, err := fileChecksum(c.pathConfig.LSX)
 	if err != nil {
 		return "", err
 	}
-	defer f.Close()
 
-	h := md5.New()
-	buf := make([]byte, 1024)
-	for {
-		n, err := f.Read(buf)
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return "", err
-		}
-		if _, err := h.Write(buf[:n]); err != nil {
-			return "", err
-		}
-	}
-
-	sum := fmt.Sprintf("%x", h.Sum(nil))
 	ctx.WithField("localChecksum", sum).Debug("got local executor checksum")
 	return sum, nil
 }
 
-func (c *client) downloadExecutor(ctx types.Context) error {
+func (c *client) downloadExecutor(
+	ctx types.Context, lsxi *types.ExecutorInfo) error {
 
 	if c.isController() {
 		return utils.NewUnsupportedForClientTypeError(
@@ -211,30 +283,83 @@ func (c *client) downloadExecutor(ctx types.Context) error {
 
 	ctx.Debug("downloading executor")
 
-	f, err := os.OpenFile(
-		c.pathConfig.LSX,
-		os.O_CREATE|os.O_RDWR|os.O_TRUNC,
-		0755)
+	rdr, err := c.APIClient.ExecutorGet(ctx, lsxi.Name)
 	if err != nil {
 		return err
 	}
+	defer rdr.Close()
 
-	defer f.Close()
-
-	rdr, err := c.APIClient.ExecutorGet(ctx, path.Base(c.pathConfig.LSX))
+	buf, err := ioutil.ReadAll(rdr)
 	if err != nil {
 		return err
 	}
 
-	n, err := io.Copy(f, rdr)
+	ctx.WithField("bytes", len(buf)).Debug("downloaded executor")
+
+	// the executor is verified before it is installed, so a client never
+	// executes an executor that was corrupted or not signed by a trusted key
+	if err := c.verifyExecutor(ctx, lsxi, buf); err != nil {
+		return goof.WithError("error verifying downloaded executor", err)
+	}
+
+	// the executor is written to a temporary file that replaces the
+	// executor, so executor daemons that are running the executor are not
+	// affected and the executor is never partially written
+	f, err := ioutil.TempFile(
+		path.Dir(c.pathConfig.LSX), path.Base(c.pathConfig.LSX)+".")
 	if err != nil {
 		return err
 	}
 
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	if _, err := f.Write(buf); err != nil {
+		return err
+	}
+
 	if err := f.Sync(); err != nil {
 		return err
 	}
 
-	ctx.WithField("bytes", n).Debug("downloaded executor")
-	return nil
+	if err := f.Chmod(0755); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), c.pathConfig.LSX)
+}
+
+// executorName returns the name of the server's executor for the provided
+// operating system and architecture. The platform of an executor is parsed
+// from its name if the server does not provide it.
+func executorName(
+	lsxInfos map[string]*types.ExecutorInfo,
+	goos, goarch string) (string, error) {
+
+	if _, ok := lsxInfos[utils.ExecutorName(goos, goarch)]; ok {
+		return utils.ExecutorName(goos, goarch), nil
+	}
+
+	names := make([]string, 0, len(lsxInfos))
+	for name := range lsxInfos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		lsxOS, lsxArch := lsxInfos[name].OS, lsxInfos[name].Arch
+		if lsxOS == "" {
+			lsxOS, lsxArch, _ = utils.ParseExecutorName(name)
+		}
+		if lsxOS == goos && lsxArch == goarch {
+			return name, nil
+		}
+	}
+
+	return "", goof.WithFields(goof.Fields{
+		"os":   goos,
+		"arch": goarch,
+	}, "no executor for platform")
 }
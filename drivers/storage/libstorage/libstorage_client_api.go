@@ -64,6 +64,22 @@ func (c *client) ServiceInspect(
 	return c.APIClient.ServiceInspect(ctx, service)
 }
 
+func (c *client) StorageClasses(
+	ctx types.Context, service string) (types.StorageClassMap, error) {
+
+	return c.APIClient.StorageClasses(c.requireCtx(ctx), service)
+}
+
+func (c *client) Quotas(ctx types.Context) (*types.QuotaReport, error) {
+	return c.APIClient.Quotas(c.requireCtx(ctx))
+}
+
+func (c *client) SubjectQuota(
+	ctx types.Context, subject string) (*types.SubjectQuota, error) {
+
+	return c.APIClient.SubjectQuota(c.requireCtx(ctx), subject)
+}
+
 func (c *client) Volumes(
 	ctx types.Context,
 	attachments types.VolumeAttachmentsTypes) (types.ServiceVolumeMap, error) {
@@ -369,23 +385,207 @@ func (c *client) SnapshotCopy(
 	return c.APIClient.SnapshotCopy(ctx, service, snapshotID, request)
 }
 
-func (c *client) Executors(
-	ctx types.Context) (map[string]*types.ExecutorInfo, error) {
+// The async methods invoke the Before functions of the client drivers, but
+// not the After functions since the operations are completed by the server
+// after the async methods return.
+
+func (c *client) VolumeCreateAsync(
+	ctx types.Context,
+	service string,
+	request *types.VolumeCreateRequest) (*types.Task, error) {
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+	ctxA, err := c.withAllLocalDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = ctxA
+
+	lsd, _ := registry.NewClientDriver(service)
+	if lsd != nil {
+		if err := lsd.Init(ctx, c.config); err != nil {
+			return nil, err
+		}
+
+		if err := lsd.VolumeCreateBefore(
+			&ctx, service, request); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.APIClient.VolumeCreateAsync(ctx, service, request)
+}
+
+func (c *client) VolumeCreateFromSnapshotAsync(
+	ctx types.Context,
+	service, snapshotID string,
+	request *types.VolumeCreateRequest) (*types.Task, error) {
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+
+	lsd, _ := registry.NewClientDriver(service)
+	if lsd != nil {
+		if err := lsd.Init(ctx, c.config); err != nil {
+			return nil, err
+		}
+
+		if err := lsd.VolumeCreateFromSnapshotBefore(
+			&ctx, service, snapshotID, request); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.APIClient.VolumeCreateFromSnapshotAsync(
+		ctx, service, snapshotID, request)
+}
+
+func (c *client) VolumeCopyAsync(
+	ctx types.Context,
+	service, volumeID string,
+	request *types.VolumeCopyRequest) (*types.Task, error) {
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+
+	lsd, _ := registry.NewClientDriver(service)
+	if lsd != nil {
+		if err := lsd.Init(ctx, c.config); err != nil {
+			return nil, err
+		}
+
+		if err := lsd.VolumeCopyBefore(
+			&ctx, service, volumeID, request); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.APIClient.VolumeCopyAsync(ctx, service, volumeID, request)
+}
+
+func (c *client) VolumeRemoveAsync(
+	ctx types.Context,
+	service, volumeID string,
+	force bool) (*types.Task, error) {
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+
+	lsd, _ := registry.NewClientDriver(service)
+	if lsd != nil {
+		if err := lsd.Init(ctx, c.config); err != nil {
+			return nil, err
+		}
+
+		if err := lsd.VolumeRemoveBefore(
+			&ctx, service, volumeID); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.APIClient.VolumeRemoveAsync(ctx, service, volumeID, force)
+}
+
+func (c *client) VolumeAttachAsync(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeAttachRequest) (*types.Task, error) {
 
 	if c.isController() {
 		return nil, utils.NewUnsupportedForClientTypeError(
-			c.clientType, "Executors")
+			c.clientType, "VolumeAttachAsync")
 	}
 
-	ctx = c.requireCtx(ctx)
-	lsxInfo, err := c.APIClient.Executors(ctx)
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+	ctxA, err := c.withAllLocalDevices(ctx)
 	if err != nil {
 		return nil, err
 	}
-	for k, v := range lsxInfo {
-		c.lsxCache.Set(k, v)
+	ctx = ctxA
+
+	return c.APIClient.VolumeAttachAsync(ctx, service, volumeID, request)
+}
+
+func (c *client) VolumeDetachAsync(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeDetachRequest) (*types.Task, error) {
+
+	if c.isController() {
+		return nil, utils.NewUnsupportedForClientTypeError(
+			c.clientType, "VolumeDetachAsync")
 	}
-	return lsxInfo, nil
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+	ctxA, err := c.withAllLocalDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = ctxA
+
+	return c.APIClient.VolumeDetachAsync(ctx, service, volumeID, request)
+}
+
+func (c *client) VolumeSnapshotAsync(
+	ctx types.Context,
+	service string,
+	volumeID string,
+	request *types.VolumeSnapshotRequest) (*types.Task, error) {
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+	return c.APIClient.VolumeSnapshotAsync(ctx, service, volumeID, request)
+}
+
+func (c *client) SnapshotRemoveAsync(
+	ctx types.Context,
+	service, snapshotID string) (*types.Task, error) {
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+	return c.APIClient.SnapshotRemoveAsync(ctx, service, snapshotID)
+}
+
+func (c *client) SnapshotCopyAsync(
+	ctx types.Context,
+	service, snapshotID string,
+	request *types.SnapshotCopyRequest) (*types.Task, error) {
+
+	ctx = c.withInstanceID(c.requireCtx(ctx), service)
+	return c.APIClient.SnapshotCopyAsync(ctx, service, snapshotID, request)
+}
+
+func (c *client) Tasks(
+	ctx types.Context) (map[string]*types.Task, error) {
+
+	return c.APIClient.Tasks(c.requireCtx(ctx))
+}
+
+func (c *client) TaskInspect(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	return c.APIClient.TaskInspect(c.requireCtx(ctx), taskID)
+}
+
+func (c *client) TaskCancel(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	return c.APIClient.TaskCancel(c.requireCtx(ctx), taskID)
+}
+
+func (c *client) TaskWait(
+	ctx types.Context, taskID int) (*types.Task, error) {
+
+	return c.APIClient.TaskWait(c.requireCtx(ctx), taskID)
+}
+
+func (c *client) Executors(
+	ctx types.Context) (map[string]*types.ExecutorInfo, error) {
+
+	if c.isController() {
+		return nil, utils.NewUnsupportedForClientTypeError(
+			c.clientType, "Executors")
+	}
+
+	ctx = c.requireCtx(ctx)
+	return c.APIClient.Executors(ctx)
 }
 
 func (c *client) ExecutorHead(
@@ -0,0 +1,440 @@
+package libstorage
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akutz/goof"
+	gocontext "golang.org/x/net/context"
+
+	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/types"
+)
+
+var (
+	// lsxDaemonStartTimeout is how long the client waits for an executor
+	// daemon to become ready.
+	lsxDaemonStartTimeout = time.Duration(30) * time.Second
+
+	// lsxDaemonMaxFailures is the number of times in a row an executor
+	// daemon may fail to start or exit unexpectedly before the client stops
+	// using the daemon and invokes the executor for every command.
+	lsxDaemonMaxFailures = 3
+
+	errLSXDaemonDisabled = goof.New("executor daemon disabled")
+)
+
+// lsxReadOnlyCmds are the executor commands that do not modify the host.
+// Such a command is executed by invoking the executor if the daemon exits
+// while executing the command.
+var lsxReadOnlyCmds = map[string]bool{
+	strings.ToLower(types.LSXCmdSupported):     true,
+	strings.ToLower(types.LSXCmdInstanceID):    true,
+	strings.ToLower(types.LSXCmdNextDevice):    true,
+	strings.ToLower(types.LSXCmdLocalDevices):  true,
+	strings.ToLower(types.LSXCmdWaitForDevice): true,
+	strings.ToLower(types.LSXCmdMounts):        true,
+}
+
+// lsxDaemons are the executor daemons used by a client, one per driver.
+type lsxDaemons struct {
+	sync.Mutex
+	daemons map[string]*lsxDaemon
+}
+
+func newLSXDaemons() *lsxDaemons {
+	return &lsxDaemons{daemons: map[string]*lsxDaemon{}}
+}
+
+// lsxDaemon executes a driver's executor commands with an executor that runs
+// as a daemon. The client connects to the daemon's UNIX socket if a daemon
+// is listening on the socket, otherwise the client starts the executor with
+// the serve command and sends the requests to the executor's stdin. A daemon
+// that exits is started again when the next command is executed. The client
+// only uses a daemon listening on the socket if the daemon runs the same
+// executor as the client.
+type lsxDaemon struct {
+	sync.Mutex
+	ctx        types.Context
+	driverName string
+	lsxPath    string
+	sockPath   string
+	env        []string
+
+	conn   io.ReadWriteCloser
+	rdr    *bufio.Reader
+	cmd    *exec.Cmd
+	lsxMod time.Time
+	nextID int
+
+	failures int
+	disabled bool
+}
+
+// lsxPipe is the connection to an executor daemon started by the client.
+type lsxPipe struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// runExecutorDaemon executes a command with the driver's executor daemon.
+// The returned flag is false if the command could not be executed by the
+// daemon and should be executed by invoking the executor instead.
+func (c *client) runExecutorDaemon(
+	ctx types.Context, args ...string) ([]byte, bool, error) {
+
+	if c.lsxDaemons == nil || len(args) < 2 {
+		return nil, false, nil
+	}
+
+	driverName := strings.ToLower(args[0])
+
+	c.lsxDaemons.Lock()
+	d, ok := c.lsxDaemons.daemons[driverName]
+	if !ok {
+		d = &lsxDaemon{
+			ctx:        c.ctx.WithField("lsxDaemon", driverName),
+			driverName: driverName,
+			lsxPath:    c.pathConfig.LSX,
+			sockPath: path.Join(
+				c.pathConfig.Run, "lsx-"+driverName+".sock"),
+			env: append(os.Environ(), c.config.EnvVars()...),
+		}
+		c.lsxDaemons.daemons[driverName] = d
+	}
+	c.lsxDaemons.Unlock()
+
+	ctx = ctx.WithField("lsxDaemon", driverName)
+
+	res, sent, err := d.run(ctx, args[1:])
+	if err != nil {
+		if err == errLSXDaemonDisabled {
+			return nil, false, nil
+		}
+		if ctx.Err() != nil {
+			return nil, true, err
+		}
+		if !sent || lsxReadOnlyCmds[strings.ToLower(args[1])] {
+			ctx.WithError(err).WithField("args", args).Warn(
+				"error executing command with executor daemon")
+			return nil, false, nil
+		}
+		return nil, true, goof.WithFieldE(
+			"args", args, "executor daemon exited", err)
+	}
+
+	if res.ExitCode != 0 {
+		return nil, true, newExecutorError(
+			ctx, d.lsxPath, args, res.ExitCode, res.Stderr,
+			goof.WithField("exitCode", res.ExitCode, "executor failed"))
+	}
+
+	ctx.WithField("args", args).Debug(
+		"executed command with executor daemon")
+	return res.Stdout, true, nil
+}
+
+// run executes a command with the daemon, starting the daemon if it is not
+// running or if the executor was replaced since the daemon was started. The
+// returned flag indicates whether or not the request was sent to the daemon.
+func (d *lsxDaemon) run(
+	ctx types.Context, args []string) (*types.LSXResponse, bool, error) {
+
+	d.Lock()
+	defer d.Unlock()
+
+	if d.disabled {
+		return nil, false, errLSXDaemonDisabled
+	}
+
+	// a daemon the client started is restarted, and the client reconnects
+	// to a daemon listening on the socket in order to compare the checksums
+	// of the daemon's executor and the new executor
+	if d.conn != nil && d.replaced() {
+		ctx.Debug("executor replaced; restarting executor daemon")
+		d.stop()
+	}
+
+	started := false
+	if d.conn == nil {
+		if err := d.start(ctx); err != nil {
+			d.failed(ctx, err)
+			return nil, false, err
+		}
+		started = true
+	}
+
+	d.nextID++
+	req := &types.LSXRequest{ID: d.nextID, Args: args}
+	req.Transaction, _ = context.Transaction(ctx)
+
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return nil, false, err
+	}
+	buf = append(buf, '\n')
+
+	// a daemon that exited while idle is started again
+	_, err = d.conn.Write(buf)
+	if err != nil && !started {
+		ctx.WithError(err).Debug("restarting executor daemon")
+		d.stop()
+		if err = d.start(ctx); err == nil {
+			_, err = d.conn.Write(buf)
+		}
+	}
+	if err != nil {
+		d.failed(ctx, err)
+		return nil, false, err
+	}
+
+	res, err := d.read(ctx)
+	if err != nil {
+		// a cancelled command is not a failure of the daemon
+		if ctx.Err() != nil {
+			d.stop()
+			return nil, true, err
+		}
+		d.failed(ctx, err)
+		return nil, true, err
+	}
+	if res.ID != req.ID {
+		err := goof.WithFields(goof.Fields{
+			"requestID":  req.ID,
+			"responseID": res.ID,
+		}, "executor daemon response out of order")
+		d.failed(ctx, err)
+		return nil, true, err
+	}
+
+	d.failures = 0
+	return res, true, nil
+}
+
+// start connects to the daemon listening on the driver's socket or starts
+// a daemon, and waits for the daemon to be ready.
+func (d *lsxDaemon) start(ctx types.Context) error {
+
+	if conn, err := net.Dial("unix", d.sockPath); err == nil {
+		d.conn = conn
+		d.rdr = bufio.NewReader(conn)
+		err := d.connect(ctx)
+		if err == nil {
+			ctx.WithField("socket", d.sockPath).Debug(
+				"connected to executor daemon")
+			return nil
+		}
+		ctx.WithError(err).WithField("socket", d.sockPath).Debug(
+			"not using executor daemon on socket")
+		d.stop()
+	}
+
+	if err := d.exec(ctx); err != nil {
+		return err
+	}
+	d.rdr = bufio.NewReader(d.conn)
+
+	if _, err := d.ready(ctx); err != nil {
+		d.stop()
+		return goof.WithError("error starting executor daemon", err)
+	}
+
+	ctx.Debug("executor daemon ready")
+	return nil
+}
+
+// connect waits for the daemon listening on the socket to be ready and
+// verifies that the daemon runs the same executor as the client.
+func (d *lsxDaemon) connect(ctx types.Context) error {
+
+	fi, err := os.Stat(d.lsxPath)
+	if err != nil {
+		return err
+	}
+	sum, err := fileChecksum(d.lsxPath)
+	if err != nil {
+		return err
+	}
+
+	res, err := d.ready(ctx)
+	if err != nil {
+		return err
+	}
+	if res.Checksum != sum {
+		return goof.WithFields(goof.Fields{
+			"localChecksum":  sum,
+			"daemonChecksum": res.Checksum,
+		}, "executor daemon runs a different executor")
+	}
+
+	d.lsxMod = fi.ModTime()
+	return nil
+}
+
+// ready waits for the daemon to indicate it is ready.
+func (d *lsxDaemon) ready(ctx types.Context) (*types.LSXResponse, error) {
+
+	// a daemon that does not become ready in time is stopped, which
+	// unblocks the read
+	readyCtx, cancel := gocontext.WithTimeout(ctx, lsxDaemonStartTimeout)
+	defer cancel()
+
+	res, err := d.read(readyCtx)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Ready {
+		return nil, goof.New("executor daemon not ready")
+	}
+	return res, nil
+}
+
+// exec starts the executor with the serve command.
+func (d *lsxDaemon) exec(ctx types.Context) error {
+
+	fi, err := os.Stat(d.lsxPath)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(d.lsxPath, d.driverName, types.LSXCmdServe)
+	cmd.Env = d.env
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// the daemon's logs are logged by the client
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			d.ctx.WithField("stderr", scanner.Text()).Debug(
+				"executor daemon output")
+		}
+	}()
+
+	ctx.WithField("pid", cmd.Process.Pid).Debug("started executor daemon")
+
+	d.cmd = cmd
+	d.conn = &lsxPipe{Reader: stdout, WriteCloser: stdin}
+	d.lsxMod = fi.ModTime()
+	return nil
+}
+
+// read reads a response from the daemon. The connection to the daemon is
+// closed, and a daemon the client started is stopped, if the context is done
+// before the response is read, which unblocks the read.
+func (d *lsxDaemon) read(ctx gocontext.Context) (*types.LSXResponse, error) {
+
+	// a connection to a socket also honors the context's deadline
+	if conn, ok := d.conn.(net.Conn); ok {
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetReadDeadline(deadline)
+			defer conn.SetReadDeadline(time.Time{})
+		}
+	}
+
+	var (
+		conn = d.conn
+		cmd  = d.cmd
+		done = make(chan struct{})
+	)
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			if cmd != nil {
+				cmd.Process.Kill()
+			}
+		case <-done:
+		}
+	}()
+
+	line, err := d.rdr.ReadBytes('\n')
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	res := &types.LSXResponse{}
+	if err := json.Unmarshal(line, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// replaced returns a flag indicating whether or not the executor was
+// replaced since the daemon was started or connected to, ex. by another
+// process that downloaded a newer executor.
+func (d *lsxDaemon) replaced() bool {
+	fi, err := os.Stat(d.lsxPath)
+	return err != nil || !fi.ModTime().Equal(d.lsxMod)
+}
+
+// failed stops the daemon after it could not be started or exited
+// unexpectedly. The daemon is disabled after too many failures in a row.
+func (d *lsxDaemon) failed(ctx types.Context, err error) {
+	d.stop()
+	d.failures++
+	ctx.WithError(err).WithField(
+		"failures", d.failures).Warn("executor daemon failed")
+	if d.failures >= lsxDaemonMaxFailures {
+		d.disabled = true
+		ctx.Warn("disabled executor daemon")
+	}
+}
+
+// stop closes the connection to the daemon and stops the daemon if the
+// client started it.
+func (d *lsxDaemon) stop() {
+	if d.conn != nil {
+		d.conn.Close()
+		d.conn = nil
+	}
+	if d.cmd != nil {
+		d.cmd.Process.Kill()
+		d.cmd.Wait()
+		d.cmd = nil
+	}
+	d.rdr = nil
+}
+
+// fileChecksum returns the SHA-256 checksum of a file, such as an executor.
+func fileChecksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
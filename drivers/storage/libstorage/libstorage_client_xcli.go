@@ -3,6 +3,7 @@ package libstorage
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
 	"strconv"
@@ -432,6 +433,9 @@ func (c *client) runExecutor(
 			c.clientType, "runExecutor")
 	}
 
+	// the lock is held while the daemon executes the command as well, so
+	// commands are not executed concurrently by the daemons and executors
+	// of other processes on the host
 	ctx.Debug("waiting on executor lock")
 	if err := c.lsxMutexWait(); err != nil {
 		return nil, err
@@ -444,6 +448,10 @@ func (c *client) runExecutor(
 		}
 	}()
 
+	if out, ok, err := c.runExecutorDaemon(ctx, args...); ok {
+		return out, err
+	}
+
 	lsxBin := c.pathConfig.LSX
 	cmd := exec.Command(lsxBin, args...)
 	cmd.Env = os.Environ()
@@ -459,35 +467,55 @@ func (c *client) runExecutor(
 		cmd.Env = append(cmd.Env, cev)
 	}
 
+	// provide the executor with the transaction so its logs may be
+	// correlated with the client's and server's logs
+	if tx, ok := context.Transaction(ctx); ok {
+		cmd.Env = append(cmd.Env, fmt.Sprintf(
+			"%s=%s", types.TransactionEnvVar, tx.String()))
+	}
+
 	out, err := cmd.Output()
 
 	if exitError, ok := err.(*exec.ExitError); ok {
 		exitCode := exitError.Sys().(syscall.WaitStatus).ExitStatus()
-		switch exitCode {
-		case types.LSXExitCodeNotImplemented:
-			return nil, types.ErrNotImplemented
-		case types.LSXExitCodeTimedOut:
-			return nil, types.ErrTimedOut
-		}
-		stderr := string(exitError.Stderr)
-		ctx.WithFields(log.Fields{
-			"cmd":    lsxBin,
-			"args":   args,
-			"stderr": stderr,
-		}).Error("error from executor cli")
-		return nil, goof.WithFieldsE(
-			map[string]interface{}{
-				"lsx":    lsxBin,
-				"args":   args,
-				"stderr": stderr,
-			},
-			"error executing xcli",
-			err)
+		return nil, newExecutorError(
+			ctx, lsxBin, args, exitCode, string(exitError.Stderr), err)
 	}
 
 	return out, err
 }
 
+// newExecutorError returns the error for an executor command that exited
+// with a non-zero exit code.
+func newExecutorError(
+	ctx types.Context,
+	lsxBin string,
+	args []string,
+	exitCode int,
+	stderr string,
+	err error) error {
+
+	switch exitCode {
+	case types.LSXExitCodeNotImplemented:
+		return types.ErrNotImplemented
+	case types.LSXExitCodeTimedOut:
+		return types.ErrTimedOut
+	}
+	ctx.WithFields(log.Fields{
+		"cmd":    lsxBin,
+		"args":   args,
+		"stderr": stderr,
+	}).Error("error from executor cli")
+	return goof.WithFieldsE(
+		map[string]interface{}{
+			"lsx":    lsxBin,
+			"args":   args,
+			"stderr": stderr,
+		},
+		"error executing xcli",
+		err)
+}
+
 func (c *client) lsxMutexWait() error {
 
 	if c.isController() {
@@ -1,20 +1,22 @@
 package libstorage
 
 import (
-	"crypto/tls"
 	"io/ioutil"
 	"net"
-	"net/http"
 	"path"
-	"strings"
+	"strconv"
+	"sync"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
 	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
 	"github.com/akutz/gotil"
+	"google.golang.org/grpc"
 
 	apiclient "github.com/codedellemc/libstorage/api/client"
 	"github.com/codedellemc/libstorage/api/context"
+	"github.com/codedellemc/libstorage/api/registry"
 	"github.com/codedellemc/libstorage/api/types"
 	"github.com/codedellemc/libstorage/api/utils"
 )
@@ -31,8 +33,22 @@ var (
 	EnableLocalDevicesHeaders = true
 )
 
+const (
+	// defaultCacheInstanceIDTTL is the default duration of the instance ID
+	// cache.
+	defaultCacheInstanceIDTTL = time.Duration(30) * time.Minute
+
+	// defaultCacheSupportedTTL is the default duration of the supported
+	// cache.
+	defaultCacheSupportedTTL = time.Duration(0)
+)
+
 type driver struct {
 	client
+
+	// registerCaches ensures the client's caches are registered once, even
+	// if the driver is initialized more than once.
+	registerCaches sync.Once
 }
 
 func newDriver() types.StorageDriver {
@@ -63,100 +79,48 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 		logFields["encodedToken"] = tok
 	}
 
-	proto, lAddr, err := gotil.ParseAddress(addr)
-	if err != nil {
-		return err
-	}
-
-	var tlsConfig *types.TLSConfig
-
-	// disable TLS for UNIX sockets
-	if !strings.EqualFold(proto, "unix") {
-		tlsConfig, err = utils.ParseTLSConfig(
-			d.ctx, config, logFields, types.ConfigClient)
-		if err != nil {
-			return err
-		}
-	}
-
-	host := getHost(d.ctx, proto, lAddr, tlsConfig)
 	lsxPath := config.GetString(types.ConfigExecutorPath)
 	cliType := types.ParseClientType(config.GetString(types.ConfigClientType))
 	disableKeepAlive := config.GetBool(types.ConfigHTTPDisableKeepAlive)
 
-	logFields["lAddr"] = host
 	logFields["lsxPath"] = lsxPath
 	logFields["clientType"] = cliType
 	logFields["disableKeepAlive"] = disableKeepAlive
 
-	httpTransport := &http.Transport{
-		Dial: func(string, string) (net.Conn, error) {
+	apiClient, isEmbedded := context.APIClient(ctx)
+	logFields["embedded"] = isEmbedded
 
-			if tlsConfig == nil {
-				conn, err := net.Dial(proto, lAddr)
-				if err != nil {
-					return nil, err
-				}
-				d.ctx.Debug("successful connection")
-				return conn, nil
+	if isEmbedded {
+		// the in-process api client of an embedded server does not connect
+		// to a host, so no host is required
+		d.ctx.Debug("using in-process api client of embedded server")
+	} else {
+		hosts, err := newHostServers(d.ctx, config, logFields)
+		if err != nil {
+			return err
+		}
+		logFields["lAddr"] = hosts.servers[0].host
+
+		if hosts.isGRPC() {
+			// the dialer establishes the TLS connection and verifies the
+			// server's known host, so the connection does not use the gRPC
+			// transport credentials
+			dialer := func(string, time.Duration) (net.Conn, error) {
+				return hosts.dial()
 			}
-
-			conn, err := tls.Dial(proto, lAddr, &tlsConfig.Config)
+			conn, err := grpc.Dial(
+				hosts.servers[0].host,
+				grpc.WithInsecure(),
+				grpc.WithDialer(dialer))
 			if err != nil {
-				return nil, err
-			}
-
-			if !tlsConfig.VerifyPeers {
-				d.ctx.Debug("successful tls connection; not verifying peers")
-				return conn, nil
-			}
-
-			const errMatch = "error matching peer fingerprint"
-
-			// get the fqdn/IP of the endpoint to which the connection
-			// is being made in case an ErrKnownHost error occurs
-			hostSansPort := lAddr
-			if hostParts := strings.Split(lAddr, ":"); len(hostParts) > 1 {
-				hostSansPort = hostParts[0]
-			}
-
-			peerCerts := conn.ConnectionState().PeerCertificates
-
-			if ok, err := verifyKnownHost(
-				d.ctx,
-				hostSansPort,
-				peerCerts,
-				tlsConfig.KnownHost); ok {
-
-				return conn, nil
-
-			} else if err != nil {
-
-				d.ctx.WithError(err).Error(errMatch)
-				return nil, err
-			}
-
-			if ok, err := verifyKnownHostFiles(
-				d.ctx,
-				hostSansPort,
-				peerCerts,
-				tlsConfig.UsrKnownHosts,
-				tlsConfig.SysKnownHosts); ok {
-
-				return conn, nil
-
-			} else if err != nil {
-
-				d.ctx.WithError(err).Error(errMatch)
-				return nil, err
+				return err
 			}
-
-			return nil, newErrKnownHost(hostSansPort, peerCerts)
-		},
-		DisableKeepAlives: disableKeepAlive,
+			apiClient = apiclient.NewGRPC(conn)
+		} else {
+			apiClient = apiclient.New(hosts.servers[0].host, hosts)
+		}
 	}
 
-	apiClient := apiclient.New(host, httpTransport)
 	logReq := config.GetBool(types.ConfigLogHTTPRequests)
 	logRes := config.GetBool(types.ConfigLogHTTPResponses)
 	apiClient.LogRequests(logReq)
@@ -167,6 +131,17 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 	logFields["logRequests"] = logReq
 	logFields["logResponses"] = logRes
 
+	retryPolicy, err := newRetryPolicy(config)
+	if err != nil {
+		return err
+	}
+	apiClient.UseRetryPolicy(retryPolicy)
+	logFields["retryAttempts"] = retryPolicy.Attempts
+
+	cancelTasks := config.GetBool(types.ConfigClientTasksCancel)
+	apiClient.CancelTasks(cancelTasks)
+	logFields["cancelTasks"] = cancelTasks
+
 	pathConfig := context.MustPathConfig(d.ctx)
 
 	lsxMutexPath := path.Join(pathConfig.Run, "lsx.lock")
@@ -176,7 +151,6 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 		APIClient:    apiClient,
 		ctx:          d.ctx,
 		config:       config,
-		tlsConfig:    tlsConfig,
 		pathConfig:   pathConfig,
 		clientType:   cliType,
 		lsxMutexPath: lsxMutexPath,
@@ -185,19 +159,52 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 
 	if d.clientType == types.IntegrationClient {
 
-		newIIDCache := utils.NewStore
-		dur, err := time.ParseDuration(
-			config.GetString(types.ConfigClientCacheInstanceID))
+		iidTTL := parseCacheTTL(
+			d.ctx, config, types.ConfigClientCacheInstanceID,
+			defaultCacheInstanceIDTTL)
+		supportedTTL := parseCacheTTL(
+			d.ctx, config, types.ConfigClientCacheSupported,
+			defaultCacheSupportedTTL)
+		logFields["iidCacheDuration"] = formatCacheTTL(iidTTL)
+		logFields["supportedCacheDuration"] = formatCacheTTL(supportedTTL)
+
+		d.supportedCache = &lss{Store: newCacheStore(supportedTTL)}
+		d.instanceIDCache = newCacheStore(iidTTL)
+
+		if config.GetBool(types.ConfigExecutorServe) {
+			d.lsxDaemons = newLSXDaemons()
+		}
+		logFields["lsxServe"] = d.lsxDaemons != nil
+
+		trustedKeys, err := utils.ParseTrustedKeys(
+			config.GetString(types.ConfigExecutorTrustedKeys))
 		if err != nil {
-			logFields["iidCacheDuration"] = dur.String()
-			newIIDCache = func() types.Store {
-				return utils.NewTTLStore(dur, true)
-			}
+			return goof.WithError("error parsing trusted keys", err)
 		}
+		d.trustedKeys = trustedKeys
+		logFields["lsxTrustedKeys"] = len(trustedKeys)
 
-		d.lsxCache = &lss{Store: utils.NewStore()}
-		d.supportedCache = &lss{Store: utils.NewStore()}
-		d.instanceIDCache = newIIDCache()
+		if config.GetBool(types.ConfigClientCacheDisk) {
+			d.diskCache = newDiskCache(pathConfig.Run, supportedTTL, iidTTL)
+			logFields["diskCachePath"] = d.diskCache.path
+		}
+
+		d.registerCaches.Do(func() {
+			registry.RegisterCache(&lssCache{
+				name: "client",
+				d:    d,
+				store: func(c *client) types.Store {
+					return c.supportedCache
+				},
+			})
+			registry.RegisterCache(&lssCache{
+				name: "instanceID",
+				d:    d,
+				store: func(c *client) types.Store {
+					return c.instanceIDCache
+				},
+			})
+		})
 	}
 
 	d.ctx.WithFields(logFields).Info("created libStorage client")
@@ -209,3 +216,81 @@ func (d *driver) Init(ctx types.Context, config gofig.Config) error {
 	d.ctx.Info("successefully dialed libStorage server")
 	return nil
 }
+
+// cacheTTLDisabled is the duration of a client cache that is disabled. The
+// entries of a disabled cache are kept in memory for the life of the client,
+// since requests depend upon them, but are never persisted to disk.
+const cacheTTLDisabled = time.Duration(-1)
+
+// parseCacheTTL returns how long the entries of one of the client's caches
+// remain valid. A duration of zero means the entries do not expire. The
+// legacy boolean values are mapped to the default duration and to a disabled
+// cache. Any other invalid value is replaced with the default duration.
+func parseCacheTTL(
+	ctx types.Context,
+	config gofig.Config,
+	key string,
+	defaultTTL time.Duration) time.Duration {
+
+	val := config.GetString(key)
+	ttl, err := time.ParseDuration(val)
+	if err == nil && ttl >= 0 {
+		return ttl
+	}
+
+	lf := log.Fields{"key": key, "value": val}
+	if enabled, berr := strconv.ParseBool(val); berr == nil {
+		if !enabled {
+			ctx.WithFields(lf).Warn(
+				"boolean cache durations are deprecated; disabling cache")
+			return cacheTTLDisabled
+		}
+		ctx.WithFields(lf).Warn(
+			"boolean cache durations are deprecated; using default duration")
+		return defaultTTL
+	}
+
+	ctx.WithFields(lf).Warn("invalid cache duration; using default duration")
+	return defaultTTL
+}
+
+// formatCacheTTL returns the string representation of a cache duration for
+// logging.
+func formatCacheTTL(ttl time.Duration) string {
+	if ttl == cacheTTLDisabled {
+		return "disabled"
+	}
+	return ttl.String()
+}
+
+// newCacheStore returns a store whose entries expire after the provided
+// duration, or never if the duration is zero or the cache is disabled.
+func newCacheStore(ttl time.Duration) types.Store {
+	if ttl <= 0 {
+		return utils.NewStore()
+	}
+	return utils.NewTTLStore(ttl, true)
+}
+
+// newRetryPolicy returns the policy with which the API client retries failed
+// requests.
+func newRetryPolicy(config gofig.Config) (*types.RetryPolicy, error) {
+
+	minBackoff, err := time.ParseDuration(
+		config.GetString(types.ConfigClientHTTPRetryMinBackoff))
+	if err != nil {
+		return nil, err
+	}
+
+	maxBackoff, err := time.ParseDuration(
+		config.GetString(types.ConfigClientHTTPRetryMaxBackoff))
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.RetryPolicy{
+		Attempts:   config.GetInt(types.ConfigClientHTTPRetryAttempts),
+		MinBackoff: minBackoff,
+		MaxBackoff: maxBackoff,
+	}, nil
+}
@@ -121,6 +121,7 @@ func (d *driver) VolumeCreate(
 		IOPS:             opts.IOPS,
 		Size:             opts.Size,
 		Type:             opts.Type,
+		Class:            opts.Class,
 		Opts:             opts.Opts.Map(),
 	}
 
@@ -144,6 +145,7 @@ func (d *driver) VolumeCreateFromSnapshot(
 		IOPS:             opts.IOPS,
 		Size:             opts.Size,
 		Type:             opts.Type,
+		Class:            opts.Class,
 		Opts:             opts.Opts.Map(),
 	}
 
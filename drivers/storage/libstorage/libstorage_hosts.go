@@ -0,0 +1,425 @@
+package libstorage
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	gofig "github.com/akutz/gofig/types"
+	"github.com/akutz/goof"
+
+	apiclient "github.com/codedellemc/libstorage/api/client"
+	"github.com/codedellemc/libstorage/api/types"
+	"github.com/codedellemc/libstorage/api/utils"
+)
+
+// srvScheme is the scheme of a host that is a DNS SRV name. The targets of
+// the name's SRV records are the addresses of the servers.
+const srvScheme = "srv://"
+
+// txServerTimeout is how long a transaction remains pinned to the server that
+// handled its last request. The API client inspects a task created by one of
+// its async methods as part of the transaction that created the task, so the
+// transaction remains pinned for longer than the API client tracks the task.
+var txServerTimeout = apiclient.TrackedTaskTimeout + time.Minute
+
+// parseHosts returns the addresses of the configured servers. The host may
+// be a single address, a comma-separated list of addresses or a list, and
+// any of the addresses may be a DNS SRV name, ex. srv://_libstorage._tcp.lan.
+func parseHosts(ctx types.Context, config gofig.Config) ([]string, error) {
+
+	var addrs []string
+	if v := config.GetString(types.ConfigHost); v != "" {
+		addrs = strings.Split(v, ",")
+	} else {
+		addrs = config.GetStringSlice(types.ConfigHost)
+	}
+
+	hosts := []string{}
+	for _, addr := range addrs {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(strings.ToLower(addr), srvScheme) {
+			hosts = append(hosts, addr)
+			continue
+		}
+
+		name := addr[len(srvScheme):]
+		_, srvs, err := net.LookupSRV("", "", name)
+		if err != nil {
+			return nil, goof.WithFieldE(
+				"name", name, "error looking up srv records", err)
+		}
+
+		// the records are ordered by priority and randomized by weight
+		for _, srv := range srvs {
+			host := fmt.Sprintf(
+				"tcp://%s:%d", strings.TrimSuffix(srv.Target, "."), srv.Port)
+			ctx.WithFields(log.Fields{
+				"name": name,
+				"host": host,
+			}).Debug("got host from srv record")
+			hosts = append(hosts, host)
+		}
+	}
+
+	if len(hosts) == 0 {
+		return nil, goof.New("no host configured")
+	}
+
+	return hosts, nil
+}
+
+// hostServer is one of the libStorage servers used by the client.
+type hostServer struct {
+	ctx       types.Context
+	addr      string
+	network   string
+	lAddr     string
+	host      string
+	grpc      bool
+	tlsConfig *types.TLSConfig
+	transport *http.Transport
+
+	// downUntil is the time until which the server is skipped because
+	// connecting to it failed
+	downUntil time.Time
+}
+
+func newHostServer(
+	ctx types.Context,
+	config gofig.Config,
+	addr string,
+	logFields log.Fields) (*hostServer, error) {
+
+	proto, lAddr, err := utils.ParseAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &hostServer{
+		ctx:     ctx.WithField("host", addr),
+		addr:    addr,
+		network: proto,
+		lAddr:   lAddr,
+		grpc:    types.ParseEndpointType(proto) == types.GRPCEndpoint,
+	}
+
+	// disable TLS for UNIX sockets
+	if !strings.EqualFold(proto, "unix") {
+		s.tlsConfig, err = utils.ParseTLSConfig(
+			ctx, config, logFields, types.ConfigClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// gRPC endpoints are dialed over TCP
+	if s.grpc {
+		s.network = "tcp"
+	}
+
+	s.host = getHost(ctx, proto, lAddr, s.tlsConfig)
+	s.transport = &http.Transport{
+		Dial: func(string, string) (net.Conn, error) {
+			return s.dial()
+		},
+		DisableKeepAlives: config.GetBool(types.ConfigHTTPDisableKeepAlive),
+	}
+
+	return s, nil
+}
+
+// dial connects to the server. The server's TLS certificate is verified
+// against the known hosts using the server's own host name.
+func (s *hostServer) dial() (net.Conn, error) {
+
+	if s.tlsConfig == nil {
+		conn, err := net.Dial(s.network, s.lAddr)
+		if err != nil {
+			return nil, err
+		}
+		s.ctx.Debug("successful connection")
+		return conn, nil
+	}
+
+	conn, err := tls.Dial(s.network, s.lAddr, &s.tlsConfig.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.tlsConfig.VerifyPeers {
+		s.ctx.Debug("successful tls connection; not verifying peers")
+		return conn, nil
+	}
+
+	const errMatch = "error matching peer fingerprint"
+
+	// get the fqdn/IP of the endpoint to which the connection
+	// is being made in case an ErrKnownHost error occurs
+	hostSansPort := s.lAddr
+	if hostParts := strings.Split(s.lAddr, ":"); len(hostParts) > 1 {
+		hostSansPort = hostParts[0]
+	}
+
+	peerCerts := conn.ConnectionState().PeerCertificates
+
+	if ok, err := verifyKnownHost(
+		s.ctx,
+		hostSansPort,
+		peerCerts,
+		s.tlsConfig.KnownHost); ok {
+
+		return conn, nil
+
+	} else if err != nil {
+
+		conn.Close()
+		s.ctx.WithError(err).Error(errMatch)
+		return nil, err
+	}
+
+	if ok, err := verifyKnownHostFiles(
+		s.ctx,
+		hostSansPort,
+		peerCerts,
+		s.tlsConfig.UsrKnownHosts,
+		s.tlsConfig.SysKnownHosts); ok {
+
+		return conn, nil
+
+	} else if err != nil {
+
+		conn.Close()
+		s.ctx.WithError(err).Error(errMatch)
+		return nil, err
+	}
+
+	conn.Close()
+	return nil, newErrKnownHost(hostSansPort, peerCerts)
+}
+
+// roundTrip sends a request to the server.
+func (s *hostServer) roundTrip(
+	req *http.Request, body []byte) (*http.Response, error) {
+
+	r := &http.Request{}
+	*r = *req
+	u := *req.URL
+	u.Host = s.host
+	r.URL = &u
+	r.Host = s.host
+	if body != nil {
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return s.transport.RoundTrip(r)
+}
+
+// hostServers is an http.RoundTripper that sends the client's requests to
+// one of several servers. Requests that read resources are sent to the
+// healthy servers in turn, while all other requests are sent to the first
+// healthy server. Requests that are part of a transaction are sent to the
+// server that handled the transaction's previous request. If a server cannot
+// be reached the request fails over to the next server, and the server is
+// skipped until the retry interval has elapsed.
+type hostServers struct {
+	sync.Mutex
+	servers       []*hostServer
+	next          int
+	retryInterval time.Duration
+	txServers     types.Store
+}
+
+func newHostServers(
+	ctx types.Context,
+	config gofig.Config,
+	logFields log.Fields) (*hostServers, error) {
+
+	addrs, err := parseHosts(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &hostServers{
+		txServers: utils.NewTTLStore(txServerTimeout, false),
+	}
+
+	h.retryInterval, err = time.ParseDuration(
+		config.GetString(types.ConfigClientHostsRetryInterval))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, addr := range addrs {
+		s, err := newHostServer(ctx, config, addr, logFields)
+		if err != nil {
+			return nil, err
+		}
+		if len(h.servers) > 0 && s.grpc != h.isGRPC() {
+			return nil, goof.WithField(
+				"host", addr, "cannot mix grpc and http hosts")
+		}
+		h.servers = append(h.servers, s)
+	}
+
+	logFields["hosts"] = addrs
+	logFields["hostsRetryInterval"] = h.retryInterval
+
+	return h, nil
+}
+
+func (h *hostServers) isGRPC() bool {
+	return h.servers[0].grpc
+}
+
+// RoundTrip sends a request to one of the servers.
+func (h *hostServers) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	if len(h.servers) == 1 {
+		return h.servers[0].roundTrip(req, nil)
+	}
+
+	var (
+		txID = req.Header.Get(types.TransactionHeader)
+		read = req.Method == http.MethodGet || req.Method == http.MethodHead
+		body []byte
+		err  error
+	)
+
+	// the transport closes a request's body, so the body is buffered in
+	// order to send it again if the request fails over to another server
+	if req.Body != nil {
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range h.candidates(txID, read) {
+
+		res, rtErr := s.roundTrip(req, body)
+		if rtErr == nil {
+			if txID != "" {
+				h.txServers.Set(txID, s)
+			}
+			return res, nil
+		}
+		err = rtErr
+
+		// a request that failed because it was cancelled is not sent to
+		// another server, and the server is not at fault
+		if req.Context().Err() != nil || !canFailover(err, read) {
+			return nil, err
+		}
+		h.markDown(s, err)
+	}
+
+	return nil, err
+}
+
+// dial connects to the first healthy server. A gRPC connection multiplexes
+// all of the client's calls, so the calls of a gRPC client are not balanced
+// across the servers, but the connection fails over when it is reestablished.
+func (h *hostServers) dial() (net.Conn, error) {
+
+	var err error
+	for _, s := range h.candidates("", false) {
+		conn, dialErr := s.dial()
+		if dialErr == nil {
+			return conn, nil
+		}
+		err = dialErr
+
+		if !canFailover(err, false) {
+			return nil, err
+		}
+		h.markDown(s, err)
+	}
+
+	return nil, err
+}
+
+// candidates returns the servers to which a request is sent, in the order in
+// which they are tried.
+func (h *hostServers) candidates(txID string, read bool) []*hostServer {
+
+	h.Lock()
+	defer h.Unlock()
+
+	var (
+		now  = time.Now()
+		up   []*hostServer
+		down []*hostServer
+	)
+
+	for _, s := range h.servers {
+		if s.downUntil.After(now) {
+			down = append(down, s)
+		} else {
+			up = append(up, s)
+		}
+	}
+
+	if read && len(up) > 1 {
+		i := h.next % len(up)
+		h.next++
+		up = append(append([]*hostServer{}, up[i:]...), up[:i]...)
+	}
+
+	// servers that are down are tried last in case all of them are down
+	servers := append(up, down...)
+
+	if txID == "" {
+		return servers
+	}
+
+	txServer, ok := h.txServers.Get(txID).(*hostServer)
+	if !ok {
+		return servers
+	}
+
+	pinned := []*hostServer{txServer}
+	for _, s := range servers {
+		if s != txServer {
+			pinned = append(pinned, s)
+		}
+	}
+	return pinned
+}
+
+func (h *hostServers) markDown(s *hostServer, err error) {
+	h.Lock()
+	defer h.Unlock()
+	s.downUntil = time.Now().Add(h.retryInterval)
+	s.ctx.WithError(err).WithField(
+		"retryInterval", h.retryInterval).Warn("server unavailable")
+}
+
+// canFailover returns a flag indicating whether or not a request that failed
+// with the provided error may be sent to another server. Requests that read
+// resources may always be sent again, but other requests only if the server
+// could not be reached. A server whose identity cannot be verified is never
+// skipped.
+func canFailover(err error, read bool) bool {
+	switch err.(type) {
+	case *types.ErrKnownHost, *types.ErrKnownHostConflict:
+		return false
+	}
+	if read {
+		return true
+	}
+	opErr, ok := err.(*net.OpError)
+	return ok && opErr.Op == "dial"
+}
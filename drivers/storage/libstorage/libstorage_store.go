@@ -13,13 +13,6 @@ func (s *lss) GetServiceInfo(service string) *types.ServiceInfo {
 	return nil
 }
 
-func (s *lss) GetExecutorInfo(lsx string) *types.ExecutorInfo {
-	if obj, ok := s.Get(lsx).(*types.ExecutorInfo); ok {
-		return obj
-	}
-	return nil
-}
-
 func (s *lss) GetInstanceID(service string) *types.InstanceID {
 	return s.Store.GetInstanceID(service)
 }
@@ -30,3 +23,32 @@ func (s *lss) GetLSXSupported(driverName string) types.LSXSupportedOp {
 	}
 	return 0
 }
+
+// lssCache enables one of the client's stores to be flushed at runtime. The
+// store is looked up when the cache is flushed, since the driver replaces its
+// stores each time it is initialized. Flushing the store also removes the
+// client's disk cache, if any.
+type lssCache struct {
+	name  string
+	d     *driver
+	store func(c *client) types.Store
+}
+
+func (c *lssCache) Name() string {
+	return c.name
+}
+
+func (c *lssCache) Flush(ctx types.Context) int {
+	n := 0
+	s := c.store(&c.d.client)
+	for _, k := range s.Keys() {
+		s.Delete(k)
+		n++
+	}
+	if c.d.diskCache != nil {
+		if err := c.d.diskCache.clear(ctx); err != nil {
+			ctx.WithError(err).Warn("error removing disk cache")
+		}
+	}
+	return n
+}
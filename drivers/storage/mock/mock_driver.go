@@ -95,6 +95,12 @@ func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
 	return types.Block, nil
 }
 
+// PersistsLabels returns true since the labels of a request are kept with
+// the in-memory volumes and snapshots.
+func (d *driver) PersistsLabels(ctx types.Context) bool {
+	return true
+}
+
 func (d *driver) NextDeviceInfo(
 	ctx types.Context) (*types.NextDeviceInfo, error) {
 	return d.nextDeviceInfo, nil
@@ -212,6 +218,7 @@ func (d *driver) VolumeCreate(
 			volume.Fields["priority"] = customFields.GetString("priority")
 		}
 	}
+	volume.Fields = utils.RecordLabels(volume.Fields, utils.Labels(opts.Opts))
 
 	d.volumes = append(d.volumes, volume)
 
@@ -260,6 +267,7 @@ func (d *driver) VolumeCreateFromSnapshot(
 	if opts.Opts.IsSet("priority") {
 		volume.Fields["priority"] = opts.Opts.GetString("priority")
 	}
+	volume.Fields = utils.RecordLabels(volume.Fields, utils.Labels(opts.Opts))
 	return volume, nil
 }
 
@@ -295,6 +303,7 @@ func (d *driver) VolumeCopy(
 	for k, v := range ogvol.Fields {
 		volume.Fields[k] = v
 	}
+	volume.Fields = utils.RecordLabels(volume.Fields, utils.Labels(opts))
 
 	d.volumes = append(d.volumes, volume)
 
@@ -318,7 +327,7 @@ func (d *driver) VolumeSnapshot(
 		Name:     snapshotName,
 		ID:       fmt.Sprintf("snap-%03d", lenSnaps+1),
 		VolumeID: volumeID,
-		Fields:   map[string]string{},
+		Fields:   utils.RecordLabels(map[string]string{}, utils.Labels(opts)),
 	}
 
 	d.snapshots = append(d.snapshots, snapshot)
@@ -461,6 +470,7 @@ func (d *driver) SnapshotCopy(
 	for k, s := range ogsnap.Fields {
 		snapshot.Fields[k] = s
 	}
+	snapshot.Fields = utils.RecordLabels(snapshot.Fields, utils.Labels(opts))
 
 	d.snapshots = append(d.snapshots, snapshot)
 
@@ -95,6 +95,12 @@ func (d *driver) Type(ctx types.Context) (types.StorageType, error) {
 	return types.Object, nil
 }
 
+// PersistsLabels returns true since the custom fields of a request are
+// recorded in the volume's or snapshot's JSON file.
+func (d *driver) PersistsLabels(ctx types.Context) bool {
+	return true
+}
+
 func (d *driver) NextDeviceInfo(
 	ctx types.Context) (*types.NextDeviceInfo, error) {
 	return &types.NextDeviceInfo{
@@ -115,7 +121,11 @@ func (d *driver) InstanceInspect(
 
 	iid := context.MustInstanceID(ctx)
 	if iid.ID != "" {
-		return &types.Instance{Name: "vfsInstance", InstanceID: iid}, nil
+		return &types.Instance{
+			Name:             "vfsInstance",
+			InstanceID:       iid,
+			AvailabilityZone: iid.Fields[vfs.InstanceIDFieldAvailabilityZone],
+		}, nil
 	}
 
 	var hostname string
@@ -262,6 +272,9 @@ func (d *driver) VolumeCreate(
 	if opts.Encrypted != nil {
 		v.Encrypted = *opts.Encrypted
 	}
+	if opts.Class != nil {
+		v.Fields[types.VolumeFieldClass] = *opts.Class
+	}
 	if customFields := opts.Opts.GetStore("opts"); customFields != nil {
 		for _, k := range customFields.Keys() {
 			v.Fields[k] = customFields.GetString(k)
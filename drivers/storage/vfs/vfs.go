@@ -15,6 +15,10 @@ import (
 const (
 	// Name is the name of the driver.
 	Name = "vfs"
+
+	// InstanceIDFieldAvailabilityZone is the key to retrieve the availability
+	// zone value from the InstanceID Field map.
+	InstanceIDFieldAvailabilityZone = "availabilityZone"
 )
 
 func init() {
@@ -83,6 +83,8 @@ func init() {
 			rk(gofig.String, pathConfig.LSX, "", types.ConfigExecutorPath)
 
 			rk(gofig.Bool, false, "", types.ConfigExecutorNoDownload)
+			rk(gofig.Bool, true, "", types.ConfigExecutorServe)
+			rk(gofig.String, "", "", types.ConfigExecutorTrustedKeys)
 			rk(gofig.Bool, false, "", types.ConfigIgVolOpsMountPreempt)
 			rk(gofig.Int, 0, "", types.ConfigIgVolOpsMountRetryCount)
 			rk(gofig.String, "5s", "", types.ConfigIgVolOpsMountRetryWait)
@@ -92,12 +94,31 @@ func init() {
 			rk(gofig.Bool, true, "", types.ConfigIgVolOpsPathCacheEnabled)
 			rk(gofig.Bool, true, "", types.ConfigIgVolOpsPathCacheAsync)
 			rk(gofig.String, "30m", "", types.ConfigClientCacheInstanceID)
+			rk(gofig.String, "0s", "", types.ConfigClientCacheSupported)
+			rk(gofig.Bool, false, "", types.ConfigClientCacheDisk)
+			rk(gofig.String, "30s", "", types.ConfigClientHostsRetryInterval)
+			rk(gofig.Int, 3, "", types.ConfigClientHTTPRetryAttempts)
+			rk(gofig.String, "100ms", "", types.ConfigClientHTTPRetryMinBackoff)
+			rk(gofig.String, "5s", "", types.ConfigClientHTTPRetryMaxBackoff)
+			rk(gofig.Bool, false, "", types.ConfigClientTasksCancel)
 			rk(gofig.String, "30s", "", types.ConfigDeviceAttachTimeout)
 			rk(gofig.Int, 0, "", types.ConfigDeviceScanType)
 			rk(gofig.Bool, false, "", types.ConfigEmbedded)
 			rk(gofig.String, "1m", "", types.ConfigServerTasksExeTimeout)
 			rk(gofig.String, "0s", "", types.ConfigServerTasksLogTimeout)
+			rk(gofig.String, "", "", types.ConfigServerExecutorsPath)
+			rk(gofig.String, "1m", "", types.ConfigServerVolumesWatchTimeout)
+			rk(gofig.String, "30s", "", types.ConfigServerVolumesWatchRelist)
+			rk(gofig.String, "0s", "", types.ConfigServerVolumesCacheTTL)
+			rk(gofig.Bool, false, "", types.ConfigServerVolumesPartial)
+			rk(gofig.String, "0s", "", types.ConfigServerVolumesServiceTimeout)
 			rk(gofig.Bool, false, "", types.ConfigServerParseRequestOpts)
+			rk(gofig.Bool, false, "", types.ConfigServerNamespacesEnabled)
+			rk(gofig.String, "", "", types.ConfigServerPolicyFile)
+			rk(gofig.String, "", "", types.ConfigServerPolicyEndpoint)
+			rk(gofig.String, "10s", "", types.ConfigServerPolicyTimeout)
+			rk(gofig.Bool, false, "", types.ConfigServerPolicyFailOpen)
+			rk(gofig.String, "", "", types.ConfigServerAdminToken)
 
 			// tls config
 			rk(
@@ -48,6 +48,11 @@ import (
 // New starts an embedded libStorage server and returns both the server
 // instnace as well as a client connected to said instnace.
 //
+// If the config enables libstorage.embedded then the server's endpoints do
+// not listen for requests, and the client's requests are handled in process
+// by the server's routers and middleware. Otherwise the client connects to
+// the configured host, or to the server's first endpoint if no host is set.
+//
 // While a new server may be launched, it's still up to the caller to provide
 // a config instance with the correct properties to specify service
 // information for a libStorage server.
@@ -67,7 +72,9 @@ func New(
 		return nil, nil, nil, err
 	}
 
-	if h := config.GetString(types.ConfigHost); h == "" {
+	if config.GetBool(types.ConfigEmbedded) {
+		ctx = ctx.WithValue(apictx.APIClientKey, s.API())
+	} else if h := config.GetString(types.ConfigHost); h == "" {
 		config.Set(types.ConfigHost, s.Addrs()[0])
 	}
 